@@ -0,0 +1,119 @@
+package api
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestV1 tests the conversion of the typed v2 model to the v1 model.
+func TestV1(t *testing.T) {
+	speed := int64(1000)
+	iface := NetworkInterfaceV2{
+		Name:        "eth0",
+		MACAddress:  "00:11:22:33:44:55",
+		MTU:         1500,
+		SpeedMbps:   &speed,
+		Duplex:      DuplexFull,
+		AdminStatus: AdminStatusUp,
+		OperStatus:  OperStatusUp,
+		Addresses:   []Address{{Address: "192.168.1.10", PrefixLength: 24, Family: FamilyIPv4}},
+	}
+
+	expected := NetworkInterface{
+		Name:              "eth0",
+		IPAddresses:       []string{"192.168.1.10"},
+		MACAddress:        "00:11:22:33:44:55",
+		MTU:               1500,
+		Speed:             "1000Mb/s",
+		Duplex:            "Full",
+		AdminStatus:       "enabled",
+		OperationalStatus: "UP",
+	}
+	if got := iface.V1(); !reflect.DeepEqual(got, expected) {
+		t.Errorf("interface mismatch: got %+v, want %+v", got, expected)
+	}
+
+	// Unknown values map to the free-form strings of v1
+	iface = NetworkInterfaceV2{Name: "lo", Duplex: DuplexNone, AdminStatus: AdminStatusUp, OperStatus: OperStatusUnknown}
+	got := iface.V1()
+	if got.Speed != "N/A" || got.Duplex != "N/A" || got.AdminStatus != "unknown" || got.OperationalStatus != "unknown" {
+		t.Errorf("unexpected v1 values for unknown fields: %+v", got)
+	}
+
+	// The admin status follows the operational state, an enabled interface without carrier is disabled
+	iface = NetworkInterfaceV2{Name: "eth1", AdminStatus: AdminStatusUp, OperStatus: OperStatusDown}
+	if got := iface.V1(); got.AdminStatus != "disabled" || got.OperationalStatus != "DOWN" {
+		t.Errorf("unexpected v1 statuses of an interface without carrier: %+v", got)
+	}
+
+	// Devices with link settings report an unknown speed and duplex mode like ethtool
+	iface = NetworkInterfaceV2{Name: "wlan0", Duplex: DuplexUnknown}
+	if got := iface.V1(); got.Speed != "Unknown!" || got.Duplex != "Unknown!" {
+		t.Errorf("unexpected v1 values for unknown link modes: %+v", got)
+	}
+}
+
+// TestRatesSince tests the computation of per-second rates from two consecutive samples.
+func TestRatesSince(t *testing.T) {
+	prev := InterfaceStats{RxBytes: 1000, TxBytes: 500, RxPackets: 10}
+	cur := InterfaceStats{RxBytes: 3000, TxBytes: 1500, RxPackets: 30}
+
+	rates := cur.RatesSince(prev, 2*time.Second)
+	if rates == nil {
+		t.Fatal("expected rates, got nil")
+	}
+	if rates.Interval != 2 || rates.RxBytes != 1000 || rates.TxBytes != 500 || rates.RxPackets != 10 {
+		t.Errorf("unexpected rates: %+v", rates)
+	}
+
+	// Counters going backwards, e.g. after the interface was recreated, yield no rates
+	if rates := prev.RatesSince(cur, 2*time.Second); rates != nil {
+		t.Errorf("expected no rates for reset counters, got %+v", rates)
+	}
+
+	// Samples taken at the same time yield no rates
+	if rates := cur.RatesSince(prev, 0); rates != nil {
+		t.Errorf("expected no rates for zero interval, got %+v", rates)
+	}
+}
+
+// TestNewTopology tests building the topology graph and rendering it as a Graphviz document.
+func TestNewTopology(t *testing.T) {
+	interfaces := []NetworkInterfaceV2{
+		{Name: "eth0", Kind: KindDevice, OperStatus: OperStatusUp, Master: "bond0"},
+		{Name: "bond0", Kind: KindBond, OperStatus: OperStatusUp, Slaves: []string{"eth0"}},
+		{Name: "bond0.100", Kind: KindVLAN, OperStatus: OperStatusDown, Details: &LinkDetails{VLAN: &VLANDetails{ID: 100, Parent: "bond0"}}},
+		{Name: "veth0", Kind: KindVeth, OperStatus: OperStatusUp, Details: &LinkDetails{Veth: &VethDetails{PeerIndex: 5, Peer: "veth1"}}},
+		{Name: "veth1", Kind: KindVeth, OperStatus: OperStatusUp, Details: &LinkDetails{Veth: &VethDetails{PeerIndex: 4, Peer: "veth0"}}},
+		// The peer lives in another network namespace
+		{Name: "veth2", Kind: KindVeth, OperStatus: OperStatusUp, Details: &LinkDetails{Veth: &VethDetails{PeerIndex: 2}}},
+	}
+
+	topology := NewTopology(interfaces)
+
+	expectedEdges := []TopologyEdge{
+		{From: "eth0", To: "bond0", Type: EdgeMaster},
+		{From: "bond0.100", To: "bond0", Type: EdgeLower},
+		{From: "veth0", To: "veth1", Type: EdgePeer},
+	}
+	if len(topology.Nodes) != len(interfaces) {
+		t.Errorf("unexpected number of nodes: got %d, want %d", len(topology.Nodes), len(interfaces))
+	}
+	if !reflect.DeepEqual(topology.Edges, expectedEdges) {
+		t.Errorf("edges mismatch: got %+v, want %+v", topology.Edges, expectedEdges)
+	}
+
+	dot := topology.DOT()
+	for _, line := range []string{
+		"digraph topology {\n",
+		"\t\"bond0.100\" [label=\"bond0.100\\nvlan\", color=gray];\n",
+		"\t\"eth0\" -> \"bond0\" [label=master];\n",
+		"\t\"veth0\" -> \"veth1\" [label=peer, dir=none, style=dashed];\n",
+	} {
+		if !strings.Contains(dot, line) {
+			t.Errorf("missing line %q in output:\n%s", line, dot)
+		}
+	}
+}
@@ -0,0 +1,230 @@
+// Package client is a typed client for the Interfacer HTTP API.
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	api "apimodule"
+)
+
+// Sentinel errors matched by a StatusError with errors.Is, depending on its status code.
+var (
+	ErrBadRequest = errors.New("bad request")           // 400 Bad Request.
+	ErrNotFound   = errors.New("not found")             // 404 Not Found.
+	ErrServer     = errors.New("internal server error") // 500 Internal Server Error.
+)
+
+// StatusError is returned when the server responds with an error status code.
+type StatusError struct {
+	StatusCode int    // HTTP status code of the response.
+	Message    string // Error message reported by the server, if any.
+}
+
+// Error returns the error message reported by the server, or the status text without one.
+func (e *StatusError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("%d %s", e.StatusCode, http.StatusText(e.StatusCode))
+	}
+	return e.Message
+}
+
+// Unwrap returns the sentinel error of the status code, so that e.g.
+// errors.Is(err, ErrNotFound) reports whether an interface doesn't exist.
+func (e *StatusError) Unwrap() error {
+	switch e.StatusCode {
+	case http.StatusBadRequest:
+		return ErrBadRequest
+	case http.StatusNotFound:
+		return ErrNotFound
+	case http.StatusInternalServerError:
+		return ErrServer
+	default:
+		return nil
+	}
+}
+
+// Client is a client of the v2 API of an Interfacer server.
+type Client struct {
+	baseURL string // URL of the server without a trailing slash, e.g. http://localhost:8080.
+
+	// HTTPClient is the client used for requests, http.DefaultClient if nil.
+	HTTPClient *http.Client
+	// RetryInterval is the time Watch waits before reconnecting to the event stream.
+	RetryInterval time.Duration
+}
+
+// New creates a new Client for the server at the given base URL, e.g. http://localhost:8080.
+func New(baseURL string) *Client {
+	return &Client{
+		baseURL:       strings.TrimSuffix(baseURL, "/"),
+		RetryInterval: 5 * time.Second,
+	}
+}
+
+// ListInterfaces returns the details of all network interfaces.
+func (c *Client) ListInterfaces(ctx context.Context) ([]api.NetworkInterfaceV2, error) {
+	var body api.NetworkInterfacesV2
+	if err := c.get(ctx, "/v2/network", &body); err != nil {
+		return nil, err
+	}
+	return body.Interfaces, nil
+}
+
+// GetInterface returns the details of a network interface by its name.
+// It returns an error matching ErrNotFound if the interface doesn't exist.
+func (c *Client) GetInterface(ctx context.Context, name string) (*api.NetworkInterfaceV2, error) {
+	var body api.NetworkInterfacesV2
+	if err := c.get(ctx, "/v2/network?interface="+url.QueryEscape(name), &body); err != nil {
+		return nil, err
+	}
+	if len(body.Interfaces) != 1 {
+		return nil, fmt.Errorf("unexpected number of interfaces in response: %d", len(body.Interfaces))
+	}
+	return &body.Interfaces[0], nil
+}
+
+// Topology returns the graph of all interfaces and their relationships.
+// Use Topology.DOT to render it as a Graphviz document.
+func (c *Client) Topology(ctx context.Context) (*api.Topology, error) {
+	var topology api.Topology
+	if err := c.get(ctx, "/v2/topology", &topology); err != nil {
+		return nil, err
+	}
+	return &topology, nil
+}
+
+// Watch streams the interface change events until the context is cancelled.
+// Errors connecting to the event stream are returned directly. Once connected,
+// the stream is reconnected after RetryInterval whenever it ends, resuming after
+// the last event received. The returned channel is closed when the context is done.
+func (c *Client) Watch(ctx context.Context) (<-chan api.Event, error) {
+	body, err := c.openEvents(ctx, "")
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan api.Event)
+	go func() {
+		defer close(events)
+
+		var lastEventID string
+		for {
+			readEvents(body, func(event api.Event) bool {
+				select {
+				case events <- event:
+					lastEventID = fmt.Sprint(event.ID)
+					return true
+				case <-ctx.Done():
+					return false
+				}
+			})
+			body.Close()
+
+			// Reconnect until the context is done, resuming after the last event
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case <-time.After(c.RetryInterval):
+				}
+				if body, err = c.openEvents(ctx, lastEventID); err == nil {
+					break
+				}
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+// openEvents connects to the event stream, resuming after lastEventID if it is not empty.
+func (c *Client) openEvents(ctx context.Context, lastEventID string) (io.ReadCloser, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/v2/network/events", nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, statusError(resp)
+	}
+
+	return resp.Body, nil
+}
+
+// readEvents reads a Server-Sent Events stream of interface change events and passes every
+// event to fn until the stream ends or fn returns false. Malformed events are skipped.
+func readEvents(r io.Reader, fn func(api.Event) bool) {
+	// Events are separated by blank lines, lines starting with a colon are comments
+	var data string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if data != "" {
+				var event api.Event
+				if err := json.Unmarshal([]byte(data), &event); err == nil && !fn(event) {
+					return
+				}
+			}
+			data = ""
+		case strings.HasPrefix(line, "data:"):
+			data += strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		}
+	}
+}
+
+// get sends a GET request to the path and decodes the JSON response into v.
+func (c *Client) get(ctx context.Context, path string, v interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient().Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return statusError(resp)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// httpClient returns the HTTP client used for requests.
+func (c *Client) httpClient() *http.Client {
+	if c.HTTPClient != nil {
+		return c.HTTPClient
+	}
+	return http.DefaultClient
+}
+
+// statusError builds a StatusError from an error response, including the message
+// of the JSON error body if there is one.
+func statusError(resp *http.Response) error {
+	var body api.Error
+	json.NewDecoder(resp.Body).Decode(&body)
+	return &StatusError{StatusCode: resp.StatusCode, Message: body.Error}
+}
@@ -0,0 +1,144 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	api "apimodule"
+)
+
+// TestClient_Errors tests that error responses are returned as typed errors.
+func TestClient_Errors(t *testing.T) {
+	tests := []struct {
+		name            string
+		code            int
+		body            string
+		expectedErr     error
+		expectedMessage string
+	}{
+		{
+			name:            "BadRequest",
+			code:            http.StatusBadRequest,
+			body:            `{"error":"only ?interface={interface_name} and ?stats={true|false} input formats are allowed"}`,
+			expectedErr:     ErrBadRequest,
+			expectedMessage: "only ?interface={interface_name} and ?stats={true|false} input formats are allowed",
+		},
+		{
+			name:            "NotFound",
+			code:            http.StatusNotFound,
+			body:            `{"error":"there is no such interface"}`,
+			expectedErr:     ErrNotFound,
+			expectedMessage: "there is no such interface",
+		},
+		{
+			name:            "InternalServerError",
+			code:            http.StatusInternalServerError,
+			body:            `not json`,
+			expectedErr:     ErrServer,
+			expectedMessage: "500 Internal Server Error",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(test.code)
+				w.Write([]byte(test.body))
+			}))
+			defer server.Close()
+
+			_, err := New(server.URL).GetInterface(context.Background(), "eth1")
+
+			var statusErr *StatusError
+			if !errors.As(err, &statusErr) || statusErr.StatusCode != test.code {
+				t.Fatalf("expected a StatusError with code %d, got %v", test.code, err)
+			}
+			if !errors.Is(err, test.expectedErr) {
+				t.Errorf("expected error matching %v, got %v", test.expectedErr, err)
+			}
+			if err.Error() != test.expectedMessage {
+				t.Errorf("error message mismatch: got %q, want %q", err.Error(), test.expectedMessage)
+			}
+		})
+	}
+}
+
+// TestClient_ListInterfaces tests decoding the interfaces of the v2 API.
+func TestClient_ListInterfaces(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/network" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Write([]byte(`{"network_interfaces":[
+			{"name":"eth0","mtu":1500,"speed_mbps":1000,"duplex":"full","admin_status":"up","oper_status":"up",
+			 "addresses":[{"address":"192.168.1.10","prefix_length":24,"family":"ipv4","scope":"global","flags":["permanent"]}]},
+			{"name":"lo","mtu":65536,"speed_mbps":null,"duplex":"unknown","admin_status":"up","oper_status":"unknown","addresses":[]}
+		]}`))
+	}))
+	defer server.Close()
+
+	interfaces, err := New(server.URL).ListInterfaces(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(interfaces) != 2 {
+		t.Fatalf("unexpected number of interfaces: got %d, want 2", len(interfaces))
+	}
+
+	eth0, lo := interfaces[0], interfaces[1]
+	if eth0.SpeedMbps == nil || *eth0.SpeedMbps != 1000 || eth0.Addresses[0].PrefixLength != 24 {
+		t.Errorf("unexpected interface: %+v", eth0)
+	}
+	if lo.SpeedMbps != nil || lo.OperStatus != api.OperStatusUnknown {
+		t.Errorf("unexpected interface: %+v", lo)
+	}
+}
+
+// TestClient_Watch tests that the event stream is reconnected and resumed after the last event.
+func TestClient_Watch(t *testing.T) {
+	var connections atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+
+		// Every connection delivers the next event and ends the stream
+		id := connections.Add(1)
+		if id > 1 && r.Header.Get("Last-Event-ID") != fmt.Sprint(id-1) {
+			t.Errorf("unexpected Last-Event-ID header: %q", r.Header.Get("Last-Event-ID"))
+		}
+		fmt.Fprintf(w, ": keep-alive\n\nid: %d\nevent: link_up\ndata: {\"id\":%d,\"type\":\"link_up\",\"interface\":\"eth0\"}\n\n", id, id)
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	client := New(server.URL)
+	client.RetryInterval = 10 * time.Millisecond
+
+	events, err := client.Watch(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		select {
+		case event := <-events:
+			if event.ID != uint64(i) || event.Type != api.EventLinkUp || event.Interface != "eth0" {
+				t.Errorf("unexpected event: %+v", event)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for event %d", i)
+		}
+	}
+
+	// The channel is closed once the context is cancelled
+	cancel()
+	for range events {
+	}
+}
@@ -0,0 +1,58 @@
+package api
+
+import "time"
+
+// InterfacePatch represents the changes of a PATCH request to an interface.
+// Fields that are left out are not changed.
+type InterfacePatch struct {
+	MTU         *int    `json:"mtu,omitempty"`          // New Maximum Transmission Unit.
+	AdminStatus *string `json:"admin_status,omitempty"` // New administrative status, AdminStatusUp or AdminStatusDown.
+	Alias       *string `json:"alias,omitempty"`        // New description, an empty alias removes it.
+}
+
+// AddressRequest represents the address of a POST request to the addresses of an interface.
+type AddressRequest struct {
+	Address string `json:"address"` // IP address with its prefix length in CIDR notation, e.g. 192.0.2.10/24.
+}
+
+// Fields of an interface changed by write operations.
+const (
+	FieldMTU         = "mtu"
+	FieldAdminStatus = "admin_status"
+	FieldAlias       = "alias"
+	FieldAddresses   = "addresses"
+	FieldRoutes      = "routes"
+)
+
+// FieldChange represents the change of a field of an interface by a write operation.
+// Addresses are changed one at a time in CIDR notation and routes one at a time in the notation
+// of `ip route` (e.g. "0.0.0.0/0 via 192.0.2.1 metric 100"), with an empty old value when they
+// are added and an empty new value when they're removed.
+type FieldChange struct {
+	Field string `json:"field"` // Changed field, one of the Field* constants.
+	Old   string `json:"old"`   // Value before the change, e.g. "1500".
+	New   string `json:"new"`   // Value after the change, e.g. "9000".
+}
+
+// ChangeSet represents the changes a write operation makes to an interface, as returned
+// by write operations in dry-run mode without applying them.
+type ChangeSet struct {
+	Interface string        `json:"interface"` // Name of the network interface.
+	DryRun    bool          `json:"dry_run"`   // Whether the changes were only computed.
+	Changes   []FieldChange `json:"changes"`   // Changes in the order they are applied, empty if nothing changes.
+}
+
+// PendingChange represents the changes of a write operation in commit-confirmed mode,
+// which are rolled back unless they are confirmed before the deadline.
+type PendingChange struct {
+	ID        string              `json:"id"`              // Identifier to confirm or roll back the change with.
+	Interface string              `json:"interface"`       // Name of the network interface.
+	Changes   []FieldChange       `json:"changes"`         // Applied changes, rolled back in reverse order.
+	Deadline  time.Time           `json:"deadline"`        // Time the changes are rolled back unless confirmed.
+	State     *NetworkInterfaceV2 `json:"state,omitempty"` // State of the interface after applying the changes.
+}
+
+// PendingChanges represents the response of the /v2/changes endpoint.
+type PendingChanges struct {
+	Changes []PendingChange `json:"changes"` // Unconfirmed changes, ordered by deadline.
+}
@@ -0,0 +1,39 @@
+package api
+
+// DesiredState represents the desired configuration of network interfaces, which the server
+// plans and applies the changes to reach. Interfaces and fields that are left out aren't managed.
+type DesiredState struct {
+	Interfaces []DesiredInterface `json:"interfaces"` // Managed interfaces.
+}
+
+// DesiredInterface represents the desired configuration of a network interface.
+// Addresses and routes are only managed if their lists are set, an empty list removes them all.
+type DesiredInterface struct {
+	Name        string         `json:"name"`                   // Name of the network interface.
+	MTU         *int           `json:"mtu,omitempty"`          // Maximum Transmission Unit.
+	AdminStatus *string        `json:"admin_status,omitempty"` // Administrative status, AdminStatusUp or AdminStatusDown.
+	Alias       *string        `json:"alias,omitempty"`        // Description, empty for none.
+	Addresses   []string       `json:"addresses"`              // IP addresses in CIDR notation, link-local addresses aren't managed.
+	Routes      []DesiredRoute `json:"routes"`                 // Static routes through the interface in the main routing table.
+}
+
+// DesiredRoute represents a static route through an interface in the main routing table.
+type DesiredRoute struct {
+	Destination string `json:"destination"`       // Destination prefix in CIDR notation, or default.
+	Gateway     string `json:"gateway,omitempty"` // Next hop, empty for directly connected destinations.
+	Metric      uint32 `json:"metric,omitempty"`  // Priority of the route, lower values are preferred.
+}
+
+// Plan represents the changes that bring the network interfaces to their desired state.
+type Plan struct {
+	InSync     bool        `json:"in_sync"`           // Whether every interface is in its desired state.
+	Interfaces []ChangeSet `json:"interfaces"`        // Changes of the interfaces that drifted, in the order of the desired state.
+	Missing    []string    `json:"missing,omitempty"` // Names of desired interfaces that don't exist.
+}
+
+// ApplyResult represents the response of applying the desired state.
+type ApplyResult struct {
+	Applied []ChangeSet     `json:"applied"`           // Changes applied to the interfaces.
+	Pending []PendingChange `json:"pending,omitempty"` // Pending change of every changed interface in commit-confirmed mode.
+	Missing []string        `json:"missing,omitempty"` // Names of desired interfaces that don't exist and were skipped.
+}
@@ -0,0 +1,86 @@
+package api
+
+// Ethtool represents the device settings of an interface as reported by `ethtool`.
+// Sections the device or the kernel doesn't support are left out.
+type Ethtool struct {
+	Name      string       `json:"name"`                 // Name of the interface.
+	Driver    *DriverInfo  `json:"driver,omitempty"`     // Driver and firmware of the device.
+	LinkModes *LinkModes   `json:"link_modes,omitempty"` // Link modes and autonegotiation.
+	Features  []Feature    `json:"features,omitempty"`   // Offload features, sorted by name.
+	Rings     *RingSizes   `json:"rings,omitempty"`      // Sizes of the DMA rings.
+	Channels  *Channels    `json:"channels,omitempty"`   // Numbers of queues.
+	Pause     *PauseParams `json:"pause,omitempty"`      // Flow control.
+	Module    *ModuleInfo  `json:"module,omitempty"`     // Plugged transceiver module, e.g. an SFP.
+}
+
+// DriverInfo represents the driver of a device, as listed by `ethtool -i`.
+type DriverInfo struct {
+	Driver          string `json:"driver"`           // Name of the driver, e.g. ixgbe.
+	Version         string `json:"version"`          // Version of the driver.
+	FirmwareVersion string `json:"firmware_version"` // Version of the device firmware, empty if it has none.
+	BusInfo         string `json:"bus_info"`         // Bus address of the device, e.g. 0000:03:00.0.
+}
+
+// LinkModes represents the link settings of a device, as listed by `ethtool`.
+// Link mode names are those of the kernel, e.g. 10000baseT/Full.
+type LinkModes struct {
+	Port              string   `json:"port,omitempty"`     // Connector type, e.g. tp, fibre or da.
+	Autoneg           bool     `json:"autoneg"`            // Whether autonegotiation is enabled.
+	SpeedMbps         *int64   `json:"speed_mbps"`         // Current speed in Mb/s, null if unknown.
+	Duplex            string   `json:"duplex"`             // Current duplex mode, one of the Duplex* constants.
+	Supported         []string `json:"supported"`          // Link modes the device supports.
+	Advertised        []string `json:"advertised"`         // Link modes advertised to the link partner.
+	PartnerAdvertised []string `json:"partner_advertised"` // Link modes advertised by the link partner.
+}
+
+// Feature represents an offload feature of a device, as listed by `ethtool -k`.
+// Features that are off and can't be turned on are left out.
+type Feature struct {
+	Name    string `json:"name"`    // Name of the feature, e.g. tx-tcp-segmentation or rx-gro.
+	Enabled bool   `json:"enabled"` // Whether the feature is active.
+	Fixed   bool   `json:"fixed"`   // Whether the feature can't be changed.
+}
+
+// RingSizes represents the sizes of the DMA rings of a device, as listed by `ethtool -g`.
+type RingSizes struct {
+	RX         uint32 `json:"rx"`           // Current number of RX ring entries.
+	RXMax      uint32 `json:"rx_max"`       // Maximum number of RX ring entries.
+	RXMini     uint32 `json:"rx_mini"`      // Current number of RX mini ring entries.
+	RXMiniMax  uint32 `json:"rx_mini_max"`  // Maximum number of RX mini ring entries.
+	RXJumbo    uint32 `json:"rx_jumbo"`     // Current number of RX jumbo ring entries.
+	RXJumboMax uint32 `json:"rx_jumbo_max"` // Maximum number of RX jumbo ring entries.
+	TX         uint32 `json:"tx"`           // Current number of TX ring entries.
+	TXMax      uint32 `json:"tx_max"`       // Maximum number of TX ring entries.
+}
+
+// Channels represents the numbers of queues of a device, as listed by `ethtool -l`.
+type Channels struct {
+	RX          uint32 `json:"rx"`           // Current number of RX-only channels.
+	RXMax       uint32 `json:"rx_max"`       // Maximum number of RX-only channels.
+	TX          uint32 `json:"tx"`           // Current number of TX-only channels.
+	TXMax       uint32 `json:"tx_max"`       // Maximum number of TX-only channels.
+	Other       uint32 `json:"other"`        // Current number of other channels, e.g. for link interrupts.
+	OtherMax    uint32 `json:"other_max"`    // Maximum number of other channels.
+	Combined    uint32 `json:"combined"`     // Current number of combined RX/TX channels.
+	CombinedMax uint32 `json:"combined_max"` // Maximum number of combined RX/TX channels.
+}
+
+// PauseParams represents the flow control settings of a device, as listed by `ethtool -a`.
+type PauseParams struct {
+	Autoneg bool `json:"autoneg"` // Whether pause frames are negotiated with the link partner.
+	RX      bool `json:"rx"`      // Whether received pause frames are honored.
+	TX      bool `json:"tx"`      // Whether pause frames are sent.
+}
+
+// ModuleInfo represents the identification of a transceiver module read from its EEPROM,
+// as listed by `ethtool -m`.
+type ModuleInfo struct {
+	Identifier   string `json:"identifier"`              // Type of the module, e.g. SFP or QSFP28.
+	VendorName   string `json:"vendor_name"`             // Name of the vendor.
+	VendorOUI    string `json:"vendor_oui"`              // IEEE company ID of the vendor, e.g. 00:90:65.
+	PartNumber   string `json:"part_number"`             // Part number assigned by the vendor.
+	Revision     string `json:"revision"`                // Revision of the part.
+	SerialNumber string `json:"serial_number"`           // Serial number of the module.
+	DateCode     string `json:"date_code"`               // Manufacturing date, as YYMMDD followed by an optional lot code.
+	WavelengthNm int    `json:"wavelength_nm,omitempty"` // Laser wavelength of optical modules in nm.
+}
@@ -0,0 +1,62 @@
+package api
+
+import "time"
+
+// Types of the change events emitted for network interfaces.
+const (
+	EventInterfaceCreated = "interface_created" // A new interface appeared.
+	EventInterfaceDeleted = "interface_deleted" // An interface disappeared.
+	EventLinkUp           = "link_up"           // The operational status changed to UP.
+	EventLinkDown         = "link_down"         // The operational status changed from UP to anything else.
+	EventAddressAdded     = "address_added"     // An IP address was assigned to the interface.
+	EventAddressRemoved   = "address_removed"   // An IP address was removed from the interface.
+	EventMTUChanged       = "mtu_changed"       // The MTU of the interface changed.
+	EventDriftDetected    = "drift_detected"    // The interface drifted from its desired state.
+	EventDriftResolved    = "drift_resolved"    // The interface is back in its desired state.
+)
+
+// Event represents a change of a network interface.
+type Event struct {
+	ID        uint64        `json:"id"`                // Sequence number of the event, usable as Last-Event-ID.
+	Type      string        `json:"type"`              // Type of the event, one of the Event* constants.
+	Interface string        `json:"interface"`         // Name of the network interface.
+	Timestamp time.Time     `json:"timestamp"`         // Time the change was observed.
+	Address   string        `json:"address,omitempty"` // The added or removed IP address.
+	OldMTU    int           `json:"old_mtu,omitempty"` // MTU before the change.
+	NewMTU    int           `json:"new_mtu,omitempty"` // MTU after the change.
+	Changes   []FieldChange `json:"changes,omitempty"` // Changes that bring a drifted interface back to its desired state.
+}
+
+// EventStats is the pseudo event type that subscribes to periodic traffic counter updates.
+const EventStats = "stats"
+
+// Subscription actions sent by WebSocket clients.
+const (
+	ActionSubscribe   = "subscribe"   // Add interfaces and event types to the subscription.
+	ActionUnsubscribe = "unsubscribe" // Remove interfaces and event types from the subscription.
+)
+
+// Subscription represents the interfaces and event types a WebSocket client is subscribed to.
+// The wildcard "*" matches every interface or every event type, including EventStats.
+type Subscription struct {
+	Action     string   `json:"action,omitempty"` // ActionSubscribe or ActionUnsubscribe in client messages.
+	Interfaces []string `json:"interfaces"`       // Names of the network interfaces.
+	Events     []string `json:"events"`           // Event types, one of the Event* constants.
+}
+
+// Types of the messages sent to WebSocket clients.
+const (
+	MessageEvent        = "event"        // An interface change event.
+	MessageStats        = "stats"        // A periodic traffic counter update.
+	MessageSubscription = "subscription" // The subscription after a subscribe or unsubscribe message.
+	MessageError        = "error"        // An invalid client message.
+)
+
+// Message represents a message sent to WebSocket clients.
+type Message struct {
+	Type         string                 `json:"type"`                   // Type of the message, one of the Message* constants.
+	Event        *Event                 `json:"event,omitempty"`        // Set for event messages.
+	Stats        *NetworkInterfaceStats `json:"stats,omitempty"`        // Set for stats messages.
+	Subscription *Subscription          `json:"subscription,omitempty"` // Set for subscription messages.
+	Error        string                 `json:"error,omitempty"`        // Set for error messages.
+}
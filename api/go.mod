@@ -0,0 +1,3 @@
+module apimodule
+
+go 1.22.2
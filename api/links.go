@@ -0,0 +1,72 @@
+package api
+
+// Link kinds of NetworkInterfaceV2. Other kinds reported by the kernel (e.g. tun or wireguard)
+// are passed through as they are, but without details.
+const (
+	KindDevice = "device" // Physical or loopback device without a link kind.
+	KindBond   = "bond"   // Bonding (link aggregation) master.
+	KindBridge = "bridge" // Ethernet bridge.
+	KindVLAN   = "vlan"   // 802.1Q/802.1ad VLAN on top of a parent link.
+	KindVXLAN  = "vxlan"  // VXLAN tunnel endpoint.
+	KindVeth   = "veth"   // One end of a virtual ethernet pair.
+)
+
+// LinkDetails holds the kind-specific details of a link. Only the field matching the kind is set.
+type LinkDetails struct {
+	Bond   *BondDetails   `json:"bond,omitempty"`   // Details of a KindBond link.
+	Bridge *BridgeDetails `json:"bridge,omitempty"` // Details of a KindBridge link.
+	VLAN   *VLANDetails   `json:"vlan,omitempty"`   // Details of a KindVLAN link.
+	VXLAN  *VXLANDetails  `json:"vxlan,omitempty"`  // Details of a KindVXLAN link.
+	Veth   *VethDetails   `json:"veth,omitempty"`   // Details of a KindVeth link.
+}
+
+// BondDetails represents the configuration and slave state of a bond.
+type BondDetails struct {
+	Mode        string      `json:"mode"`                   // Bonding mode, e.g. active-backup or 802.3ad.
+	ActiveSlave string      `json:"active_slave,omitempty"` // Name of the active slave in active-backup mode.
+	MIIMonMs    int         `json:"miimon_ms"`              // MII link monitoring interval in milliseconds, 0 if disabled.
+	Slaves      []BondSlave `json:"slaves"`                 // State of the enslaved interfaces.
+}
+
+// BondSlave represents the state of an interface enslaved to a bond.
+type BondSlave struct {
+	Name         string `json:"name"`          // Name of the slave interface.
+	State        string `json:"state"`         // Slave state, active or backup.
+	MIIStatus    string `json:"mii_status"`    // MII link status, up, fail, down or back.
+	LinkFailures uint32 `json:"link_failures"` // Number of link failures detected on the slave.
+}
+
+// BridgeDetails represents the configuration and ports of a bridge.
+type BridgeDetails struct {
+	STPEnabled    bool         `json:"stp_enabled"`    // Whether the spanning tree protocol is enabled.
+	VLANFiltering bool         `json:"vlan_filtering"` // Whether VLAN filtering is enabled.
+	Ports         []BridgePort `json:"ports"`          // State of the bridge ports.
+}
+
+// BridgePort represents an interface attached to a bridge.
+type BridgePort struct {
+	Name  string `json:"name"`  // Name of the port interface.
+	State string `json:"state"` // STP port state, disabled, listening, learning, forwarding or blocking.
+}
+
+// VLANDetails represents the configuration of a VLAN link.
+type VLANDetails struct {
+	ID       int    `json:"id"`               // VLAN ID.
+	Protocol string `json:"protocol"`         // VLAN protocol, 802.1Q or 802.1ad.
+	Parent   string `json:"parent,omitempty"` // Name of the link the VLAN is stacked on.
+}
+
+// VXLANDetails represents the configuration of a VXLAN link.
+type VXLANDetails struct {
+	VNI    uint32 `json:"vni"`              // VXLAN network identifier.
+	Remote string `json:"remote,omitempty"` // Unicast remote or multicast group address.
+	Local  string `json:"local,omitempty"`  // Local source address.
+	Port   int    `json:"port"`             // UDP destination port.
+	Parent string `json:"parent,omitempty"` // Name of the link the tunnel is bound to.
+}
+
+// VethDetails represents the peer of a veth link.
+type VethDetails struct {
+	PeerIndex int    `json:"peer_index"`     // Interface index of the peer, which may live in another network namespace.
+	Peer      string `json:"peer,omitempty"` // Name of the peer, if it is in the same network namespace.
+}
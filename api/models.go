@@ -0,0 +1,26 @@
+// Package api contains the wire types of the Interfacer HTTP API, shared by the
+// server and its clients.
+package api
+
+// NetworkInterface represents details about a network interface.
+type NetworkInterface struct {
+	Name              string          `json:"name"`               // Name of the network interface.
+	IPAddresses       []string        `json:"ip_addresses"`       // List of IP addresses associated with the interface.
+	MACAddress        string          `json:"mac_address"`        // MAC address of the interface.
+	MTU               int             `json:"mtu"`                // Maximum Transmission Unit (MTU) of the interface.
+	Speed             string          `json:"speed"`              // Speed of the interface.
+	Duplex            string          `json:"duplex"`             // Duplex mode of the interface.
+	AdminStatus       string          `json:"admin_status"`       // Administrative status of the interface.
+	OperationalStatus string          `json:"operational_status"` // Operational status of the interface.
+	Stats             *InterfaceStats `json:"stats,omitempty"`    // Traffic counters of the interface, if requested.
+}
+
+// NetworkInterfaces represents a collection of network interfaces.
+type NetworkInterfaces struct {
+	Interfaces []NetworkInterface `json:"network_interface"` // List of network interfaces.
+}
+
+// Error represents an error response.
+type Error struct {
+	Error string `json:"error"` // Error message.
+}
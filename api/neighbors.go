@@ -0,0 +1,29 @@
+package api
+
+// Neighbor states reported in Neighbor.State, as listed by `ip neigh`.
+const (
+	NeighborIncomplete = "INCOMPLETE" // Address resolution is in progress.
+	NeighborReachable  = "REACHABLE"  // The neighbor was recently confirmed to be reachable.
+	NeighborStale      = "STALE"      // The neighbor has not been confirmed recently, it is probed on next use.
+	NeighborDelay      = "DELAY"      // Reachability confirmation is delayed, waiting for upper layer traffic.
+	NeighborProbe      = "PROBE"      // The neighbor is being probed.
+	NeighborFailed     = "FAILED"     // Address resolution failed, the neighbor did not answer.
+	NeighborNoARP      = "NOARP"      // The entry does not require address resolution.
+	NeighborPermanent  = "PERMANENT"  // The entry was added statically and never expires.
+	NeighborNone       = "NONE"       // The entry has no state.
+)
+
+// Neighbor represents an entry of the kernel neighbor table, learned via ARP (IPv4) or NDP (IPv6).
+type Neighbor struct {
+	Family     string   `json:"family"`                // Address family, FamilyIPv4 or FamilyIPv6.
+	Address    string   `json:"address"`               // IP address of the neighbor.
+	MACAddress string   `json:"mac_address,omitempty"` // Link layer address of the neighbor, empty if unresolved.
+	Interface  string   `json:"interface"`             // Name of the interface the neighbor was seen on.
+	State      string   `json:"state"`                 // Neighbor state, e.g. NeighborReachable or NeighborFailed.
+	Flags      []string `json:"flags"`                 // Entry flags, e.g. router or proxy.
+}
+
+// Neighbors represents a collection of neighbor table entries.
+type Neighbors struct {
+	Neighbors []Neighbor `json:"neighbors"` // List of neighbors.
+}
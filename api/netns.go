@@ -0,0 +1,14 @@
+package api
+
+// Namespace represents a network namespace the server can report the interfaces of.
+type Namespace struct {
+	Name    string `json:"name"`          // Reference of the namespace, its name in /run/netns or pid:{pid}.
+	Inode   uint64 `json:"inode"`         // Inode of the namespace, identical for all references to it.
+	PID     int    `json:"pid,omitempty"` // Lowest process in the namespace, only set for PID references.
+	Current bool   `json:"current"`       // Whether it is the namespace of the server itself.
+}
+
+// Namespaces represents a collection of network namespaces.
+type Namespaces struct {
+	Namespaces []Namespace `json:"namespaces"` // List of namespaces.
+}
@@ -0,0 +1,54 @@
+package api
+
+// Route represents an entry of a kernel routing table.
+type Route struct {
+	Family      string    `json:"family"`              // Address family, FamilyIPv4 or FamilyIPv6.
+	Destination string    `json:"destination"`         // Destination prefix in CIDR notation, e.g. 0.0.0.0/0 for the default route.
+	Source      string    `json:"source,omitempty"`    // Preferred source address for packets to the destination.
+	Gateway     string    `json:"gateway,omitempty"`   // Next hop, empty for directly connected destinations.
+	Interface   string    `json:"interface,omitempty"` // Name of the output interface.
+	Metric      uint32    `json:"metric"`              // Priority of the route, lower values are preferred.
+	Protocol    string    `json:"protocol"`            // Origin of the route, e.g. kernel, boot, static or dhcp.
+	Scope       string    `json:"scope"`               // Distance to the destination (global, site, link, host or nowhere).
+	Table       string    `json:"table"`               // Routing table, main, local, default or its number.
+	Type        string    `json:"type"`                // Type of the route, e.g. unicast, local, broadcast or blackhole.
+	Nexthops    []Nexthop `json:"nexthops,omitempty"`  // Next hops of a multipath route.
+}
+
+// Nexthop represents one of the next hops of a multipath route.
+type Nexthop struct {
+	Gateway   string `json:"gateway,omitempty"`   // Next hop, empty for directly connected destinations.
+	Interface string `json:"interface,omitempty"` // Name of the output interface.
+	Weight    int    `json:"weight"`              // Relative weight of the next hop.
+}
+
+// Routes represents a collection of routes.
+type Routes struct {
+	Routes []Route `json:"routes"` // List of routes.
+}
+
+// RouteLookup represents the route the kernel selects for a destination address.
+type RouteLookup struct {
+	Destination string `json:"destination"` // The looked up destination address.
+	Route       Route  `json:"route"`       // The routing table entry matching the destination.
+}
+
+// Rule represents a policy routing rule.
+type Rule struct {
+	Family          string `json:"family"`                     // Address family, FamilyIPv4 or FamilyIPv6.
+	Priority        uint32 `json:"priority"`                   // Priority of the rule, rules are evaluated in ascending order.
+	Source          string `json:"source,omitempty"`           // Source prefix the rule matches, any source if empty.
+	Destination     string `json:"destination,omitempty"`      // Destination prefix the rule matches, any destination if empty.
+	InputInterface  string `json:"input_interface,omitempty"`  // Name of the interface packets arrive on.
+	OutputInterface string `json:"output_interface,omitempty"` // Name of the interface packets leave through.
+	FwMark          uint32 `json:"fwmark,omitempty"`           // Firewall mark the rule matches.
+	FwMask          uint32 `json:"fwmask,omitempty"`           // Mask applied to the firewall mark.
+	Invert          bool   `json:"invert,omitempty"`           // Whether the selector is inverted ("not").
+	Action          string `json:"action"`                     // Action of the rule, e.g. lookup, goto, blackhole or unreachable.
+	Table           string `json:"table,omitempty"`            // Routing table looked up by the rule.
+}
+
+// Rules represents a collection of policy routing rules.
+type Rules struct {
+	Rules []Rule `json:"rules"` // List of rules.
+}
@@ -0,0 +1,100 @@
+package api
+
+import "time"
+
+// InterfaceStats represents the traffic counters of a network interface.
+type InterfaceStats struct {
+	RxBytes    uint64          `json:"rx_bytes"`        // Number of bytes received.
+	TxBytes    uint64          `json:"tx_bytes"`        // Number of bytes transmitted.
+	RxPackets  uint64          `json:"rx_packets"`      // Number of packets received.
+	TxPackets  uint64          `json:"tx_packets"`      // Number of packets transmitted.
+	RxErrors   uint64          `json:"rx_errors"`       // Number of bad packets received.
+	TxErrors   uint64          `json:"tx_errors"`       // Number of packets that failed to transmit.
+	RxDropped  uint64          `json:"rx_dropped"`      // Number of received packets dropped.
+	TxDropped  uint64          `json:"tx_dropped"`      // Number of packets dropped before transmission.
+	Multicast  uint64          `json:"multicast"`       // Number of multicast packets received.
+	Collisions uint64          `json:"collisions"`      // Number of collisions during transmission.
+	Rates      *InterfaceRates `json:"rates,omitempty"` // Per-second rates since the previous sample, if any.
+}
+
+// InterfaceRates represents the per-second rates of the traffic counters,
+// derived from two consecutive samples of InterfaceStats.
+type InterfaceRates struct {
+	Interval   float64 `json:"interval_seconds"` // Time between the two samples in seconds.
+	RxBytes    float64 `json:"rx_bytes"`         // Bytes received per second.
+	TxBytes    float64 `json:"tx_bytes"`         // Bytes transmitted per second.
+	RxPackets  float64 `json:"rx_packets"`       // Packets received per second.
+	TxPackets  float64 `json:"tx_packets"`       // Packets transmitted per second.
+	RxErrors   float64 `json:"rx_errors"`        // Receive errors per second.
+	TxErrors   float64 `json:"tx_errors"`        // Transmit errors per second.
+	RxDropped  float64 `json:"rx_dropped"`       // Received packets dropped per second.
+	TxDropped  float64 `json:"tx_dropped"`       // Transmitted packets dropped per second.
+	Multicast  float64 `json:"multicast"`        // Multicast packets received per second.
+	Collisions float64 `json:"collisions"`       // Collisions per second.
+}
+
+// NetworkInterfaceStats represents the traffic statistics of a single network interface.
+type NetworkInterfaceStats struct {
+	Name  string         `json:"name"`  // Name of the network interface.
+	Stats InterfaceStats `json:"stats"` // Traffic counters and rates of the interface.
+}
+
+// RatesSince computes the per-second rates between a previous sample of the counters and this one.
+// It returns nil if no time has passed or if any counter went backwards, e.g. because
+// the interface was recreated, as the difference would be meaningless.
+func (s InterfaceStats) RatesSince(prev InterfaceStats, elapsed time.Duration) *InterfaceRates {
+	if elapsed <= 0 {
+		return nil
+	}
+
+	cur := s.counters()
+	old := prev.counters()
+	for i := range cur {
+		if cur[i] < old[i] {
+			return nil
+		}
+	}
+
+	secs := elapsed.Seconds()
+	rate := func(i int) float64 {
+		return float64(cur[i]-old[i]) / secs
+	}
+
+	return &InterfaceRates{
+		Interval:   secs,
+		RxBytes:    rate(0),
+		TxBytes:    rate(1),
+		RxPackets:  rate(2),
+		TxPackets:  rate(3),
+		RxErrors:   rate(4),
+		TxErrors:   rate(5),
+		RxDropped:  rate(6),
+		TxDropped:  rate(7),
+		Multicast:  rate(8),
+		Collisions: rate(9),
+	}
+}
+
+// counters returns the counters in a fixed order, which RatesSince relies on.
+func (s InterfaceStats) counters() [10]uint64 {
+	return [10]uint64{
+		s.RxBytes, s.TxBytes, s.RxPackets, s.TxPackets, s.RxErrors,
+		s.TxErrors, s.RxDropped, s.TxDropped, s.Multicast, s.Collisions,
+	}
+}
+
+// InterfaceSnapshot represents the state of a network interface at a point in time.
+type InterfaceSnapshot struct {
+	Timestamp         time.Time       `json:"timestamp"`          // Time the snapshot was taken.
+	IPAddresses       []string        `json:"ip_addresses"`       // IP addresses associated with the interface.
+	MTU               int             `json:"mtu"`                // Maximum Transmission Unit (MTU) of the interface.
+	AdminStatus       string          `json:"admin_status"`       // Administrative status of the interface.
+	OperationalStatus string          `json:"operational_status"` // Operational status of the interface.
+	Stats             *InterfaceStats `json:"stats,omitempty"`    // Traffic counters and rates, if available.
+}
+
+// InterfaceHistory represents a time series of snapshots of a single network interface.
+type InterfaceHistory struct {
+	Name      string              `json:"name"`      // Name of the network interface.
+	Snapshots []InterfaceSnapshot `json:"snapshots"` // Snapshots in chronological order.
+}
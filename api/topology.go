@@ -0,0 +1,108 @@
+package api
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Edge types of TopologyEdge.
+const (
+	EdgeMaster = "master" // From an enslaved interface to its bond or bridge.
+	EdgeLower  = "lower"  // From a VLAN or VXLAN to the link it is stacked on.
+	EdgePeer   = "peer"   // Between the two ends of a veth pair.
+)
+
+// TopologyNode represents an interface in the topology graph.
+type TopologyNode struct {
+	Name       string `json:"name"`        // Name of the interface.
+	Kind       string `json:"kind"`        // Type of the link, one of the Kind* constants or another kernel link kind.
+	OperStatus string `json:"oper_status"` // Operational status, one of the OperStatus* constants.
+}
+
+// TopologyEdge represents a relationship between two interfaces in the topology graph.
+type TopologyEdge struct {
+	From string `json:"from"` // Name of the upper interface, e.g. the slave or the VLAN.
+	To   string `json:"to"`   // Name of the lower interface, e.g. the master or the parent.
+	Type string `json:"type"` // Type of the relationship, one of the Edge* constants.
+}
+
+// Topology represents the graph of all interfaces and their relationships.
+type Topology struct {
+	Nodes []TopologyNode `json:"nodes"` // Interfaces of the graph.
+	Edges []TopologyEdge `json:"edges"` // Relationships between the interfaces.
+}
+
+// NewTopology builds the topology graph from the master, parent and peer relationships of the interfaces.
+// Relationships with interfaces that are not part of the list, e.g. veth peers in other
+// network namespaces, are left out.
+func NewTopology(interfaces []NetworkInterfaceV2) Topology {
+	topology := Topology{Nodes: []TopologyNode{}, Edges: []TopologyEdge{}}
+
+	known := make(map[string]bool, len(interfaces))
+	for _, iface := range interfaces {
+		known[iface.Name] = true
+		topology.Nodes = append(topology.Nodes, TopologyNode{Name: iface.Name, Kind: iface.Kind, OperStatus: iface.OperStatus})
+	}
+
+	addEdge := func(from, to, edgeType string) {
+		if known[from] && known[to] {
+			topology.Edges = append(topology.Edges, TopologyEdge{From: from, To: to, Type: edgeType})
+		}
+	}
+
+	for _, iface := range interfaces {
+		if iface.Master != "" {
+			addEdge(iface.Name, iface.Master, EdgeMaster)
+		}
+
+		details := iface.Details
+		switch {
+		case details == nil:
+		case details.VLAN != nil && details.VLAN.Parent != "":
+			addEdge(iface.Name, details.VLAN.Parent, EdgeLower)
+		case details.VXLAN != nil && details.VXLAN.Parent != "":
+			addEdge(iface.Name, details.VXLAN.Parent, EdgeLower)
+		case details.Veth != nil && details.Veth.Peer > iface.Name:
+			// Both ends report each other, the pair is a single undirected edge
+			addEdge(iface.Name, details.Veth.Peer, EdgePeer)
+		}
+	}
+
+	return topology
+}
+
+// DOT renders the topology graph as a Graphviz document. Interfaces that are operationally
+// up are drawn green, veth pairs are drawn as undirected edges.
+func (t Topology) DOT() string {
+	var b strings.Builder
+	b.WriteString("digraph topology {\n")
+	b.WriteString("\trankdir=BT;\n")
+	b.WriteString("\tnode [shape=box, style=rounded];\n")
+
+	for _, node := range t.Nodes {
+		color := "gray"
+		if node.OperStatus == OperStatusUp {
+			color = "green"
+		}
+		fmt.Fprintf(&b, "\t%s [label=%s, color=%s];\n", dotID(node.Name), dotID(node.Name+"\n"+node.Kind), color)
+	}
+
+	for _, edge := range t.Edges {
+		attrs := "label=" + edge.Type
+		if edge.Type == EdgePeer {
+			attrs += ", dir=none, style=dashed"
+		}
+		fmt.Fprintf(&b, "\t%s -> %s [%s];\n", dotID(edge.From), dotID(edge.To), attrs)
+	}
+
+	b.WriteString("}\n")
+	return b.String()
+}
+
+// dotID quotes a string as a Graphviz ID.
+func dotID(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
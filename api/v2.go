@@ -0,0 +1,116 @@
+package api
+
+import "strconv"
+
+// Duplex modes of NetworkInterfaceV2.
+const (
+	DuplexFull    = "full"    // Full duplex.
+	DuplexHalf    = "half"    // Half duplex.
+	DuplexUnknown = "unknown" // The device has link settings, but doesn't know the duplex mode.
+	DuplexNone    = "none"    // No link settings were read, e.g. because the device has none like the loopback device.
+)
+
+// Administrative statuses of NetworkInterfaceV2 (RFC 2863 ifAdminStatus).
+const (
+	AdminStatusUp   = "up"   // The interface is enabled.
+	AdminStatusDown = "down" // The interface is disabled.
+)
+
+// Operational statuses of NetworkInterfaceV2 (RFC 2863 ifOperStatus).
+const (
+	OperStatusUp             = "up"             // Ready to pass packets.
+	OperStatusDown           = "down"           // Not ready to pass packets.
+	OperStatusTesting        = "testing"        // In some test mode.
+	OperStatusUnknown        = "unknown"        // Status can not be determined.
+	OperStatusDormant        = "dormant"        // Waiting for some external event.
+	OperStatusNotPresent     = "notPresent"     // Some component is missing.
+	OperStatusLowerLayerDown = "lowerLayerDown" // Down due to the state of a lower-layer interface.
+)
+
+// Address families of Address.
+const (
+	FamilyIPv4 = "ipv4"
+	FamilyIPv6 = "ipv6"
+)
+
+// Address represents an IP address assigned to a network interface.
+type Address struct {
+	Address      string   `json:"address"`       // The IP address without prefix length.
+	PrefixLength int      `json:"prefix_length"` // Length of the network prefix in bits.
+	Family       string   `json:"family"`        // Address family, FamilyIPv4 or FamilyIPv6.
+	Scope        string   `json:"scope"`         // Scope of the address (global, site, link, host or nowhere).
+	Flags        []string `json:"flags"`         // Address flags (e.g. permanent, secondary or tentative).
+}
+
+// NetworkInterfaceV2 represents details about a network interface with typed fields.
+// It is the model served by the v2 API, NetworkInterface is derived from it with V1.
+type NetworkInterfaceV2 struct {
+	Name        string          `json:"name"`               // Name of the network interface.
+	Kind        string          `json:"kind"`               // Type of the link, one of the Kind* constants or another kernel link kind.
+	Alias       string          `json:"alias,omitempty"`    // Description of the interface set by the administrator.
+	MACAddress  string          `json:"mac_address"`        // MAC address of the interface.
+	MTU         int             `json:"mtu"`                // Maximum Transmission Unit (MTU) of the interface.
+	SpeedMbps   *int64          `json:"speed_mbps"`         // Speed of the interface in Mb/s, null if unknown.
+	Duplex      string          `json:"duplex"`             // Duplex mode, one of the Duplex* constants.
+	AdminStatus string          `json:"admin_status"`       // Administrative status, one of the AdminStatus* constants.
+	OperStatus  string          `json:"oper_status"`        // Operational status, one of the OperStatus* constants.
+	Master      string          `json:"master,omitempty"`   // Name of the bond or bridge the interface is enslaved to.
+	Slaves      []string        `json:"slaves,omitempty"`   // Names of the interfaces enslaved to this bond or bridge.
+	Details     *LinkDetails    `json:"details,omitempty"`  // Kind-specific details, if the kind is known.
+	Wireless    *Wireless       `json:"wireless,omitempty"` // Wireless state, only for wireless interfaces.
+	Addresses   []Address       `json:"addresses"`          // IP addresses assigned to the interface.
+	Stats       *InterfaceStats `json:"stats,omitempty"`    // Traffic counters of the interface, if requested.
+}
+
+// NetworkInterfacesV2 represents a collection of network interfaces in the v2 API.
+type NetworkInterfacesV2 struct {
+	Interfaces []NetworkInterfaceV2 `json:"network_interfaces"` // List of network interfaces.
+}
+
+// V1 converts the interface to the v1 model with free-form strings.
+// Speeds and duplex modes that are not known are reported as "Unknown!" like the `ethtool`
+// command does, or as "N/A" if the device has no link settings at all (DuplexNone).
+// Like the operational status, the v1 admin status is derived from the operational state as
+// `ip link` reports it: enabled if up, disabled if down and unknown otherwise.
+func (n NetworkInterfaceV2) V1() NetworkInterface {
+	var ipAddrs []string
+	for _, addr := range n.Addresses {
+		ipAddrs = append(ipAddrs, addr.Address)
+	}
+
+	speed, duplex := "Unknown!", "Unknown!"
+	if n.Duplex == DuplexNone {
+		speed, duplex = "N/A", "N/A"
+	}
+
+	if n.SpeedMbps != nil {
+		speed = strconv.FormatInt(*n.SpeedMbps, 10) + "Mb/s"
+	}
+
+	switch n.Duplex {
+	case DuplexFull:
+		duplex = "Full"
+	case DuplexHalf:
+		duplex = "Half"
+	}
+
+	adminStatus, operationalStatus := "unknown", "unknown"
+	switch n.OperStatus {
+	case OperStatusUp:
+		adminStatus, operationalStatus = "enabled", "UP"
+	case OperStatusDown:
+		adminStatus, operationalStatus = "disabled", "DOWN"
+	}
+
+	return NetworkInterface{
+		Name:              n.Name,
+		IPAddresses:       ipAddrs,
+		MACAddress:        n.MACAddress,
+		MTU:               n.MTU,
+		Speed:             speed,
+		Duplex:            duplex,
+		AdminStatus:       adminStatus,
+		OperationalStatus: operationalStatus,
+		Stats:             n.Stats,
+	}
+}
@@ -0,0 +1,15 @@
+package api
+
+// Wireless represents the state of a wireless (802.11) interface.
+type Wireless struct {
+	Mode             string  `json:"mode"`                        // Interface type, e.g. station, ap, adhoc, monitor or mesh.
+	Connected        bool    `json:"connected"`                   // Whether a station is associated with an access point.
+	SSID             string  `json:"ssid,omitempty"`              // SSID of the connected or served network.
+	BSSID            string  `json:"bssid,omitempty"`             // MAC address of the access point a station is connected to.
+	FrequencyMHz     int     `json:"frequency_mhz,omitempty"`     // Operating frequency in MHz.
+	Channel          int     `json:"channel,omitempty"`           // Channel number derived from the frequency.
+	SignalDBm        *int    `json:"signal_dbm,omitempty"`        // Signal strength of the access point in dBm, if connected.
+	TxBitrateMbps    float64 `json:"tx_bitrate_mbps,omitempty"`   // Bitrate of the last transmitted frame in Mb/s.
+	RxBitrateMbps    float64 `json:"rx_bitrate_mbps,omitempty"`   // Bitrate of the last received frame in Mb/s.
+	ConnectedSeconds uint32  `json:"connected_seconds,omitempty"` // Time since the station connected to the access point.
+}
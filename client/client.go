@@ -1,26 +1,32 @@
 package main
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"net/http"
 	"os"
 	"time"
 
-	models "clientmodule/clientmodels"
+	api "apimodule"
+	"apimodule/client"
 )
 
 // Client represents the HTTP client.
 type Client struct {
-	endpoint string        // The endpoint to call.
-	interval time.Duration // The interval between calls.
+	api           *client.Client // The typed client of the server's API.
+	interfaceName string         // The interface to show, all interfaces if empty.
+	interval      time.Duration  // The interval between calls.
 }
 
-// NewClient creates a new instance of Client.
-func NewClient(endpoint string, interval time.Duration) *Client {
+// NewClient creates a new instance of Client for the server at the given base URL.
+func NewClient(baseURL, interfaceName string, interval time.Duration) *Client {
+	apiClient := client.New(baseURL)
+	apiClient.RetryInterval = interval
+
 	return &Client{
-		endpoint: endpoint,
-		interval: interval,
+		api:           apiClient,
+		interfaceName: interfaceName,
+		interval:      interval,
 	}
 }
 
@@ -39,66 +45,123 @@ func (c *Client) Start() {
 	}
 }
 
+// CallEndpoint fetches the network interfaces from the server and prints them.
 func (c *Client) CallEndpoint() {
-	// Make a GET request to the server's endpoint
-	resp, err := http.Get(c.endpoint)
-	if err != nil {
-		fmt.Println("Error:", err)
-		return
+	ctx, cancel := context.WithTimeout(context.Background(), c.interval)
+	defer cancel()
+
+	var interfaces []api.NetworkInterfaceV2
+	if c.interfaceName != "" {
+		iface, err := c.api.GetInterface(ctx, c.interfaceName)
+		if err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
+		interfaces = append(interfaces, *iface)
+	} else {
+		var err error
+		if interfaces, err = c.api.ListInterfaces(ctx); err != nil {
+			fmt.Println("Error:", err)
+			return
+		}
 	}
-	defer resp.Body.Close()
 
-	// Decode the JSON response into a slice of NetworkInterface
-	var body models.NetworkInterfaces
-	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
-		fmt.Println("Error decoding response:", err)
-		return
+	// Print the network interfaces
+	fmt.Println("Network Interfaces:")
+	for _, iface := range interfaces {
+		printInterface(iface.V1())
 	}
+}
 
-	// Check for errors returned from the server
-	if body.Error != "" {
-		fmt.Println("Error:", body.Error)
-		return
+// printInterface prints the details of a single network interface.
+func printInterface(iface api.NetworkInterface) {
+	fmt.Printf("Name: %s\n", iface.Name)
+	fmt.Println("IP Addresses:")
+	if len(iface.IPAddresses) == 0 {
+		fmt.Println("\tnull")
+	} else {
+		for _, ip := range iface.IPAddresses {
+			fmt.Printf("\t%s\n", ip)
+		}
 	}
+	if iface.MACAddress == "" {
+		fmt.Println("MAC Address: not found")
+	} else {
+		fmt.Printf("MAC Address: %s\n", iface.MACAddress)
+	}
+	fmt.Printf("MTU: %d\n", iface.MTU)
+	fmt.Printf("Speed: %s\n", iface.Speed)
+	fmt.Printf("Duplex: %s\n", iface.Duplex)
+	fmt.Printf("Admin Status: %s\n", iface.AdminStatus)
+	fmt.Printf("Operational Status: %s\n", iface.OperationalStatus)
+	fmt.Println()
+}
 
-	// Print the network interfaces
-	fmt.Println("Network Interfaces:")
-	for _, iface := range body.Interfaces {
-		fmt.Printf("Name: %s\n", iface.Name)
-		fmt.Println("IP Addresses:")
-		if len(iface.IPAddresses) == 0 {
-			fmt.Println("\tnull")
-		} else {
-			for _, ip := range iface.IPAddresses {
-				fmt.Printf("\t%s\n", ip)
-			}
+// Watch consumes the server's event stream and prints every interface change as it happens.
+// If an interface name is configured, only the events of that interface are printed.
+// The stream is reconnected after the interval and resumes from the last event received.
+func (c *Client) Watch() {
+	for {
+		if err := c.watch(context.Background()); err != nil {
+			fmt.Println("Error:", err)
 		}
-		if iface.MACAddress == "" {
-			fmt.Println("MAC Address: not found")
-		} else {
-			fmt.Printf("MAC Address: %s\n", iface.MACAddress)
+		time.Sleep(c.interval)
+	}
+}
+
+// watch prints the events of the stream until the context is done.
+func (c *Client) watch(ctx context.Context) error {
+	events, err := c.api.Watch(ctx)
+	if err != nil {
+		return err
+	}
+
+	for event := range events {
+		if c.interfaceName == "" || event.Interface == c.interfaceName {
+			printEvent(event)
 		}
-		fmt.Printf("MTU: %d\n", iface.MTU)
-		fmt.Printf("Speed: %s\n", iface.Speed)
-		fmt.Printf("Duplex: %s\n", iface.Duplex)
-		fmt.Printf("Admin Status: %s\n", iface.AdminStatus)
-		fmt.Printf("Operational Status: %s\n", iface.OperationalStatus)
-		fmt.Println()
 	}
+
+	return errors.New("event stream closed")
 }
 
-func main() {
-	// Construct the server endpoint using environment variables
-	host := os.Getenv("HOST")
-	port := os.Getenv("PORT")
-	apiEndpoint := os.Getenv("API_ENDPOINT")
-	interfaceName := os.Getenv("INTERFACE")
+// printEvent prints a single interface change event.
+func printEvent(event api.Event) {
+	timestamp := event.Timestamp.Format(time.RFC3339)
+	switch event.Type {
+	case api.EventAddressAdded, api.EventAddressRemoved:
+		fmt.Printf("[%s] %s: %s %s\n", timestamp, event.Interface, event.Type, event.Address)
+	case api.EventMTUChanged:
+		fmt.Printf("[%s] %s: %s %d -> %d\n", timestamp, event.Interface, event.Type, event.OldMTU, event.NewMTU)
+	case api.EventDriftDetected:
+		fmt.Printf("[%s] %s: %s\n", timestamp, event.Interface, event.Type)
+		for _, change := range event.Changes {
+			fmt.Printf("    %s: %q -> %q\n", change.Field, change.Old, change.New)
+		}
+	default:
+		fmt.Printf("[%s] %s: %s\n", timestamp, event.Interface, event.Type)
+	}
+}
+
+// WriteTopology fetches the interface topology from the server and writes it as a Graphviz
+// document to the file at path, e.g. for rendering with `dot -Tsvg`.
+func (c *Client) WriteTopology(path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), c.interval)
+	defer cancel()
 
-	endPoint := host + port + apiEndpoint
-	if interfaceName != "" {
-		endPoint += "?interface=" + interfaceName
+	topology, err := c.api.Topology(ctx)
+	if err != nil {
+		return err
 	}
-	fmt.Println("Server endpoint:", endPoint)
+
+	return os.WriteFile(path, []byte(topology.DOT()), 0o644)
+}
+
+func main() {
+	// Construct the server URL using environment variables
+	config := NewConfig()
+	baseURL := config.Host + config.Port
+	interfaceName := os.Getenv("INTERFACE")
 
 	// Parse interval duration from environment variable or default to 5 seconds
 	intervalDuration, err := time.ParseDuration(os.Getenv("INTERVAL"))
@@ -106,8 +169,30 @@ func main() {
 		intervalDuration = 5 * time.Second
 	}
 
-	// Create a new HTTP client with the specified endpoint and interval
-	client := NewClient(endPoint, intervalDuration)
+	fmt.Println("Server URL:", baseURL)
+
+	// Create a new HTTP client with the specified server and interval
+	client := NewClient(baseURL, interfaceName, intervalDuration)
+
+	// The "watch" command consumes the event stream instead of polling
+	if len(os.Args) > 1 && os.Args[1] == "watch" {
+		client.Watch()
+		return
+	}
+
+	// The "topology" command writes the interface topology to a DOT file and exits
+	if len(os.Args) > 1 && os.Args[1] == "topology" {
+		path := "topology.dot"
+		if len(os.Args) > 2 {
+			path = os.Args[2]
+		}
+		if err := client.WriteTopology(path); err != nil {
+			fmt.Println("Error:", err)
+			os.Exit(1)
+		}
+		fmt.Println("Topology written to", path)
+		return
+	}
 
 	// Start the HTTP client
 	client.Start()
@@ -4,11 +4,10 @@ import (
 	"os"
 )
 
-// Config represents the configuration for the server.
+// Config represents the configuration for the client.
 type Config struct {
-	Port        string // Port to listen on for incoming HTTP requests.
-	Host        string // Host address for the server.
-	APIEndpoint string // API endpoint for the server.
+	Port string // Port the server listens on for incoming HTTP requests.
+	Host string // Host address for the server.
 }
 
 // NewConfig creates a new instance of Config and reads configuration from environment variables.
@@ -23,14 +22,8 @@ func NewConfig() *Config {
 		host = "http://http-server" // Default host if not provided
 	}
 
-	apiEndpoint := os.Getenv("API_ENDPOINT")
-	if apiEndpoint == "" {
-		apiEndpoint = "/network" // Default API endpoint if not provided
-	}
-
 	return &Config{
-		Port:        port,
-		Host:        host,
-		APIEndpoint: apiEndpoint,
+		Port: port,
+		Host: host,
 	}
 }
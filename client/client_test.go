@@ -1,9 +1,12 @@
 package main
 
 import (
+	"context"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 )
@@ -11,18 +14,24 @@ import (
 func TestClient_CallEndpoint(t *testing.T) {
 	// Set up a mock HTTP server
 	mockHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/network" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+
 		// Respond with a mock JSON response
 		mockResponse := `{
-			"network_interface": [
+			"network_interfaces": [
 				{
 					"name": "eth0",
-					"ip_addresses": ["192.168.1.10"],
 					"mac_address": "00:11:22:33:44:55",
 					"mtu": 1500,
-					"speed": "1 Gbps",
-					"duplex": "Full",
-					"admin_status": "enabled",
-					"operational_status": "UP"
+					"speed_mbps": 1000,
+					"duplex": "full",
+					"admin_status": "up",
+					"oper_status": "up",
+					"addresses": [
+						{"address": "192.168.1.10", "prefix_length": 24, "family": "ipv4", "scope": "global", "flags": []}
+					]
 				}
 			]
 		}`
@@ -36,10 +45,9 @@ func TestClient_CallEndpoint(t *testing.T) {
 	// Set up environment variables
 	os.Setenv("HOST", mockServer.URL)
 	os.Setenv("PORT", "")
-	os.Setenv("API_ENDPOINT", "/")
 
 	// Create a new client with a short interval for testing
-	client := NewClient(mockServer.URL, 100*time.Millisecond)
+	client := NewClient(mockServer.URL, "", 100*time.Millisecond)
 
 	// Call the endpoint
 	client.CallEndpoint()
@@ -47,3 +55,62 @@ func TestClient_CallEndpoint(t *testing.T) {
 	// Sleep for a short duration to allow the client to make another call
 	time.Sleep(200 * time.Millisecond)
 }
+
+func TestClient_Watch(t *testing.T) {
+	// Set up a mock event stream with two events and a comment
+	mockHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/network/events" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(": keep-alive\n\n" +
+			"id: 8\nevent: link_down\ndata: {\"id\":8,\"type\":\"link_down\",\"interface\":\"eth0\"}\n\n" +
+			"id: 9\nevent: link_up\ndata: {\"id\":9,\"type\":\"link_up\",\"interface\":\"eth0\"}\n\n"))
+	})
+	mockServer := httptest.NewServer(mockHandler)
+	defer mockServer.Close()
+
+	client := NewClient(mockServer.URL, "eth0", 100*time.Millisecond)
+
+	// The stream is consumed until the context is done
+	ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+	defer cancel()
+	if err := client.watch(ctx); err == nil {
+		t.Error("expected an error once the event stream is closed")
+	}
+}
+
+func TestClient_WriteTopology(t *testing.T) {
+	// Set up a mock server with a bond of two interfaces
+	mockHandler := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/v2/topology" {
+			t.Errorf("unexpected path: %s", r.URL.Path)
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{
+			"nodes": [
+				{"name": "eth0", "kind": "device", "oper_status": "up"},
+				{"name": "bond0", "kind": "bond", "oper_status": "up"}
+			],
+			"edges": [{"from": "eth0", "to": "bond0", "type": "master"}]
+		}`))
+	})
+	mockServer := httptest.NewServer(mockHandler)
+	defer mockServer.Close()
+
+	client := NewClient(mockServer.URL, "", time.Second)
+
+	path := filepath.Join(t.TempDir(), "topology.dot")
+	if err := client.WriteTopology(path); err != nil {
+		t.Fatal(err)
+	}
+
+	dot, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(dot), "\t\"eth0\" -> \"bond0\" [label=master];\n") {
+		t.Errorf("missing edge in output:\n%s", dot)
+	}
+}
@@ -1,3 +1,7 @@
 module clientmodule
 
 go 1.22.2
+
+require apimodule v0.0.0
+
+replace apimodule => ../api
@@ -0,0 +1,204 @@
+package codec
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+)
+
+// WriteMessagePack writes a tree returned by Tree in the MessagePack format.
+// Integers use the smallest representation that holds them, other numbers are 64-bit floats.
+func WriteMessagePack(w io.Writer, tree any) error {
+	buf, err := appendMessagePack(nil, tree)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(buf)
+	return err
+}
+
+// appendMessagePack appends the MessagePack encoding of a tree value to buf.
+func appendMessagePack(buf []byte, v any) ([]byte, error) {
+	var err error
+	switch v := v.(type) {
+	case nil:
+		return append(buf, 0xc0), nil
+	case bool:
+		if v {
+			return append(buf, 0xc3), nil
+		}
+		return append(buf, 0xc2), nil
+	case json.Number:
+		n, err := parseNumber(v)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case n.kind == 'u':
+			return binary.BigEndian.AppendUint64(append(buf, 0xcf), n.u), nil
+		case n.kind == 'f':
+			return binary.BigEndian.AppendUint64(append(buf, 0xcb), math.Float64bits(n.f)), nil
+		case n.i >= 0:
+			return appendMessagePackUint(buf, uint64(n.i)), nil
+		default:
+			return appendMessagePackInt(buf, n.i), nil
+		}
+	case string:
+		return append(appendMessagePackHeader(buf, len(v), 0xa0, 32, 0xd9, 0xda, 0xdb), v...), nil
+	case []any:
+		buf = appendMessagePackHeader(buf, len(v), 0x90, 16, 0, 0xdc, 0xdd)
+		for _, value := range v {
+			if buf, err = appendMessagePack(buf, value); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case Object:
+		buf = appendMessagePackHeader(buf, len(v), 0x80, 16, 0, 0xde, 0xdf)
+		for _, member := range v {
+			buf = append(appendMessagePackHeader(buf, len(member.Name), 0xa0, 32, 0xd9, 0xda, 0xdb), member.Name...)
+			if buf, err = appendMessagePack(buf, member.Value); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	default:
+		return nil, errUnsupportedValue
+	}
+}
+
+// appendMessagePackUint appends a non-negative integer in its smallest MessagePack representation.
+func appendMessagePackUint(buf []byte, u uint64) []byte {
+	switch {
+	case u < 0x80:
+		return append(buf, byte(u)) // Positive fixint
+	case u <= math.MaxUint8:
+		return append(buf, 0xcc, byte(u))
+	case u <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xcd), uint16(u))
+	case u <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xce), uint32(u))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, 0xcf), u)
+	}
+}
+
+// appendMessagePackInt appends a negative integer in its smallest MessagePack representation.
+func appendMessagePackInt(buf []byte, i int64) []byte {
+	switch {
+	case i >= -32:
+		return append(buf, byte(i)) // Negative fixint
+	case i >= math.MinInt8:
+		return append(buf, 0xd0, byte(i))
+	case i >= math.MinInt16:
+		return binary.BigEndian.AppendUint16(append(buf, 0xd1), uint16(i))
+	case i >= math.MinInt32:
+		return binary.BigEndian.AppendUint32(append(buf, 0xd2), uint32(i))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, 0xd3), uint64(i))
+	}
+}
+
+// appendMessagePackHeader appends the header of a string, array or map of length n. Lengths below
+// fixMax are encoded in the fix type, the others with the 8-bit (if any), 16-bit or 32-bit type.
+func appendMessagePackHeader(buf []byte, n int, fix byte, fixMax int, type8, type16, type32 byte) []byte {
+	switch {
+	case n < fixMax:
+		return append(buf, fix|byte(n))
+	case type8 != 0 && n <= math.MaxUint8:
+		return append(buf, type8, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, type16), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(buf, type32), uint32(n))
+	}
+}
+
+// Major types of CBOR (RFC 8949).
+const (
+	cborUint   = 0 << 5
+	cborNegint = 1 << 5
+	cborText   = 3 << 5
+	cborArray  = 4 << 5
+	cborMap    = 5 << 5
+	cborSimple = 7 << 5
+)
+
+// WriteCBOR writes a tree returned by Tree in the CBOR format (RFC 8949), with definite lengths.
+// Integers use the smallest representation that holds them, other numbers are 64-bit floats.
+func WriteCBOR(w io.Writer, tree any) error {
+	buf, err := appendCBOR(nil, tree)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(buf)
+	return err
+}
+
+// appendCBOR appends the CBOR encoding of a tree value to buf.
+func appendCBOR(buf []byte, v any) ([]byte, error) {
+	var err error
+	switch v := v.(type) {
+	case nil:
+		return append(buf, cborSimple|22), nil
+	case bool:
+		if v {
+			return append(buf, cborSimple|21), nil
+		}
+		return append(buf, cborSimple|20), nil
+	case json.Number:
+		n, err := parseNumber(v)
+		if err != nil {
+			return nil, err
+		}
+		switch {
+		case n.kind == 'u':
+			return appendCBORHeader(buf, cborUint, n.u), nil
+		case n.kind == 'f':
+			return binary.BigEndian.AppendUint64(append(buf, cborSimple|27), math.Float64bits(n.f)), nil
+		case n.i >= 0:
+			return appendCBORHeader(buf, cborUint, uint64(n.i)), nil
+		default:
+			return appendCBORHeader(buf, cborNegint, uint64(-(n.i + 1))), nil
+		}
+	case string:
+		return append(appendCBORHeader(buf, cborText, uint64(len(v))), v...), nil
+	case []any:
+		buf = appendCBORHeader(buf, cborArray, uint64(len(v)))
+		for _, value := range v {
+			if buf, err = appendCBOR(buf, value); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	case Object:
+		buf = appendCBORHeader(buf, cborMap, uint64(len(v)))
+		for _, member := range v {
+			buf = append(appendCBORHeader(buf, cborText, uint64(len(member.Name))), member.Name...)
+			if buf, err = appendCBOR(buf, member.Value); err != nil {
+				return nil, err
+			}
+		}
+		return buf, nil
+	default:
+		return nil, errUnsupportedValue
+	}
+}
+
+// appendCBORHeader appends the initial byte of a major type with its argument, which is
+// embedded in the initial byte if it's below 24 and follows in 1, 2, 4 or 8 bytes otherwise.
+func appendCBORHeader(buf []byte, major byte, arg uint64) []byte {
+	switch {
+	case arg < 24:
+		return append(buf, major|byte(arg))
+	case arg <= math.MaxUint8:
+		return append(buf, major|24, byte(arg))
+	case arg <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(buf, major|25), uint16(arg))
+	case arg <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(buf, major|26), uint32(arg))
+	default:
+		return binary.BigEndian.AppendUint64(append(buf, major|27), arg)
+	}
+}
@@ -0,0 +1,126 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Member is a named value of an Object.
+type Member struct {
+	Name  string
+	Value any
+}
+
+// Object is a JSON object with its members in the order they were encoded in.
+type Object []Member
+
+// MarshalJSON encodes the object as a JSON object with its members in order.
+func (o Object) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, member := range o {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(member.Name)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(member.Value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// Tree returns the JSON encoding of v as a tree of nil, bool, json.Number, string, []any and
+// Object values, which keeps the field names, the field order and the omitted fields of the
+// JSON encoding for the other formats.
+func Tree(v any) (any, error) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.UseNumber()
+	return decodeValue(d)
+}
+
+// decodeValue decodes the next value from the token stream of d.
+func decodeValue(d *json.Decoder) (any, error) {
+	token, err := d.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch token {
+	case json.Delim('{'):
+		object := Object{}
+		for d.More() {
+			name, err := d.Token()
+			if err != nil {
+				return nil, err
+			}
+			value, err := decodeValue(d)
+			if err != nil {
+				return nil, err
+			}
+			object = append(object, Member{Name: name.(string), Value: value})
+		}
+		_, err := d.Token() // Closing brace
+		return object, err
+	case json.Delim('['):
+		list := []any{}
+		for d.More() {
+			value, err := decodeValue(d)
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, value)
+		}
+		_, err := d.Token() // Closing bracket
+		return list, err
+	default:
+		return token, nil
+	}
+}
+
+// number is a JSON number converted to the narrowest Go type that holds it.
+// Exactly one of the fields is used, depending on the kind.
+type number struct {
+	kind byte // 'i' for int64, 'u' for uint64 beyond int64 and 'f' for float64.
+	i    int64
+	u    uint64
+	f    float64
+}
+
+// parseNumber converts a JSON number to an integer if it is one, or to a float otherwise.
+func parseNumber(n json.Number) (number, error) {
+	s := string(n)
+	if !strings.ContainsAny(s, ".eE") {
+		if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+			return number{kind: 'i', i: i}, nil
+		}
+		if u, err := strconv.ParseUint(s, 10, 64); err == nil {
+			return number{kind: 'u', u: u}, nil
+		}
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return number{}, fmt.Errorf("invalid number %s", s)
+	}
+	return number{kind: 'f', f: f}, nil
+}
+
+// errUnsupportedValue is returned for values that aren't part of a tree returned by Tree.
+var errUnsupportedValue = errors.New("unsupported value in tree")
@@ -0,0 +1,195 @@
+package codec
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// testInterfaces is a response with lists, nested objects, omitted fields and numbers of every kind.
+var testInterfaces = struct {
+	Interfaces []testInterface `json:"network_interfaces"`
+}{
+	Interfaces: []testInterface{
+		{
+			Name:      "eth0",
+			MTU:       1500,
+			Slaves:    []string{"eth1", "eth2"},
+			Addresses: []testAddress{{Address: "192.0.2.10", PrefixLength: 24}, {Address: "2001:db8::10", PrefixLength: 64}},
+			Stats:     &testStats{RxBytes: 1 << 40, Rate: 0.5},
+		},
+		{Name: "lo", Alias: "true", MTU: 65536, Addresses: []testAddress{}},
+	},
+}
+
+type testInterface struct {
+	Name      string        `json:"name"`
+	Alias     string        `json:"alias,omitempty"`
+	MTU       int           `json:"mtu"`
+	Slaves    []string      `json:"slaves"`
+	Addresses []testAddress `json:"addresses"`
+	Stats     *testStats    `json:"stats,omitempty"`
+}
+
+type testAddress struct {
+	Address      string `json:"address"`
+	PrefixLength int    `json:"prefix_length"`
+}
+
+type testStats struct {
+	RxBytes uint64  `json:"rx_bytes"`
+	Rate    float64 `json:"rate"`
+}
+
+// TestWriteYAML tests that YAML keeps the field order and omitted fields of JSON and quotes
+// strings that would be read as another type.
+func TestWriteYAML(t *testing.T) {
+	tree, err := Tree(testInterfaces)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var buf bytes.Buffer
+	if err := WriteYAML(&buf, tree); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `network_interfaces:
+  - name: eth0
+    mtu: 1500
+    slaves:
+      - eth1
+      - eth2
+    addresses:
+      - address: 192.0.2.10
+        prefix_length: 24
+      - address: 2001:db8::10
+        prefix_length: 64
+    stats:
+      rx_bytes: 1099511627776
+      rate: 0.5
+  - name: lo
+    alias: "true"
+    mtu: 65536
+    slaves: null
+    addresses: []
+`
+	if buf.String() != expected {
+		t.Errorf("YAML mismatch:\ngot:\n%s\nwant:\n%s", buf.String(), expected)
+	}
+}
+
+// TestWriteTable tests that rows are expanded by the given lists and nested objects are flattened.
+func TestWriteTable(t *testing.T) {
+	tree, err := Tree(testInterfaces)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		tree     any
+		comma    rune
+		expand   []string
+		expected string
+	}{
+		{
+			name:   "CSV",
+			tree:   tree,
+			comma:  ',',
+			expand: []string{"addresses"},
+			expected: "name,mtu,slaves,addresses.address,addresses.prefix_length,stats.rx_bytes,stats.rate,alias\n" +
+				"eth0,1500,eth1 eth2,192.0.2.10,24,1099511627776,0.5,\n" +
+				"eth0,1500,eth1 eth2,2001:db8::10,64,1099511627776,0.5,\n" +
+				"lo,65536,,,,,,true\n",
+		},
+		{
+			name:  "TSV",
+			tree:  tree,
+			comma: '\t',
+			expected: "name\tmtu\tslaves\taddresses\tstats.rx_bytes\tstats.rate\talias\n" +
+				"eth0\t1500\teth1 eth2\t\"[{\"\"address\"\":\"\"192.0.2.10\"\",\"\"prefix_length\"\":24},{\"\"address\"\":\"\"2001:db8::10\"\",\"\"prefix_length\"\":64}]\"\t1099511627776\t0.5\t\n" +
+				"lo\t65536\t\t\t\t\ttrue\n",
+		},
+		{
+			name:     "Object",
+			tree:     Object{{Name: "error", Value: "interface not found"}},
+			comma:    ',',
+			expected: "error\ninterface not found\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := WriteTable(&buf, test.tree, test.comma, test.expand...); err != nil {
+				t.Fatal(err)
+			}
+			if buf.String() != test.expected {
+				t.Errorf("table mismatch:\ngot:\n%s\nwant:\n%s", buf.String(), test.expected)
+			}
+		})
+	}
+}
+
+// TestWriteBinary tests the MessagePack and CBOR encodings against the examples of their specifications.
+func TestWriteBinary(t *testing.T) {
+	tree, err := Tree(struct {
+		Name     string   `json:"name"`
+		Up       bool     `json:"up"`
+		Alias    *string  `json:"alias"`
+		Small    int      `json:"small"`
+		Negative int      `json:"negative"`
+		Large    uint64   `json:"large"`
+		Rate     float64  `json:"rate"`
+		Flags    []string `json:"flags"`
+	}{Name: "eth0", Up: true, Small: 1000, Negative: -100, Large: 1 << 63, Rate: 1.5, Flags: []string{"a"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name     string
+		write    func(*bytes.Buffer, any) error
+		expected string
+	}{
+		{
+			name:  "MessagePack",
+			write: func(buf *bytes.Buffer, tree any) error { return WriteMessagePack(buf, tree) },
+			expected: "88" +
+				"a46e616d65" + "a465746830" + // "name": "eth0"
+				"a27570" + "c3" + // "up": true
+				"a5616c696173" + "c0" + // "alias": null
+				"a5736d616c6c" + "cd03e8" + // "small": 1000
+				"a86e65676174697665" + "d09c" + // "negative": -100
+				"a56c61726765" + "cf8000000000000000" + // "large": 2^63
+				"a472617465" + "cb3ff8000000000000" + // "rate": 1.5
+				"a5666c616773" + "91a161", // "flags": ["a"]
+		},
+		{
+			name:  "CBOR",
+			write: func(buf *bytes.Buffer, tree any) error { return WriteCBOR(buf, tree) },
+			expected: "a8" +
+				"646e616d65" + "6465746830" + // "name": "eth0"
+				"627570" + "f5" + // "up": true
+				"65616c696173" + "f6" + // "alias": null
+				"65736d616c6c" + "1903e8" + // "small": 1000
+				"686e65676174697665" + "3863" + // "negative": -100
+				"656c61726765" + "1b8000000000000000" + // "large": 2^63
+				"6472617465" + "fb3ff8000000000000" + // "rate": 1.5
+				"65666c616773" + "816161", // "flags": ["a"]
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := test.write(&buf, tree); err != nil {
+				t.Fatal(err)
+			}
+			if got := hex.EncodeToString(buf.Bytes()); got != test.expected {
+				t.Errorf("encoding mismatch:\ngot  %s\nwant %s", got, test.expected)
+			}
+		})
+	}
+}
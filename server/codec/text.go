@@ -0,0 +1,274 @@
+package codec
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// WriteYAML writes a tree returned by Tree as a YAML document in block style.
+func WriteYAML(w io.Writer, tree any) error {
+	node, err := yamlNode(tree)
+	if err != nil {
+		return err
+	}
+
+	e := yaml.NewEncoder(w)
+	e.SetIndent(2)
+	if err := e.Encode(node); err != nil {
+		return err
+	}
+	return e.Close()
+}
+
+// yamlNode converts a tree value to a YAML node. Strings are tagged explicitly, so that the
+// encoder quotes the ones that would otherwise be read back as another type, e.g. "true".
+func yamlNode(v any) (*yaml.Node, error) {
+	switch v := v.(type) {
+	case nil:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"}, nil
+	case bool:
+		value := "false"
+		if v {
+			value = "true"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: value}, nil
+	case json.Number:
+		n, err := parseNumber(v)
+		if err != nil {
+			return nil, err
+		}
+		tag := "!!int"
+		if n.kind == 'f' {
+			tag = "!!float"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String()}, nil
+	case string:
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: v}, nil
+	case []any:
+		node := &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		for _, value := range v {
+			child, err := yamlNode(value)
+			if err != nil {
+				return nil, err
+			}
+			node.Content = append(node.Content, child)
+		}
+		return node, nil
+	case Object:
+		node := &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		for _, member := range v {
+			child, err := yamlNode(member.Value)
+			if err != nil {
+				return nil, err
+			}
+			key := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: member.Name}
+			node.Content = append(node.Content, key, child)
+		}
+		return node, nil
+	default:
+		return nil, errUnsupportedValue
+	}
+}
+
+// WriteTable writes a tree returned by Tree as a table of delimited values with a header row,
+// e.g. CSV with comma ',' or TSV with comma '\t'.
+//
+// The rows are the objects of the list that is the only member of the tree, e.g. the interfaces
+// of {"network_interfaces": [...]}, or the tree itself otherwise. Nested objects are flattened into
+// columns named by their path, e.g. stats.rx_bytes. A member of a row named in expand, e.g. the
+// addresses of an interface, turns the row into one row per element of its list, or a single row
+// with empty columns if the list is empty. Other lists of scalars are joined by spaces, and any
+// other lists are written as JSON. Columns are ordered by their first appearance.
+func WriteTable(w io.Writer, tree any, comma rune, expand ...string) error {
+	t := &table{expand: make(map[string]bool), index: make(map[string]int)}
+	for _, name := range expand {
+		t.expand[name] = true
+	}
+
+	for _, row := range tableRows(tree) {
+		expanded, err := t.flatten("", row)
+		if err != nil {
+			return err
+		}
+		for _, cells := range expanded {
+			t.add(cells)
+		}
+	}
+
+	cw := csv.NewWriter(w)
+	cw.Comma = comma
+	if err := cw.Write(t.columns); err != nil {
+		return err
+	}
+	for _, row := range t.rows {
+		record := make([]string, len(t.columns))
+		for _, c := range row {
+			record[t.index[c.column]] = c.value
+		}
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// tableRows returns the values that become the rows of a table.
+func tableRows(tree any) []any {
+	if object, ok := tree.(Object); ok && len(object) == 1 {
+		if list, ok := object[0].Value.([]any); ok {
+			return list
+		}
+	}
+	if list, ok := tree.([]any); ok {
+		return list
+	}
+	return []any{tree}
+}
+
+// table collects the rows of a table and the union of their columns.
+type table struct {
+	expand  map[string]bool // Names of the members that are expanded into rows.
+	columns []string        // Columns in the order of their first appearance.
+	index   map[string]int  // Index of every column.
+	rows    [][]cell
+}
+
+// cell is a value of a table row.
+type cell struct {
+	column string
+	value  string
+}
+
+// add adds a row and the columns that weren't seen before.
+func (t *table) add(cells []cell) {
+	for _, c := range cells {
+		if _, ok := t.index[c.column]; !ok {
+			t.index[c.column] = len(t.columns)
+			t.columns = append(t.columns, c.column)
+		}
+	}
+	t.rows = append(t.rows, cells)
+}
+
+// flatten turns a value into the cells of one or more rows, naming the columns below the prefix.
+func (t *table) flatten(prefix string, v any) ([][]cell, error) {
+	object, ok := v.(Object)
+	if !ok {
+		value, err := cellValue(v)
+		if err != nil {
+			return nil, err
+		}
+		return [][]cell{{{column: columnName(prefix, "value"), value: value}}}, nil
+	}
+
+	rows := [][]cell{nil}
+	for _, member := range object {
+		column := columnName(prefix, member.Name)
+
+		var memberRows [][]cell
+		var err error
+		list, isList := member.Value.([]any)
+		switch value := member.Value.(type) {
+		case Object:
+			memberRows, err = t.flatten(column, value)
+		default:
+			if isList && t.expand[member.Name] {
+				memberRows, err = t.expandList(column, list)
+				break
+			}
+			var text string
+			text, err = cellValue(value)
+			memberRows = [][]cell{{{column: column, value: text}}}
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		// Every row so far is combined with every row of the member
+		combined := make([][]cell, 0, len(rows)*len(memberRows))
+		for _, row := range rows {
+			for _, memberRow := range memberRows {
+				combined = append(combined, append(append([]cell(nil), row...), memberRow...))
+			}
+		}
+		rows = combined
+	}
+	return rows, nil
+}
+
+// expandList turns the elements of a list into one row each, or a list without elements
+// into a single row without cells.
+func (t *table) expandList(column string, list []any) ([][]cell, error) {
+	if len(list) == 0 {
+		return [][]cell{nil}, nil
+	}
+
+	var rows [][]cell
+	for _, element := range list {
+		if _, ok := element.(Object); !ok {
+			value, err := cellValue(element)
+			if err != nil {
+				return nil, err
+			}
+			rows = append(rows, []cell{{column: column, value: value}})
+			continue
+		}
+		elementRows, err := t.flatten(column, element)
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, elementRows...)
+	}
+	return rows, nil
+}
+
+// columnName returns the name of a column below the prefix.
+func columnName(prefix, name string) string {
+	if prefix == "" {
+		return name
+	}
+	return prefix + "." + name
+}
+
+// cellValue formats a value as the text of a cell. Null is empty, lists of scalars are joined
+// by spaces and any other lists and objects are written as JSON.
+func cellValue(v any) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return "", nil
+	case bool:
+		if v {
+			return "true", nil
+		}
+		return "false", nil
+	case json.Number:
+		return v.String(), nil
+	case string:
+		return v, nil
+	case []any:
+		values := make([]string, 0, len(v))
+		for _, element := range v {
+			switch element.(type) {
+			case []any, Object:
+				b, err := json.Marshal(v)
+				return string(b), err
+			}
+			value, err := cellValue(element)
+			if err != nil {
+				return "", err
+			}
+			values = append(values, value)
+		}
+		return strings.Join(values, " "), nil
+	case Object:
+		b, err := json.Marshal(v)
+		return string(b), err
+	default:
+		return "", errUnsupportedValue
+	}
+}
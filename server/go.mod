@@ -1,3 +1,20 @@
 module servermodule
 
 go 1.22.2
+
+require (
+	apimodule v0.0.0
+	github.com/gorilla/websocket v1.5.3
+	golang.org/x/sys v0.28.0
+	google.golang.org/grpc v1.70.0
+	google.golang.org/protobuf v1.36.6
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	golang.org/x/net v0.32.0 // indirect
+	golang.org/x/text v0.21.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20241202173237-19429a94021a // indirect
+)
+
+replace apimodule => ../api
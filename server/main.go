@@ -7,5 +7,7 @@ import (
 
 func main() {
 	// Start the server with the provided configuration
-	log.Fatal(server.Start())
+	if err := server.Start(); err != nil {
+		log.Fatal(err)
+	}
 }
@@ -0,0 +1,291 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// ContentType is the content type of the Prometheus text exposition format.
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+// DefaultBuckets are the upper bounds, in seconds, of the latency histogram buckets.
+var DefaultBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Labels are the label names and values of a single sample.
+type Labels map[string]string
+
+// Writer writes metric families in the Prometheus text exposition format.
+// Every family is announced with Family before its samples are written with Sample.
+type Writer struct {
+	w   io.Writer
+	err error // First write error, all later writes are skipped.
+}
+
+// NewWriter creates a new Writer writing to w.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w}
+}
+
+// Family writes the HELP and TYPE lines of a metric family.
+// The type is one of "counter", "gauge" or "histogram".
+func (w *Writer) Family(name, help, typ string) {
+	w.printf("# HELP %s %s\n# TYPE %s %s\n", name, escapeHelp(help), name, typ)
+}
+
+// Sample writes a single sample of a metric.
+func (w *Writer) Sample(name string, labels Labels, value float64) {
+	w.printf("%s%s %s\n", name, formatLabels(labels), formatValue(value))
+}
+
+// Err returns the first error that occurred while writing.
+func (w *Writer) Err() error {
+	return w.err
+}
+
+// printf writes formatted output unless an earlier write failed.
+func (w *Writer) printf(format string, args ...interface{}) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = fmt.Fprintf(w.w, format, args...)
+}
+
+// formatLabels formats labels as {name="value",...} with the names in sorted order.
+func formatLabels(labels Labels) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(name)
+		b.WriteString(`="`)
+		b.WriteString(escapeLabel(labels[name]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+
+	return b.String()
+}
+
+// formatValue formats a sample value, including the special values +Inf, -Inf and NaN.
+func formatValue(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	case v == math.Trunc(v) && math.Abs(v) < 1e15:
+		// Print counters and other integral values without an exponent
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+// escapeLabel escapes backslashes, double quotes and line feeds in label values.
+func escapeLabel(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// escapeHelp escapes backslashes and line feeds in help texts.
+func escapeHelp(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "\n", `\n`).Replace(s)
+}
+
+// histogram is a cumulative histogram of observed durations.
+type histogram struct {
+	buckets []float64 // Upper bounds of the buckets in seconds.
+	counts  []uint64  // Number of observations per bucket, not cumulative.
+	count   uint64    // Total number of observations.
+	sum     float64   // Sum of all observations in seconds.
+}
+
+// newHistogram creates a new empty histogram with the given bucket upper bounds.
+func newHistogram(buckets []float64) *histogram {
+	return &histogram{
+		buckets: buckets,
+		counts:  make([]uint64, len(buckets)),
+	}
+}
+
+// observe records a single duration.
+func (h *histogram) observe(d time.Duration) {
+	v := d.Seconds()
+	h.count++
+	h.sum += v
+	for i, bound := range h.buckets {
+		if v <= bound {
+			h.counts[i]++
+			return
+		}
+	}
+}
+
+// write writes the bucket, sum and count samples of the histogram.
+func (h *histogram) write(w *Writer, name string, labels Labels) {
+	var cumulative uint64
+	for i, bound := range h.buckets {
+		cumulative += h.counts[i]
+		w.Sample(name+"_bucket", withLabel(labels, "le", formatValue(bound)), float64(cumulative))
+	}
+	w.Sample(name+"_bucket", withLabel(labels, "le", "+Inf"), float64(h.count))
+	w.Sample(name+"_sum", labels, h.sum)
+	w.Sample(name+"_count", labels, float64(h.count))
+}
+
+// withLabel returns a copy of the labels with one additional label.
+func withLabel(labels Labels, name, value string) Labels {
+	result := make(Labels, len(labels)+1)
+	for k, v := range labels {
+		result[k] = v
+	}
+	result[name] = value
+	return result
+}
+
+// requestKey identifies the request counter of a route and status code.
+type requestKey struct {
+	method string
+	route  string
+	code   int
+}
+
+// routeKey identifies the latency histogram of a route.
+type routeKey struct {
+	method string
+	route  string
+}
+
+// Registry collects the self-metrics of the server: HTTP requests per route and
+// the duration of the collector calls.
+type Registry struct {
+	mu               sync.Mutex
+	requests         map[requestKey]uint64
+	latencies        map[routeKey]*histogram
+	collections      map[string]*histogram // Collector call durations by call.
+	collectionErrors uint64
+}
+
+// NewRegistry creates a new empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		requests:    make(map[requestKey]uint64),
+		latencies:   make(map[routeKey]*histogram),
+		collections: make(map[string]*histogram),
+	}
+}
+
+// ObserveRequest records a handled HTTP request. The pattern is the route pattern
+// the request matched, e.g. "GET /network/{name}/stats", or empty if none matched.
+func (r *Registry) ObserveRequest(pattern string, code int, d time.Duration) {
+	method, route := "", "unmatched"
+	if pattern != "" {
+		method, route, _ = strings.Cut(pattern, " ")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.requests[requestKey{method: method, route: route, code: code}]++
+
+	key := routeKey{method: method, route: route}
+	h, ok := r.latencies[key]
+	if !ok {
+		h = newHistogram(DefaultBuckets)
+		r.latencies[key] = h
+	}
+	h.observe(d)
+}
+
+// ObserveCollection records a call to the collector, such as "interfaces" or "interface".
+func (r *Registry) ObserveCollection(call string, d time.Duration, err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	h, ok := r.collections[call]
+	if !ok {
+		h = newHistogram(DefaultBuckets)
+		r.collections[call] = h
+	}
+	h.observe(d)
+
+	if err != nil {
+		r.collectionErrors++
+	}
+}
+
+// Write writes all self-metrics of the registry.
+func (r *Registry) Write(w *Writer) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Requests, sorted for a stable output
+	requestKeys := make([]requestKey, 0, len(r.requests))
+	for key := range r.requests {
+		requestKeys = append(requestKeys, key)
+	}
+	sort.Slice(requestKeys, func(i, j int) bool {
+		a, b := requestKeys[i], requestKeys[j]
+		if a.route != b.route {
+			return a.route < b.route
+		}
+		if a.method != b.method {
+			return a.method < b.method
+		}
+		return a.code < b.code
+	})
+
+	w.Family("interfacer_http_requests_total", "Total number of HTTP requests by route and status code.", "counter")
+	for _, key := range requestKeys {
+		w.Sample("interfacer_http_requests_total", Labels{"method": key.method, "route": key.route, "code": strconv.Itoa(key.code)}, float64(r.requests[key]))
+	}
+
+	routeKeys := make([]routeKey, 0, len(r.latencies))
+	for key := range r.latencies {
+		routeKeys = append(routeKeys, key)
+	}
+	sort.Slice(routeKeys, func(i, j int) bool {
+		if routeKeys[i].route != routeKeys[j].route {
+			return routeKeys[i].route < routeKeys[j].route
+		}
+		return routeKeys[i].method < routeKeys[j].method
+	})
+
+	w.Family("interfacer_http_request_duration_seconds", "Duration of HTTP requests by route.", "histogram")
+	for _, key := range routeKeys {
+		r.latencies[key].write(w, "interfacer_http_request_duration_seconds", Labels{"method": key.method, "route": key.route})
+	}
+
+	// Collector calls
+	calls := make([]string, 0, len(r.collections))
+	for call := range r.collections {
+		calls = append(calls, call)
+	}
+	sort.Strings(calls)
+
+	w.Family("interfacer_collector_duration_seconds", "Duration of collector calls.", "histogram")
+	for _, call := range calls {
+		r.collections[call].write(w, "interfacer_collector_duration_seconds", Labels{"call": call})
+	}
+
+	w.Family("interfacer_collector_errors_total", "Total number of failed collector calls.", "counter")
+	w.Sample("interfacer_collector_errors_total", nil, float64(r.collectionErrors))
+}
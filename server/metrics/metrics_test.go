@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWriter tests the text exposition format written by the Writer.
+func TestWriter(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewWriter(&buf)
+
+	w.Family("test_up", "Whether the \"test\" is up.", "gauge")
+	w.Sample("test_up", Labels{"name": "eth0", "alias": "uplink \"A\""}, 1)
+	w.Sample("test_up", nil, 0.5)
+
+	expected := "# HELP test_up Whether the \"test\" is up.\n" +
+		"# TYPE test_up gauge\n" +
+		"test_up{alias=\"uplink \\\"A\\\"\",name=\"eth0\"} 1\n" +
+		"test_up 0.5\n"
+
+	if err := w.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != expected {
+		t.Errorf("output mismatch:\ngot:\n%s\nwant:\n%s", buf.String(), expected)
+	}
+}
+
+// TestRegistry tests that requests and collector calls are recorded per route and call.
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	r.ObserveRequest("GET /network", 200, 20*time.Millisecond)
+	r.ObserveRequest("GET /network", 200, 2*time.Second)
+	r.ObserveRequest("GET /network", 404, time.Millisecond)
+	r.ObserveRequest("", 404, time.Millisecond)
+	r.ObserveCollection("interfaces", 30*time.Millisecond, nil)
+	r.ObserveCollection("interface", time.Millisecond, errors.New("failed"))
+
+	var buf bytes.Buffer
+	r.Write(NewWriter(&buf))
+	output := buf.String()
+
+	expectedLines := []string{
+		`interfacer_http_requests_total{code="200",method="GET",route="/network"} 2`,
+		`interfacer_http_requests_total{code="404",method="GET",route="/network"} 1`,
+		`interfacer_http_requests_total{code="404",method="",route="unmatched"} 1`,
+		`interfacer_http_request_duration_seconds_bucket{le="0.025",method="GET",route="/network"} 2`,
+		`interfacer_http_request_duration_seconds_bucket{le="+Inf",method="GET",route="/network"} 3`,
+		`interfacer_http_request_duration_seconds_count{method="GET",route="/network"} 3`,
+		`interfacer_collector_duration_seconds_count{call="interfaces"} 1`,
+		`interfacer_collector_errors_total 1`,
+	}
+
+	for _, line := range expectedLines {
+		if !strings.Contains(output, line+"\n") {
+			t.Errorf("missing line %q in output:\n%s", line, output)
+		}
+	}
+}
@@ -1,12 +1,23 @@
 package router
 
 import (
+	"bufio"
+	"errors"
+	"net"
 	"net/http"
+	"slices"
+	"time"
 )
 
+// ObserverFunc is called after every request with the route pattern the request matched
+// (empty if none matched), the response status code and the time it took to handle the request.
+type ObserverFunc func(pattern string, code int, duration time.Duration)
+
 // Router is a simple HTTP router that wraps around http.ServeMux.
 type Router struct {
-	mux *http.ServeMux
+	mux      *http.ServeMux
+	observer ObserverFunc
+	routes   []string
 }
 
 // New creates a new instance of Router.
@@ -19,6 +30,13 @@ func New() *Router {
 // Handle registers a handler for the given pattern.
 func (r *Router) Handle(pattern string, handler http.Handler) {
 	r.mux.Handle(pattern, handler)
+	r.routes = append(r.routes, pattern)
+}
+
+// Routes returns the patterns of the registered handlers (e.g. "GET /v1/network"), in the
+// order they were registered.
+func (r *Router) Routes() []string {
+	return slices.Clone(r.routes)
 }
 
 // GET registers a handler for the HTTP GET method and the given pattern.
@@ -26,7 +44,146 @@ func (r *Router) GET(pattern string, fn http.HandlerFunc) {
 	r.Handle(http.MethodGet+" "+pattern, fn)
 }
 
+// POST registers a handler for the HTTP POST method and the given pattern.
+func (r *Router) POST(pattern string, fn http.HandlerFunc) {
+	r.Handle(http.MethodPost+" "+pattern, fn)
+}
+
+// PUT registers a handler for the HTTP PUT method and the given pattern.
+func (r *Router) PUT(pattern string, fn http.HandlerFunc) {
+	r.Handle(http.MethodPut+" "+pattern, fn)
+}
+
+// PATCH registers a handler for the HTTP PATCH method and the given pattern.
+func (r *Router) PATCH(pattern string, fn http.HandlerFunc) {
+	r.Handle(http.MethodPatch+" "+pattern, fn)
+}
+
+// DELETE registers a handler for the HTTP DELETE method and the given pattern.
+func (r *Router) DELETE(pattern string, fn http.HandlerFunc) {
+	r.Handle(http.MethodDelete+" "+pattern, fn)
+}
+
+// Middleware wraps a handler, e.g. to add common response headers.
+type Middleware func(http.Handler) http.Handler
+
+// Group registers handlers below a common path prefix, wrapped in common middleware.
+type Group struct {
+	router     *Router
+	prefix     string
+	middleware []Middleware
+}
+
+// Group creates a new Group registering its handlers below the given prefix (e.g. "/v1").
+// The middleware is applied in order, the first one being the outermost.
+func (r *Router) Group(prefix string, middleware ...Middleware) *Group {
+	return &Group{
+		router:     r,
+		prefix:     prefix,
+		middleware: middleware,
+	}
+}
+
+// Handle registers a handler for the given path below the prefix of the group.
+func (g *Group) Handle(path string, handler http.Handler) {
+	g.handle("", path, handler)
+}
+
+// GET registers a handler for the HTTP GET method and the given path below the prefix of the group.
+func (g *Group) GET(path string, fn http.HandlerFunc) {
+	g.handle(http.MethodGet+" ", path, fn)
+}
+
+// POST registers a handler for the HTTP POST method and the given path below the prefix of the group.
+func (g *Group) POST(path string, fn http.HandlerFunc) {
+	g.handle(http.MethodPost+" ", path, fn)
+}
+
+// PUT registers a handler for the HTTP PUT method and the given path below the prefix of the group.
+func (g *Group) PUT(path string, fn http.HandlerFunc) {
+	g.handle(http.MethodPut+" ", path, fn)
+}
+
+// PATCH registers a handler for the HTTP PATCH method and the given path below the prefix of the group.
+func (g *Group) PATCH(path string, fn http.HandlerFunc) {
+	g.handle(http.MethodPatch+" ", path, fn)
+}
+
+// DELETE registers a handler for the HTTP DELETE method and the given path below the prefix of the group.
+func (g *Group) DELETE(path string, fn http.HandlerFunc) {
+	g.handle(http.MethodDelete+" ", path, fn)
+}
+
+// handle wraps the handler in the middleware of the group and registers it.
+func (g *Group) handle(method, path string, handler http.Handler) {
+	for i := len(g.middleware) - 1; i >= 0; i-- {
+		handler = g.middleware[i](handler)
+	}
+	g.router.Handle(method+g.prefix+path, handler)
+}
+
+// Observe sets a function that is notified about every handled request, e.g. to record metrics.
+func (r *Router) Observe(fn ObserverFunc) {
+	r.observer = fn
+}
+
 // ServeHTTP dispatches the request to the handler registered to handle it.
 func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
-	r.mux.ServeHTTP(w, req)
+	if r.observer == nil {
+		r.mux.ServeHTTP(w, req)
+		return
+	}
+
+	start := time.Now()
+	_, pattern := r.mux.Handler(req)
+	rec := &statusRecorder{ResponseWriter: w, code: http.StatusOK}
+
+	r.mux.ServeHTTP(rec, req)
+
+	r.observer(pattern, rec.code, time.Since(start))
+}
+
+// statusRecorder is an http.ResponseWriter that remembers the status code of the response.
+// It passes flushing and hijacking through, as needed for event streams and WebSockets.
+type statusRecorder struct {
+	http.ResponseWriter
+	code        int
+	wroteHeader bool
+}
+
+// WriteHeader records the status code and writes it to the underlying ResponseWriter.
+func (rec *statusRecorder) WriteHeader(code int) {
+	if !rec.wroteHeader {
+		rec.code = code
+		rec.wroteHeader = true
+	}
+	rec.ResponseWriter.WriteHeader(code)
+}
+
+// Write writes the body to the underlying ResponseWriter, implying a 200 status code.
+func (rec *statusRecorder) Write(b []byte) (int, error) {
+	rec.wroteHeader = true
+	return rec.ResponseWriter.Write(b)
+}
+
+// Flush flushes the underlying ResponseWriter if it supports flushing.
+func (rec *statusRecorder) Flush() {
+	if flusher, ok := rec.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack takes over the connection of the underlying ResponseWriter if it supports hijacking.
+func (rec *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := rec.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, errors.New("hijacking is not supported")
+	}
+	rec.code = http.StatusSwitchingProtocols
+	return hijacker.Hijack()
+}
+
+// Unwrap returns the underlying ResponseWriter, for use by http.ResponseController.
+func (rec *statusRecorder) Unwrap() http.ResponseWriter {
+	return rec.ResponseWriter
 }
@@ -3,7 +3,9 @@ package router
 import (
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	"testing"
+	"time"
 )
 
 // TestRouter_Handle is a test function for the Handle method of the Router struct.
@@ -56,3 +58,173 @@ func TestRouter_Handle(t *testing.T) {
 		})
 	}
 }
+
+// TestRouter_Observe is a test function for the Observe method of the Router struct.
+func TestRouter_Observe(t *testing.T) {
+	tests := []struct {
+		name            string
+		requestPath     string
+		expectedPattern string
+		expectedCode    int
+	}{
+		{
+			name:            "Registered pattern",
+			requestPath:     "/test/eth0",
+			expectedPattern: "GET /test/{name}",
+			expectedCode:    http.StatusTeapot,
+		},
+		{
+			name:            "Unregistered pattern",
+			requestPath:     "/unknown",
+			expectedPattern: "",
+			expectedCode:    http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := New()
+			router.GET("/test/{name}", func(w http.ResponseWriter, req *http.Request) {
+				w.WriteHeader(http.StatusTeapot)
+			})
+
+			var pattern string
+			var code int
+			router.Observe(func(p string, c int, d time.Duration) {
+				pattern, code = p, c
+			})
+
+			req, err := http.NewRequest(http.MethodGet, tt.requestPath, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			router.ServeHTTP(httptest.NewRecorder(), req)
+
+			if pattern != tt.expectedPattern {
+				t.Errorf("observed wrong pattern: got %q want %q", pattern, tt.expectedPattern)
+			}
+			if code != tt.expectedCode {
+				t.Errorf("observed wrong status code: got %v want %v", code, tt.expectedCode)
+			}
+		})
+	}
+}
+
+// TestRouter_Group is a test function for the Group method of the Router struct.
+func TestRouter_Group(t *testing.T) {
+	tests := []struct {
+		name           string
+		requestPath    string
+		expectedCode   int
+		expectedHeader string
+	}{
+		{
+			name:           "Path below the prefix",
+			requestPath:    "/v1/test",
+			expectedCode:   http.StatusOK,
+			expectedHeader: "outer,inner",
+		},
+		{
+			name:           "Path without the prefix",
+			requestPath:    "/test",
+			expectedCode:   http.StatusNotFound,
+			expectedHeader: "",
+		},
+	}
+
+	// tag appends its name to the X-Middleware header, recording the order the middleware ran in
+	tag := func(name string) Middleware {
+		return func(next http.Handler) http.Handler {
+			return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				value := name
+				if v := w.Header().Get("X-Middleware"); v != "" {
+					value = v + "," + name
+				}
+				w.Header().Set("X-Middleware", value)
+				next.ServeHTTP(w, req)
+			})
+		}
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			router := New()
+			router.Group("/v1", tag("outer"), tag("inner")).GET("/test", func(w http.ResponseWriter, req *http.Request) {})
+
+			req, err := http.NewRequest("GET", tt.requestPath, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.expectedCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.expectedCode)
+			}
+			if header := rr.Header().Get("X-Middleware"); header != tt.expectedHeader {
+				t.Errorf("middleware order mismatch: got %q want %q", header, tt.expectedHeader)
+			}
+		})
+	}
+}
+
+// TestRouter_Routes is a test function for the Routes method of the Router struct.
+func TestRouter_Routes(t *testing.T) {
+	router := New()
+	router.GET("/metrics", func(w http.ResponseWriter, req *http.Request) {})
+	v1 := router.Group("/v1")
+	v1.GET("/network", func(w http.ResponseWriter, req *http.Request) {})
+	v1.DELETE("/network/{name}", func(w http.ResponseWriter, req *http.Request) {})
+	v1.Handle("/any", http.NotFoundHandler())
+
+	expected := []string{"GET /metrics", "GET /v1/network", "DELETE /v1/network/{name}", "/v1/any"}
+	if routes := router.Routes(); !reflect.DeepEqual(routes, expected) {
+		t.Errorf("routes mismatch: got %v want %v", routes, expected)
+	}
+}
+
+// TestGroup_Methods is a test function for the method-specific registration functions of the Group struct.
+func TestGroup_Methods(t *testing.T) {
+	tests := []struct {
+		name          string
+		requestMethod string
+		requestPath   string
+		expectedCode  int
+	}{
+		{name: "GET", requestMethod: "GET", requestPath: "/v2/items/1", expectedCode: http.StatusOK},
+		{name: "PUT", requestMethod: "PUT", requestPath: "/v2/items/1", expectedCode: http.StatusResetContent},
+		{name: "PATCH", requestMethod: "PATCH", requestPath: "/v2/items/1", expectedCode: http.StatusNoContent},
+		{name: "DELETE", requestMethod: "DELETE", requestPath: "/v2/items/1", expectedCode: http.StatusAccepted},
+		{name: "POST", requestMethod: "POST", requestPath: "/v2/items", expectedCode: http.StatusCreated},
+		{name: "Method not registered", requestMethod: "OPTIONS", requestPath: "/v2/items/1", expectedCode: http.StatusMethodNotAllowed},
+	}
+
+	status := func(code int) http.HandlerFunc {
+		return func(w http.ResponseWriter, req *http.Request) { w.WriteHeader(code) }
+	}
+
+	router := New()
+	group := router.Group("/v2")
+	group.GET("/items/{id}", status(http.StatusOK))
+	group.PUT("/items/{id}", status(http.StatusResetContent))
+	group.PATCH("/items/{id}", status(http.StatusNoContent))
+	group.DELETE("/items/{id}", status(http.StatusAccepted))
+	group.POST("/items", status(http.StatusCreated))
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, err := http.NewRequest(tt.requestMethod, tt.requestPath, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			router.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != tt.expectedCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, tt.expectedCode)
+			}
+		})
+	}
+}
@@ -0,0 +1,1416 @@
+// The Interfacer gRPC service serves the network interfaces of the host, as the v2 HTTP API does.
+//
+// The Go code is generated with protoc v3.21.12 and the protoc-gen-go and protoc-gen-go-grpc
+// releases that match the protobuf and grpc modules required by go.mod:
+//
+//	go install google.golang.org/protobuf/cmd/protoc-gen-go@v1.36.6
+//	go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@v1.5.1
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//		--go-grpc_out=. --go-grpc_opt=paths=source_relative interfacer.proto
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        v3.21.12
+// source: interfacer.proto
+
+package rpc
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type ListInterfacesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Glob patterns the names of the interfaces have to match any of, e.g. eth*. All interfaces if empty.
+	Names []string `protobuf:"bytes,1,rep,name=names,proto3" json:"names,omitempty"`
+	// Whether to include the traffic counters.
+	WithStats     bool `protobuf:"varint,2,opt,name=with_stats,json=withStats,proto3" json:"with_stats,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListInterfacesRequest) Reset() {
+	*x = ListInterfacesRequest{}
+	mi := &file_interfacer_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListInterfacesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListInterfacesRequest) ProtoMessage() {}
+
+func (x *ListInterfacesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_interfacer_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListInterfacesRequest.ProtoReflect.Descriptor instead.
+func (*ListInterfacesRequest) Descriptor() ([]byte, []int) {
+	return file_interfacer_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *ListInterfacesRequest) GetNames() []string {
+	if x != nil {
+		return x.Names
+	}
+	return nil
+}
+
+func (x *ListInterfacesRequest) GetWithStats() bool {
+	if x != nil {
+		return x.WithStats
+	}
+	return false
+}
+
+type ListInterfacesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Interfaces    []*NetworkInterface    `protobuf:"bytes,1,rep,name=interfaces,proto3" json:"interfaces,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListInterfacesResponse) Reset() {
+	*x = ListInterfacesResponse{}
+	mi := &file_interfacer_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListInterfacesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListInterfacesResponse) ProtoMessage() {}
+
+func (x *ListInterfacesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_interfacer_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListInterfacesResponse.ProtoReflect.Descriptor instead.
+func (*ListInterfacesResponse) Descriptor() ([]byte, []int) {
+	return file_interfacer_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *ListInterfacesResponse) GetInterfaces() []*NetworkInterface {
+	if x != nil {
+		return x.Interfaces
+	}
+	return nil
+}
+
+type GetInterfaceRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the interface.
+	Name string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Whether to include the traffic counters.
+	WithStats     bool `protobuf:"varint,2,opt,name=with_stats,json=withStats,proto3" json:"with_stats,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetInterfaceRequest) Reset() {
+	*x = GetInterfaceRequest{}
+	mi := &file_interfacer_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetInterfaceRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetInterfaceRequest) ProtoMessage() {}
+
+func (x *GetInterfaceRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_interfacer_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetInterfaceRequest.ProtoReflect.Descriptor instead.
+func (*GetInterfaceRequest) Descriptor() ([]byte, []int) {
+	return file_interfacer_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *GetInterfaceRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *GetInterfaceRequest) GetWithStats() bool {
+	if x != nil {
+		return x.WithStats
+	}
+	return false
+}
+
+type GetStatsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Name of the interface.
+	Name          string `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetStatsRequest) Reset() {
+	*x = GetStatsRequest{}
+	mi := &file_interfacer_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetStatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetStatsRequest) ProtoMessage() {}
+
+func (x *GetStatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_interfacer_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetStatsRequest.ProtoReflect.Descriptor instead.
+func (*GetStatsRequest) Descriptor() ([]byte, []int) {
+	return file_interfacer_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *GetStatsRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+type WatchInterfacesRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Names of the interfaces to watch, all interfaces if empty.
+	Interfaces []string `protobuf:"bytes,1,rep,name=interfaces,proto3" json:"interfaces,omitempty"`
+	// Event types to watch, e.g. link_up or stats, all event types except stats if empty.
+	Events []string `protobuf:"bytes,2,rep,name=events,proto3" json:"events,omitempty"`
+	// ID of the last event received before reconnecting, to receive the events missed since.
+	LastEventId   uint64 `protobuf:"varint,3,opt,name=last_event_id,json=lastEventId,proto3" json:"last_event_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchInterfacesRequest) Reset() {
+	*x = WatchInterfacesRequest{}
+	mi := &file_interfacer_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchInterfacesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchInterfacesRequest) ProtoMessage() {}
+
+func (x *WatchInterfacesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_interfacer_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchInterfacesRequest.ProtoReflect.Descriptor instead.
+func (*WatchInterfacesRequest) Descriptor() ([]byte, []int) {
+	return file_interfacer_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *WatchInterfacesRequest) GetInterfaces() []string {
+	if x != nil {
+		return x.Interfaces
+	}
+	return nil
+}
+
+func (x *WatchInterfacesRequest) GetEvents() []string {
+	if x != nil {
+		return x.Events
+	}
+	return nil
+}
+
+func (x *WatchInterfacesRequest) GetLastEventId() uint64 {
+	if x != nil {
+		return x.LastEventId
+	}
+	return 0
+}
+
+type WatchInterfacesResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Message:
+	//
+	//	*WatchInterfacesResponse_Event
+	//	*WatchInterfacesResponse_Stats
+	Message       isWatchInterfacesResponse_Message `protobuf_oneof:"message"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WatchInterfacesResponse) Reset() {
+	*x = WatchInterfacesResponse{}
+	mi := &file_interfacer_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WatchInterfacesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WatchInterfacesResponse) ProtoMessage() {}
+
+func (x *WatchInterfacesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_interfacer_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use WatchInterfacesResponse.ProtoReflect.Descriptor instead.
+func (*WatchInterfacesResponse) Descriptor() ([]byte, []int) {
+	return file_interfacer_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *WatchInterfacesResponse) GetMessage() isWatchInterfacesResponse_Message {
+	if x != nil {
+		return x.Message
+	}
+	return nil
+}
+
+func (x *WatchInterfacesResponse) GetEvent() *Event {
+	if x != nil {
+		if x, ok := x.Message.(*WatchInterfacesResponse_Event); ok {
+			return x.Event
+		}
+	}
+	return nil
+}
+
+func (x *WatchInterfacesResponse) GetStats() *NetworkInterfaceStats {
+	if x != nil {
+		if x, ok := x.Message.(*WatchInterfacesResponse_Stats); ok {
+			return x.Stats
+		}
+	}
+	return nil
+}
+
+type isWatchInterfacesResponse_Message interface {
+	isWatchInterfacesResponse_Message()
+}
+
+type WatchInterfacesResponse_Event struct {
+	// A change event of an interface.
+	Event *Event `protobuf:"bytes,1,opt,name=event,proto3,oneof"`
+}
+
+type WatchInterfacesResponse_Stats struct {
+	// The traffic counters of an interface after a sample.
+	Stats *NetworkInterfaceStats `protobuf:"bytes,2,opt,name=stats,proto3,oneof"`
+}
+
+func (*WatchInterfacesResponse_Event) isWatchInterfacesResponse_Message() {}
+
+func (*WatchInterfacesResponse_Stats) isWatchInterfacesResponse_Message() {}
+
+// NetworkInterface is a network interface in the v2 model, without its kind-specific details.
+type NetworkInterface struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Type of the link, e.g. device, bond, bridge, vlan, vxlan or veth.
+	Kind       string `protobuf:"bytes,2,opt,name=kind,proto3" json:"kind,omitempty"`
+	Alias      string `protobuf:"bytes,3,opt,name=alias,proto3" json:"alias,omitempty"`
+	MacAddress string `protobuf:"bytes,4,opt,name=mac_address,json=macAddress,proto3" json:"mac_address,omitempty"`
+	Mtu        int32  `protobuf:"varint,5,opt,name=mtu,proto3" json:"mtu,omitempty"`
+	// Speed in Mb/s, unset if unknown.
+	SpeedMbps *int64 `protobuf:"varint,6,opt,name=speed_mbps,json=speedMbps,proto3,oneof" json:"speed_mbps,omitempty"`
+	// full, half, unknown, or none if no link settings were read.
+	Duplex string `protobuf:"bytes,7,opt,name=duplex,proto3" json:"duplex,omitempty"`
+	// up or down.
+	AdminStatus string `protobuf:"bytes,8,opt,name=admin_status,json=adminStatus,proto3" json:"admin_status,omitempty"`
+	// RFC 2863 operational status, e.g. up, down or unknown.
+	OperStatus string `protobuf:"bytes,9,opt,name=oper_status,json=operStatus,proto3" json:"oper_status,omitempty"`
+	// Name of the bond or bridge the interface is enslaved to.
+	Master string `protobuf:"bytes,10,opt,name=master,proto3" json:"master,omitempty"`
+	// Names of the interfaces enslaved to this bond or bridge.
+	Slaves []string `protobuf:"bytes,11,rep,name=slaves,proto3" json:"slaves,omitempty"`
+	// Wireless state, only for wireless interfaces.
+	Wireless  *Wireless  `protobuf:"bytes,12,opt,name=wireless,proto3" json:"wireless,omitempty"`
+	Addresses []*Address `protobuf:"bytes,13,rep,name=addresses,proto3" json:"addresses,omitempty"`
+	// Traffic counters, if requested.
+	Stats         *InterfaceStats `protobuf:"bytes,14,opt,name=stats,proto3" json:"stats,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkInterface) Reset() {
+	*x = NetworkInterface{}
+	mi := &file_interfacer_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkInterface) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkInterface) ProtoMessage() {}
+
+func (x *NetworkInterface) ProtoReflect() protoreflect.Message {
+	mi := &file_interfacer_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkInterface.ProtoReflect.Descriptor instead.
+func (*NetworkInterface) Descriptor() ([]byte, []int) {
+	return file_interfacer_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *NetworkInterface) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *NetworkInterface) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *NetworkInterface) GetAlias() string {
+	if x != nil {
+		return x.Alias
+	}
+	return ""
+}
+
+func (x *NetworkInterface) GetMacAddress() string {
+	if x != nil {
+		return x.MacAddress
+	}
+	return ""
+}
+
+func (x *NetworkInterface) GetMtu() int32 {
+	if x != nil {
+		return x.Mtu
+	}
+	return 0
+}
+
+func (x *NetworkInterface) GetSpeedMbps() int64 {
+	if x != nil && x.SpeedMbps != nil {
+		return *x.SpeedMbps
+	}
+	return 0
+}
+
+func (x *NetworkInterface) GetDuplex() string {
+	if x != nil {
+		return x.Duplex
+	}
+	return ""
+}
+
+func (x *NetworkInterface) GetAdminStatus() string {
+	if x != nil {
+		return x.AdminStatus
+	}
+	return ""
+}
+
+func (x *NetworkInterface) GetOperStatus() string {
+	if x != nil {
+		return x.OperStatus
+	}
+	return ""
+}
+
+func (x *NetworkInterface) GetMaster() string {
+	if x != nil {
+		return x.Master
+	}
+	return ""
+}
+
+func (x *NetworkInterface) GetSlaves() []string {
+	if x != nil {
+		return x.Slaves
+	}
+	return nil
+}
+
+func (x *NetworkInterface) GetWireless() *Wireless {
+	if x != nil {
+		return x.Wireless
+	}
+	return nil
+}
+
+func (x *NetworkInterface) GetAddresses() []*Address {
+	if x != nil {
+		return x.Addresses
+	}
+	return nil
+}
+
+func (x *NetworkInterface) GetStats() *InterfaceStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+type Address struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	Address      string                 `protobuf:"bytes,1,opt,name=address,proto3" json:"address,omitempty"`
+	PrefixLength int32                  `protobuf:"varint,2,opt,name=prefix_length,json=prefixLength,proto3" json:"prefix_length,omitempty"`
+	// ipv4 or ipv6.
+	Family string `protobuf:"bytes,3,opt,name=family,proto3" json:"family,omitempty"`
+	// global, site, link, host or nowhere.
+	Scope string `protobuf:"bytes,4,opt,name=scope,proto3" json:"scope,omitempty"`
+	// e.g. permanent, secondary or tentative.
+	Flags         []string `protobuf:"bytes,5,rep,name=flags,proto3" json:"flags,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Address) Reset() {
+	*x = Address{}
+	mi := &file_interfacer_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Address) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Address) ProtoMessage() {}
+
+func (x *Address) ProtoReflect() protoreflect.Message {
+	mi := &file_interfacer_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Address.ProtoReflect.Descriptor instead.
+func (*Address) Descriptor() ([]byte, []int) {
+	return file_interfacer_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *Address) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Address) GetPrefixLength() int32 {
+	if x != nil {
+		return x.PrefixLength
+	}
+	return 0
+}
+
+func (x *Address) GetFamily() string {
+	if x != nil {
+		return x.Family
+	}
+	return ""
+}
+
+func (x *Address) GetScope() string {
+	if x != nil {
+		return x.Scope
+	}
+	return ""
+}
+
+func (x *Address) GetFlags() []string {
+	if x != nil {
+		return x.Flags
+	}
+	return nil
+}
+
+type Wireless struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// e.g. station, ap, adhoc, monitor or mesh.
+	Mode         string `protobuf:"bytes,1,opt,name=mode,proto3" json:"mode,omitempty"`
+	Connected    bool   `protobuf:"varint,2,opt,name=connected,proto3" json:"connected,omitempty"`
+	Ssid         string `protobuf:"bytes,3,opt,name=ssid,proto3" json:"ssid,omitempty"`
+	Bssid        string `protobuf:"bytes,4,opt,name=bssid,proto3" json:"bssid,omitempty"`
+	FrequencyMhz int32  `protobuf:"varint,5,opt,name=frequency_mhz,json=frequencyMhz,proto3" json:"frequency_mhz,omitempty"`
+	Channel      int32  `protobuf:"varint,6,opt,name=channel,proto3" json:"channel,omitempty"`
+	// Signal strength in dBm, unset if not connected.
+	SignalDbm        *int32  `protobuf:"varint,7,opt,name=signal_dbm,json=signalDbm,proto3,oneof" json:"signal_dbm,omitempty"`
+	TxBitrateMbps    float64 `protobuf:"fixed64,8,opt,name=tx_bitrate_mbps,json=txBitrateMbps,proto3" json:"tx_bitrate_mbps,omitempty"`
+	RxBitrateMbps    float64 `protobuf:"fixed64,9,opt,name=rx_bitrate_mbps,json=rxBitrateMbps,proto3" json:"rx_bitrate_mbps,omitempty"`
+	ConnectedSeconds uint32  `protobuf:"varint,10,opt,name=connected_seconds,json=connectedSeconds,proto3" json:"connected_seconds,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *Wireless) Reset() {
+	*x = Wireless{}
+	mi := &file_interfacer_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Wireless) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Wireless) ProtoMessage() {}
+
+func (x *Wireless) ProtoReflect() protoreflect.Message {
+	mi := &file_interfacer_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Wireless.ProtoReflect.Descriptor instead.
+func (*Wireless) Descriptor() ([]byte, []int) {
+	return file_interfacer_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Wireless) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *Wireless) GetConnected() bool {
+	if x != nil {
+		return x.Connected
+	}
+	return false
+}
+
+func (x *Wireless) GetSsid() string {
+	if x != nil {
+		return x.Ssid
+	}
+	return ""
+}
+
+func (x *Wireless) GetBssid() string {
+	if x != nil {
+		return x.Bssid
+	}
+	return ""
+}
+
+func (x *Wireless) GetFrequencyMhz() int32 {
+	if x != nil {
+		return x.FrequencyMhz
+	}
+	return 0
+}
+
+func (x *Wireless) GetChannel() int32 {
+	if x != nil {
+		return x.Channel
+	}
+	return 0
+}
+
+func (x *Wireless) GetSignalDbm() int32 {
+	if x != nil && x.SignalDbm != nil {
+		return *x.SignalDbm
+	}
+	return 0
+}
+
+func (x *Wireless) GetTxBitrateMbps() float64 {
+	if x != nil {
+		return x.TxBitrateMbps
+	}
+	return 0
+}
+
+func (x *Wireless) GetRxBitrateMbps() float64 {
+	if x != nil {
+		return x.RxBitrateMbps
+	}
+	return 0
+}
+
+func (x *Wireless) GetConnectedSeconds() uint32 {
+	if x != nil {
+		return x.ConnectedSeconds
+	}
+	return 0
+}
+
+type InterfaceStats struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	RxBytes    uint64                 `protobuf:"varint,1,opt,name=rx_bytes,json=rxBytes,proto3" json:"rx_bytes,omitempty"`
+	TxBytes    uint64                 `protobuf:"varint,2,opt,name=tx_bytes,json=txBytes,proto3" json:"tx_bytes,omitempty"`
+	RxPackets  uint64                 `protobuf:"varint,3,opt,name=rx_packets,json=rxPackets,proto3" json:"rx_packets,omitempty"`
+	TxPackets  uint64                 `protobuf:"varint,4,opt,name=tx_packets,json=txPackets,proto3" json:"tx_packets,omitempty"`
+	RxErrors   uint64                 `protobuf:"varint,5,opt,name=rx_errors,json=rxErrors,proto3" json:"rx_errors,omitempty"`
+	TxErrors   uint64                 `protobuf:"varint,6,opt,name=tx_errors,json=txErrors,proto3" json:"tx_errors,omitempty"`
+	RxDropped  uint64                 `protobuf:"varint,7,opt,name=rx_dropped,json=rxDropped,proto3" json:"rx_dropped,omitempty"`
+	TxDropped  uint64                 `protobuf:"varint,8,opt,name=tx_dropped,json=txDropped,proto3" json:"tx_dropped,omitempty"`
+	Multicast  uint64                 `protobuf:"varint,9,opt,name=multicast,proto3" json:"multicast,omitempty"`
+	Collisions uint64                 `protobuf:"varint,10,opt,name=collisions,proto3" json:"collisions,omitempty"`
+	// Per-second rates since the previous sample, if any.
+	Rates         *InterfaceRates `protobuf:"bytes,11,opt,name=rates,proto3" json:"rates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InterfaceStats) Reset() {
+	*x = InterfaceStats{}
+	mi := &file_interfacer_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InterfaceStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InterfaceStats) ProtoMessage() {}
+
+func (x *InterfaceStats) ProtoReflect() protoreflect.Message {
+	mi := &file_interfacer_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InterfaceStats.ProtoReflect.Descriptor instead.
+func (*InterfaceStats) Descriptor() ([]byte, []int) {
+	return file_interfacer_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *InterfaceStats) GetRxBytes() uint64 {
+	if x != nil {
+		return x.RxBytes
+	}
+	return 0
+}
+
+func (x *InterfaceStats) GetTxBytes() uint64 {
+	if x != nil {
+		return x.TxBytes
+	}
+	return 0
+}
+
+func (x *InterfaceStats) GetRxPackets() uint64 {
+	if x != nil {
+		return x.RxPackets
+	}
+	return 0
+}
+
+func (x *InterfaceStats) GetTxPackets() uint64 {
+	if x != nil {
+		return x.TxPackets
+	}
+	return 0
+}
+
+func (x *InterfaceStats) GetRxErrors() uint64 {
+	if x != nil {
+		return x.RxErrors
+	}
+	return 0
+}
+
+func (x *InterfaceStats) GetTxErrors() uint64 {
+	if x != nil {
+		return x.TxErrors
+	}
+	return 0
+}
+
+func (x *InterfaceStats) GetRxDropped() uint64 {
+	if x != nil {
+		return x.RxDropped
+	}
+	return 0
+}
+
+func (x *InterfaceStats) GetTxDropped() uint64 {
+	if x != nil {
+		return x.TxDropped
+	}
+	return 0
+}
+
+func (x *InterfaceStats) GetMulticast() uint64 {
+	if x != nil {
+		return x.Multicast
+	}
+	return 0
+}
+
+func (x *InterfaceStats) GetCollisions() uint64 {
+	if x != nil {
+		return x.Collisions
+	}
+	return 0
+}
+
+func (x *InterfaceStats) GetRates() *InterfaceRates {
+	if x != nil {
+		return x.Rates
+	}
+	return nil
+}
+
+type InterfaceRates struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	IntervalSeconds float64                `protobuf:"fixed64,1,opt,name=interval_seconds,json=intervalSeconds,proto3" json:"interval_seconds,omitempty"`
+	RxBytes         float64                `protobuf:"fixed64,2,opt,name=rx_bytes,json=rxBytes,proto3" json:"rx_bytes,omitempty"`
+	TxBytes         float64                `protobuf:"fixed64,3,opt,name=tx_bytes,json=txBytes,proto3" json:"tx_bytes,omitempty"`
+	RxPackets       float64                `protobuf:"fixed64,4,opt,name=rx_packets,json=rxPackets,proto3" json:"rx_packets,omitempty"`
+	TxPackets       float64                `protobuf:"fixed64,5,opt,name=tx_packets,json=txPackets,proto3" json:"tx_packets,omitempty"`
+	RxErrors        float64                `protobuf:"fixed64,6,opt,name=rx_errors,json=rxErrors,proto3" json:"rx_errors,omitempty"`
+	TxErrors        float64                `protobuf:"fixed64,7,opt,name=tx_errors,json=txErrors,proto3" json:"tx_errors,omitempty"`
+	RxDropped       float64                `protobuf:"fixed64,8,opt,name=rx_dropped,json=rxDropped,proto3" json:"rx_dropped,omitempty"`
+	TxDropped       float64                `protobuf:"fixed64,9,opt,name=tx_dropped,json=txDropped,proto3" json:"tx_dropped,omitempty"`
+	Multicast       float64                `protobuf:"fixed64,10,opt,name=multicast,proto3" json:"multicast,omitempty"`
+	Collisions      float64                `protobuf:"fixed64,11,opt,name=collisions,proto3" json:"collisions,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *InterfaceRates) Reset() {
+	*x = InterfaceRates{}
+	mi := &file_interfacer_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InterfaceRates) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InterfaceRates) ProtoMessage() {}
+
+func (x *InterfaceRates) ProtoReflect() protoreflect.Message {
+	mi := &file_interfacer_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InterfaceRates.ProtoReflect.Descriptor instead.
+func (*InterfaceRates) Descriptor() ([]byte, []int) {
+	return file_interfacer_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *InterfaceRates) GetIntervalSeconds() float64 {
+	if x != nil {
+		return x.IntervalSeconds
+	}
+	return 0
+}
+
+func (x *InterfaceRates) GetRxBytes() float64 {
+	if x != nil {
+		return x.RxBytes
+	}
+	return 0
+}
+
+func (x *InterfaceRates) GetTxBytes() float64 {
+	if x != nil {
+		return x.TxBytes
+	}
+	return 0
+}
+
+func (x *InterfaceRates) GetRxPackets() float64 {
+	if x != nil {
+		return x.RxPackets
+	}
+	return 0
+}
+
+func (x *InterfaceRates) GetTxPackets() float64 {
+	if x != nil {
+		return x.TxPackets
+	}
+	return 0
+}
+
+func (x *InterfaceRates) GetRxErrors() float64 {
+	if x != nil {
+		return x.RxErrors
+	}
+	return 0
+}
+
+func (x *InterfaceRates) GetTxErrors() float64 {
+	if x != nil {
+		return x.TxErrors
+	}
+	return 0
+}
+
+func (x *InterfaceRates) GetRxDropped() float64 {
+	if x != nil {
+		return x.RxDropped
+	}
+	return 0
+}
+
+func (x *InterfaceRates) GetTxDropped() float64 {
+	if x != nil {
+		return x.TxDropped
+	}
+	return 0
+}
+
+func (x *InterfaceRates) GetMulticast() float64 {
+	if x != nil {
+		return x.Multicast
+	}
+	return 0
+}
+
+func (x *InterfaceRates) GetCollisions() float64 {
+	if x != nil {
+		return x.Collisions
+	}
+	return 0
+}
+
+type NetworkInterfaceStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Stats         *InterfaceStats        `protobuf:"bytes,2,opt,name=stats,proto3" json:"stats,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NetworkInterfaceStats) Reset() {
+	*x = NetworkInterfaceStats{}
+	mi := &file_interfacer_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NetworkInterfaceStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NetworkInterfaceStats) ProtoMessage() {}
+
+func (x *NetworkInterfaceStats) ProtoReflect() protoreflect.Message {
+	mi := &file_interfacer_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NetworkInterfaceStats.ProtoReflect.Descriptor instead.
+func (*NetworkInterfaceStats) Descriptor() ([]byte, []int) {
+	return file_interfacer_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *NetworkInterfaceStats) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *NetworkInterfaceStats) GetStats() *InterfaceStats {
+	if x != nil {
+		return x.Stats
+	}
+	return nil
+}
+
+type Event struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Sequence number of the event, usable as last_event_id.
+	Id uint64 `protobuf:"varint,1,opt,name=id,proto3" json:"id,omitempty"`
+	// e.g. interface_created, link_up, address_added, mtu_changed or drift_detected.
+	Type      string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	Interface string                 `protobuf:"bytes,3,opt,name=interface,proto3" json:"interface,omitempty"`
+	Timestamp *timestamppb.Timestamp `protobuf:"bytes,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// The added or removed IP address.
+	Address string `protobuf:"bytes,5,opt,name=address,proto3" json:"address,omitempty"`
+	OldMtu  int32  `protobuf:"varint,6,opt,name=old_mtu,json=oldMtu,proto3" json:"old_mtu,omitempty"`
+	NewMtu  int32  `protobuf:"varint,7,opt,name=new_mtu,json=newMtu,proto3" json:"new_mtu,omitempty"`
+	// Changes that bring a drifted interface back to its desired state.
+	Changes       []*FieldChange `protobuf:"bytes,8,rep,name=changes,proto3" json:"changes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Event) Reset() {
+	*x = Event{}
+	mi := &file_interfacer_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Event) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Event) ProtoMessage() {}
+
+func (x *Event) ProtoReflect() protoreflect.Message {
+	mi := &file_interfacer_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Event.ProtoReflect.Descriptor instead.
+func (*Event) Descriptor() ([]byte, []int) {
+	return file_interfacer_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *Event) GetId() uint64 {
+	if x != nil {
+		return x.Id
+	}
+	return 0
+}
+
+func (x *Event) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Event) GetInterface() string {
+	if x != nil {
+		return x.Interface
+	}
+	return ""
+}
+
+func (x *Event) GetTimestamp() *timestamppb.Timestamp {
+	if x != nil {
+		return x.Timestamp
+	}
+	return nil
+}
+
+func (x *Event) GetAddress() string {
+	if x != nil {
+		return x.Address
+	}
+	return ""
+}
+
+func (x *Event) GetOldMtu() int32 {
+	if x != nil {
+		return x.OldMtu
+	}
+	return 0
+}
+
+func (x *Event) GetNewMtu() int32 {
+	if x != nil {
+		return x.NewMtu
+	}
+	return 0
+}
+
+func (x *Event) GetChanges() []*FieldChange {
+	if x != nil {
+		return x.Changes
+	}
+	return nil
+}
+
+type FieldChange struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// e.g. mtu, admin_status or addresses.
+	Field string `protobuf:"bytes,1,opt,name=field,proto3" json:"field,omitempty"`
+	// Value before the change, e.g. 1500.
+	Old string `protobuf:"bytes,2,opt,name=old,proto3" json:"old,omitempty"`
+	// Value after the change, e.g. 9000.
+	New           string `protobuf:"bytes,3,opt,name=new,proto3" json:"new,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FieldChange) Reset() {
+	*x = FieldChange{}
+	mi := &file_interfacer_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FieldChange) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FieldChange) ProtoMessage() {}
+
+func (x *FieldChange) ProtoReflect() protoreflect.Message {
+	mi := &file_interfacer_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FieldChange.ProtoReflect.Descriptor instead.
+func (*FieldChange) Descriptor() ([]byte, []int) {
+	return file_interfacer_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *FieldChange) GetField() string {
+	if x != nil {
+		return x.Field
+	}
+	return ""
+}
+
+func (x *FieldChange) GetOld() string {
+	if x != nil {
+		return x.Old
+	}
+	return ""
+}
+
+func (x *FieldChange) GetNew() string {
+	if x != nil {
+		return x.New
+	}
+	return ""
+}
+
+var File_interfacer_proto protoreflect.FileDescriptor
+
+const file_interfacer_proto_rawDesc = "" +
+	"\n" +
+	"\x10interfacer.proto\x12\rinterfacer.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"L\n" +
+	"\x15ListInterfacesRequest\x12\x14\n" +
+	"\x05names\x18\x01 \x03(\tR\x05names\x12\x1d\n" +
+	"\n" +
+	"with_stats\x18\x02 \x01(\bR\twithStats\"Y\n" +
+	"\x16ListInterfacesResponse\x12?\n" +
+	"\n" +
+	"interfaces\x18\x01 \x03(\v2\x1f.interfacer.v1.NetworkInterfaceR\n" +
+	"interfaces\"H\n" +
+	"\x13GetInterfaceRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"with_stats\x18\x02 \x01(\bR\twithStats\"%\n" +
+	"\x0fGetStatsRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"t\n" +
+	"\x16WatchInterfacesRequest\x12\x1e\n" +
+	"\n" +
+	"interfaces\x18\x01 \x03(\tR\n" +
+	"interfaces\x12\x16\n" +
+	"\x06events\x18\x02 \x03(\tR\x06events\x12\"\n" +
+	"\rlast_event_id\x18\x03 \x01(\x04R\vlastEventId\"\x90\x01\n" +
+	"\x17WatchInterfacesResponse\x12,\n" +
+	"\x05event\x18\x01 \x01(\v2\x14.interfacer.v1.EventH\x00R\x05event\x12<\n" +
+	"\x05stats\x18\x02 \x01(\v2$.interfacer.v1.NetworkInterfaceStatsH\x00R\x05statsB\t\n" +
+	"\amessage\"\xe2\x03\n" +
+	"\x10NetworkInterface\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x12\n" +
+	"\x04kind\x18\x02 \x01(\tR\x04kind\x12\x14\n" +
+	"\x05alias\x18\x03 \x01(\tR\x05alias\x12\x1f\n" +
+	"\vmac_address\x18\x04 \x01(\tR\n" +
+	"macAddress\x12\x10\n" +
+	"\x03mtu\x18\x05 \x01(\x05R\x03mtu\x12\"\n" +
+	"\n" +
+	"speed_mbps\x18\x06 \x01(\x03H\x00R\tspeedMbps\x88\x01\x01\x12\x16\n" +
+	"\x06duplex\x18\a \x01(\tR\x06duplex\x12!\n" +
+	"\fadmin_status\x18\b \x01(\tR\vadminStatus\x12\x1f\n" +
+	"\voper_status\x18\t \x01(\tR\n" +
+	"operStatus\x12\x16\n" +
+	"\x06master\x18\n" +
+	" \x01(\tR\x06master\x12\x16\n" +
+	"\x06slaves\x18\v \x03(\tR\x06slaves\x123\n" +
+	"\bwireless\x18\f \x01(\v2\x17.interfacer.v1.WirelessR\bwireless\x124\n" +
+	"\taddresses\x18\r \x03(\v2\x16.interfacer.v1.AddressR\taddresses\x123\n" +
+	"\x05stats\x18\x0e \x01(\v2\x1d.interfacer.v1.InterfaceStatsR\x05statsB\r\n" +
+	"\v_speed_mbps\"\x8c\x01\n" +
+	"\aAddress\x12\x18\n" +
+	"\aaddress\x18\x01 \x01(\tR\aaddress\x12#\n" +
+	"\rprefix_length\x18\x02 \x01(\x05R\fprefixLength\x12\x16\n" +
+	"\x06family\x18\x03 \x01(\tR\x06family\x12\x14\n" +
+	"\x05scope\x18\x04 \x01(\tR\x05scope\x12\x14\n" +
+	"\x05flags\x18\x05 \x03(\tR\x05flags\"\xd5\x02\n" +
+	"\bWireless\x12\x12\n" +
+	"\x04mode\x18\x01 \x01(\tR\x04mode\x12\x1c\n" +
+	"\tconnected\x18\x02 \x01(\bR\tconnected\x12\x12\n" +
+	"\x04ssid\x18\x03 \x01(\tR\x04ssid\x12\x14\n" +
+	"\x05bssid\x18\x04 \x01(\tR\x05bssid\x12#\n" +
+	"\rfrequency_mhz\x18\x05 \x01(\x05R\ffrequencyMhz\x12\x18\n" +
+	"\achannel\x18\x06 \x01(\x05R\achannel\x12\"\n" +
+	"\n" +
+	"signal_dbm\x18\a \x01(\x05H\x00R\tsignalDbm\x88\x01\x01\x12&\n" +
+	"\x0ftx_bitrate_mbps\x18\b \x01(\x01R\rtxBitrateMbps\x12&\n" +
+	"\x0frx_bitrate_mbps\x18\t \x01(\x01R\rrxBitrateMbps\x12+\n" +
+	"\x11connected_seconds\x18\n" +
+	" \x01(\rR\x10connectedSecondsB\r\n" +
+	"\v_signal_dbm\"\xef\x02\n" +
+	"\x0eInterfaceStats\x12\x19\n" +
+	"\brx_bytes\x18\x01 \x01(\x04R\arxBytes\x12\x19\n" +
+	"\btx_bytes\x18\x02 \x01(\x04R\atxBytes\x12\x1d\n" +
+	"\n" +
+	"rx_packets\x18\x03 \x01(\x04R\trxPackets\x12\x1d\n" +
+	"\n" +
+	"tx_packets\x18\x04 \x01(\x04R\ttxPackets\x12\x1b\n" +
+	"\trx_errors\x18\x05 \x01(\x04R\brxErrors\x12\x1b\n" +
+	"\ttx_errors\x18\x06 \x01(\x04R\btxErrors\x12\x1d\n" +
+	"\n" +
+	"rx_dropped\x18\a \x01(\x04R\trxDropped\x12\x1d\n" +
+	"\n" +
+	"tx_dropped\x18\b \x01(\x04R\ttxDropped\x12\x1c\n" +
+	"\tmulticast\x18\t \x01(\x04R\tmulticast\x12\x1e\n" +
+	"\n" +
+	"collisions\x18\n" +
+	" \x01(\x04R\n" +
+	"collisions\x123\n" +
+	"\x05rates\x18\v \x01(\v2\x1d.interfacer.v1.InterfaceRatesR\x05rates\"\xe5\x02\n" +
+	"\x0eInterfaceRates\x12)\n" +
+	"\x10interval_seconds\x18\x01 \x01(\x01R\x0fintervalSeconds\x12\x19\n" +
+	"\brx_bytes\x18\x02 \x01(\x01R\arxBytes\x12\x19\n" +
+	"\btx_bytes\x18\x03 \x01(\x01R\atxBytes\x12\x1d\n" +
+	"\n" +
+	"rx_packets\x18\x04 \x01(\x01R\trxPackets\x12\x1d\n" +
+	"\n" +
+	"tx_packets\x18\x05 \x01(\x01R\ttxPackets\x12\x1b\n" +
+	"\trx_errors\x18\x06 \x01(\x01R\brxErrors\x12\x1b\n" +
+	"\ttx_errors\x18\a \x01(\x01R\btxErrors\x12\x1d\n" +
+	"\n" +
+	"rx_dropped\x18\b \x01(\x01R\trxDropped\x12\x1d\n" +
+	"\n" +
+	"tx_dropped\x18\t \x01(\x01R\ttxDropped\x12\x1c\n" +
+	"\tmulticast\x18\n" +
+	" \x01(\x01R\tmulticast\x12\x1e\n" +
+	"\n" +
+	"collisions\x18\v \x01(\x01R\n" +
+	"collisions\"`\n" +
+	"\x15NetworkInterfaceStats\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x123\n" +
+	"\x05stats\x18\x02 \x01(\v2\x1d.interfacer.v1.InterfaceStatsR\x05stats\"\x85\x02\n" +
+	"\x05Event\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\x04R\x02id\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\x12\x1c\n" +
+	"\tinterface\x18\x03 \x01(\tR\tinterface\x128\n" +
+	"\ttimestamp\x18\x04 \x01(\v2\x1a.google.protobuf.TimestampR\ttimestamp\x12\x18\n" +
+	"\aaddress\x18\x05 \x01(\tR\aaddress\x12\x17\n" +
+	"\aold_mtu\x18\x06 \x01(\x05R\x06oldMtu\x12\x17\n" +
+	"\anew_mtu\x18\a \x01(\x05R\x06newMtu\x124\n" +
+	"\achanges\x18\b \x03(\v2\x1a.interfacer.v1.FieldChangeR\achanges\"G\n" +
+	"\vFieldChange\x12\x14\n" +
+	"\x05field\x18\x01 \x01(\tR\x05field\x12\x10\n" +
+	"\x03old\x18\x02 \x01(\tR\x03old\x12\x10\n" +
+	"\x03new\x18\x03 \x01(\tR\x03new2\xf6\x02\n" +
+	"\n" +
+	"Interfacer\x12]\n" +
+	"\x0eListInterfaces\x12$.interfacer.v1.ListInterfacesRequest\x1a%.interfacer.v1.ListInterfacesResponse\x12S\n" +
+	"\fGetInterface\x12\".interfacer.v1.GetInterfaceRequest\x1a\x1f.interfacer.v1.NetworkInterface\x12P\n" +
+	"\bGetStats\x12\x1e.interfacer.v1.GetStatsRequest\x1a$.interfacer.v1.NetworkInterfaceStats\x12b\n" +
+	"\x0fWatchInterfaces\x12%.interfacer.v1.WatchInterfacesRequest\x1a&.interfacer.v1.WatchInterfacesResponse0\x01B\x12Z\x10servermodule/rpcb\x06proto3"
+
+var (
+	file_interfacer_proto_rawDescOnce sync.Once
+	file_interfacer_proto_rawDescData []byte
+)
+
+func file_interfacer_proto_rawDescGZIP() []byte {
+	file_interfacer_proto_rawDescOnce.Do(func() {
+		file_interfacer_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_interfacer_proto_rawDesc), len(file_interfacer_proto_rawDesc)))
+	})
+	return file_interfacer_proto_rawDescData
+}
+
+var file_interfacer_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_interfacer_proto_goTypes = []any{
+	(*ListInterfacesRequest)(nil),   // 0: interfacer.v1.ListInterfacesRequest
+	(*ListInterfacesResponse)(nil),  // 1: interfacer.v1.ListInterfacesResponse
+	(*GetInterfaceRequest)(nil),     // 2: interfacer.v1.GetInterfaceRequest
+	(*GetStatsRequest)(nil),         // 3: interfacer.v1.GetStatsRequest
+	(*WatchInterfacesRequest)(nil),  // 4: interfacer.v1.WatchInterfacesRequest
+	(*WatchInterfacesResponse)(nil), // 5: interfacer.v1.WatchInterfacesResponse
+	(*NetworkInterface)(nil),        // 6: interfacer.v1.NetworkInterface
+	(*Address)(nil),                 // 7: interfacer.v1.Address
+	(*Wireless)(nil),                // 8: interfacer.v1.Wireless
+	(*InterfaceStats)(nil),          // 9: interfacer.v1.InterfaceStats
+	(*InterfaceRates)(nil),          // 10: interfacer.v1.InterfaceRates
+	(*NetworkInterfaceStats)(nil),   // 11: interfacer.v1.NetworkInterfaceStats
+	(*Event)(nil),                   // 12: interfacer.v1.Event
+	(*FieldChange)(nil),             // 13: interfacer.v1.FieldChange
+	(*timestamppb.Timestamp)(nil),   // 14: google.protobuf.Timestamp
+}
+var file_interfacer_proto_depIdxs = []int32{
+	6,  // 0: interfacer.v1.ListInterfacesResponse.interfaces:type_name -> interfacer.v1.NetworkInterface
+	12, // 1: interfacer.v1.WatchInterfacesResponse.event:type_name -> interfacer.v1.Event
+	11, // 2: interfacer.v1.WatchInterfacesResponse.stats:type_name -> interfacer.v1.NetworkInterfaceStats
+	8,  // 3: interfacer.v1.NetworkInterface.wireless:type_name -> interfacer.v1.Wireless
+	7,  // 4: interfacer.v1.NetworkInterface.addresses:type_name -> interfacer.v1.Address
+	9,  // 5: interfacer.v1.NetworkInterface.stats:type_name -> interfacer.v1.InterfaceStats
+	10, // 6: interfacer.v1.InterfaceStats.rates:type_name -> interfacer.v1.InterfaceRates
+	9,  // 7: interfacer.v1.NetworkInterfaceStats.stats:type_name -> interfacer.v1.InterfaceStats
+	14, // 8: interfacer.v1.Event.timestamp:type_name -> google.protobuf.Timestamp
+	13, // 9: interfacer.v1.Event.changes:type_name -> interfacer.v1.FieldChange
+	0,  // 10: interfacer.v1.Interfacer.ListInterfaces:input_type -> interfacer.v1.ListInterfacesRequest
+	2,  // 11: interfacer.v1.Interfacer.GetInterface:input_type -> interfacer.v1.GetInterfaceRequest
+	3,  // 12: interfacer.v1.Interfacer.GetStats:input_type -> interfacer.v1.GetStatsRequest
+	4,  // 13: interfacer.v1.Interfacer.WatchInterfaces:input_type -> interfacer.v1.WatchInterfacesRequest
+	1,  // 14: interfacer.v1.Interfacer.ListInterfaces:output_type -> interfacer.v1.ListInterfacesResponse
+	6,  // 15: interfacer.v1.Interfacer.GetInterface:output_type -> interfacer.v1.NetworkInterface
+	11, // 16: interfacer.v1.Interfacer.GetStats:output_type -> interfacer.v1.NetworkInterfaceStats
+	5,  // 17: interfacer.v1.Interfacer.WatchInterfaces:output_type -> interfacer.v1.WatchInterfacesResponse
+	14, // [14:18] is the sub-list for method output_type
+	10, // [10:14] is the sub-list for method input_type
+	10, // [10:10] is the sub-list for extension type_name
+	10, // [10:10] is the sub-list for extension extendee
+	0,  // [0:10] is the sub-list for field type_name
+}
+
+func init() { file_interfacer_proto_init() }
+func file_interfacer_proto_init() {
+	if File_interfacer_proto != nil {
+		return
+	}
+	file_interfacer_proto_msgTypes[5].OneofWrappers = []any{
+		(*WatchInterfacesResponse_Event)(nil),
+		(*WatchInterfacesResponse_Stats)(nil),
+	}
+	file_interfacer_proto_msgTypes[6].OneofWrappers = []any{}
+	file_interfacer_proto_msgTypes[8].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_interfacer_proto_rawDesc), len(file_interfacer_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_interfacer_proto_goTypes,
+		DependencyIndexes: file_interfacer_proto_depIdxs,
+		MessageInfos:      file_interfacer_proto_msgTypes,
+	}.Build()
+	File_interfacer_proto = out.File
+	file_interfacer_proto_goTypes = nil
+	file_interfacer_proto_depIdxs = nil
+}
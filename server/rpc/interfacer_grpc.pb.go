@@ -0,0 +1,267 @@
+// The Interfacer gRPC service serves the network interfaces of the host, as the v2 HTTP API does.
+//
+// The Go code is generated with protoc v3.21.12 and the protoc-gen-go and protoc-gen-go-grpc
+// releases that match the protobuf and grpc modules required by go.mod:
+//
+//	go install google.golang.org/protobuf/cmd/protoc-gen-go@v1.36.6
+//	go install google.golang.org/grpc/cmd/protoc-gen-go-grpc@v1.5.1
+//	protoc --go_out=. --go_opt=paths=source_relative \
+//		--go-grpc_out=. --go-grpc_opt=paths=source_relative interfacer.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             v3.21.12
+// source: interfacer.proto
+
+package rpc
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Interfacer_ListInterfaces_FullMethodName  = "/interfacer.v1.Interfacer/ListInterfaces"
+	Interfacer_GetInterface_FullMethodName    = "/interfacer.v1.Interfacer/GetInterface"
+	Interfacer_GetStats_FullMethodName        = "/interfacer.v1.Interfacer/GetStats"
+	Interfacer_WatchInterfaces_FullMethodName = "/interfacer.v1.Interfacer/WatchInterfaces"
+)
+
+// InterfacerClient is the client API for Interfacer service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Interfacer serves the network interfaces of the host.
+type InterfacerClient interface {
+	// ListInterfaces returns the interfaces, optionally filtered by name.
+	ListInterfaces(ctx context.Context, in *ListInterfacesRequest, opts ...grpc.CallOption) (*ListInterfacesResponse, error)
+	// GetInterface returns a single interface, or NOT_FOUND if it doesn't exist.
+	GetInterface(ctx context.Context, in *GetInterfaceRequest, opts ...grpc.CallOption) (*NetworkInterface, error)
+	// GetStats returns the traffic counters of a single interface with the per-second rates since
+	// the previous sample, or NOT_FOUND if the interface doesn't exist or has no counters.
+	GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*NetworkInterfaceStats, error)
+	// WatchInterfaces streams the change events of the interfaces and, if subscribed to the
+	// stats event type, their traffic counters after every sample, until the client cancels.
+	// Watchers falling too far behind are ended with RESOURCE_EXHAUSTED and have to resume.
+	WatchInterfaces(ctx context.Context, in *WatchInterfacesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchInterfacesResponse], error)
+}
+
+type interfacerClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewInterfacerClient(cc grpc.ClientConnInterface) InterfacerClient {
+	return &interfacerClient{cc}
+}
+
+func (c *interfacerClient) ListInterfaces(ctx context.Context, in *ListInterfacesRequest, opts ...grpc.CallOption) (*ListInterfacesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListInterfacesResponse)
+	err := c.cc.Invoke(ctx, Interfacer_ListInterfaces_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interfacerClient) GetInterface(ctx context.Context, in *GetInterfaceRequest, opts ...grpc.CallOption) (*NetworkInterface, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NetworkInterface)
+	err := c.cc.Invoke(ctx, Interfacer_GetInterface_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interfacerClient) GetStats(ctx context.Context, in *GetStatsRequest, opts ...grpc.CallOption) (*NetworkInterfaceStats, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NetworkInterfaceStats)
+	err := c.cc.Invoke(ctx, Interfacer_GetStats_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *interfacerClient) WatchInterfaces(ctx context.Context, in *WatchInterfacesRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[WatchInterfacesResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Interfacer_ServiceDesc.Streams[0], Interfacer_WatchInterfaces_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[WatchInterfacesRequest, WatchInterfacesResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Interfacer_WatchInterfacesClient = grpc.ServerStreamingClient[WatchInterfacesResponse]
+
+// InterfacerServer is the server API for Interfacer service.
+// All implementations must embed UnimplementedInterfacerServer
+// for forward compatibility.
+//
+// Interfacer serves the network interfaces of the host.
+type InterfacerServer interface {
+	// ListInterfaces returns the interfaces, optionally filtered by name.
+	ListInterfaces(context.Context, *ListInterfacesRequest) (*ListInterfacesResponse, error)
+	// GetInterface returns a single interface, or NOT_FOUND if it doesn't exist.
+	GetInterface(context.Context, *GetInterfaceRequest) (*NetworkInterface, error)
+	// GetStats returns the traffic counters of a single interface with the per-second rates since
+	// the previous sample, or NOT_FOUND if the interface doesn't exist or has no counters.
+	GetStats(context.Context, *GetStatsRequest) (*NetworkInterfaceStats, error)
+	// WatchInterfaces streams the change events of the interfaces and, if subscribed to the
+	// stats event type, their traffic counters after every sample, until the client cancels.
+	// Watchers falling too far behind are ended with RESOURCE_EXHAUSTED and have to resume.
+	WatchInterfaces(*WatchInterfacesRequest, grpc.ServerStreamingServer[WatchInterfacesResponse]) error
+	mustEmbedUnimplementedInterfacerServer()
+}
+
+// UnimplementedInterfacerServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedInterfacerServer struct{}
+
+func (UnimplementedInterfacerServer) ListInterfaces(context.Context, *ListInterfacesRequest) (*ListInterfacesResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListInterfaces not implemented")
+}
+func (UnimplementedInterfacerServer) GetInterface(context.Context, *GetInterfaceRequest) (*NetworkInterface, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetInterface not implemented")
+}
+func (UnimplementedInterfacerServer) GetStats(context.Context, *GetStatsRequest) (*NetworkInterfaceStats, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStats not implemented")
+}
+func (UnimplementedInterfacerServer) WatchInterfaces(*WatchInterfacesRequest, grpc.ServerStreamingServer[WatchInterfacesResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method WatchInterfaces not implemented")
+}
+func (UnimplementedInterfacerServer) mustEmbedUnimplementedInterfacerServer() {}
+func (UnimplementedInterfacerServer) testEmbeddedByValue()                    {}
+
+// UnsafeInterfacerServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to InterfacerServer will
+// result in compilation errors.
+type UnsafeInterfacerServer interface {
+	mustEmbedUnimplementedInterfacerServer()
+}
+
+func RegisterInterfacerServer(s grpc.ServiceRegistrar, srv InterfacerServer) {
+	// If the following call pancis, it indicates UnimplementedInterfacerServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Interfacer_ServiceDesc, srv)
+}
+
+func _Interfacer_ListInterfaces_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListInterfacesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InterfacerServer).ListInterfaces(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Interfacer_ListInterfaces_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InterfacerServer).ListInterfaces(ctx, req.(*ListInterfacesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Interfacer_GetInterface_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetInterfaceRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InterfacerServer).GetInterface(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Interfacer_GetInterface_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InterfacerServer).GetInterface(ctx, req.(*GetInterfaceRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Interfacer_GetStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(InterfacerServer).GetStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Interfacer_GetStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(InterfacerServer).GetStats(ctx, req.(*GetStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Interfacer_WatchInterfaces_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(WatchInterfacesRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(InterfacerServer).WatchInterfaces(m, &grpc.GenericServerStream[WatchInterfacesRequest, WatchInterfacesResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Interfacer_WatchInterfacesServer = grpc.ServerStreamingServer[WatchInterfacesResponse]
+
+// Interfacer_ServiceDesc is the grpc.ServiceDesc for Interfacer service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Interfacer_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "interfacer.v1.Interfacer",
+	HandlerType: (*InterfacerServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "ListInterfaces",
+			Handler:    _Interfacer_ListInterfaces_Handler,
+		},
+		{
+			MethodName: "GetInterface",
+			Handler:    _Interfacer_GetInterface_Handler,
+		},
+		{
+			MethodName: "GetStats",
+			Handler:    _Interfacer_GetStats_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "WatchInterfaces",
+			Handler:       _Interfacer_WatchInterfaces_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "interfacer.proto",
+}
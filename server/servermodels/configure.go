@@ -0,0 +1,190 @@
+//go:build linux
+
+package servermodels
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"syscall"
+)
+
+// SetMTU changes the Maximum Transmission Unit of an interface.
+func (c *NetlinkCollector) SetMTU(name string, mtu int) error {
+	return setLink(name, 0, 0, encodeAttr(syscall.IFLA_MTU, binary.NativeEndian.AppendUint32(nil, uint32(mtu))))
+}
+
+// SetAdminStatus brings an interface administratively up or down by changing its IFF_UP flag.
+func (c *NetlinkCollector) SetAdminStatus(name string, up bool) error {
+	var flags uint32
+	if up {
+		flags = syscall.IFF_UP
+	}
+	return setLink(name, flags, syscall.IFF_UP)
+}
+
+// SetAlias changes the description of an interface, an empty alias removes it.
+func (c *NetlinkCollector) SetAlias(name, alias string) error {
+	return setLink(name, 0, 0, encodeAttr(syscall.IFLA_IFALIAS, []byte(alias)))
+}
+
+// AddAddress assigns an IP address to an interface, like `ip address add`.
+func (c *NetlinkCollector) AddAddress(name string, addr *net.IPNet) error {
+	return changeAddress(syscall.RTM_NEWADDR, syscall.NLM_F_CREATE|syscall.NLM_F_EXCL, name, addr)
+}
+
+// DeleteAddress removes an IP address from an interface, like `ip address del`.
+func (c *NetlinkCollector) DeleteAddress(name string, addr *net.IPNet) error {
+	return changeAddress(syscall.RTM_DELADDR, 0, name, addr)
+}
+
+// AddRoute adds a static route through an interface to the main routing table,
+// like `ip route add ... proto static`.
+func (c *NetlinkCollector) AddRoute(name string, dst *net.IPNet, gateway net.IP, metric uint32) error {
+	return changeRoute(syscall.RTM_NEWROUTE, syscall.NLM_F_CREATE|syscall.NLM_F_EXCL, name, dst, gateway, metric)
+}
+
+// DeleteRoute removes a static route through an interface from the main routing table, like `ip route del`.
+func (c *NetlinkCollector) DeleteRoute(name string, dst *net.IPNet, gateway net.IP, metric uint32) error {
+	return changeRoute(syscall.RTM_DELROUTE, 0, name, dst, gateway, metric)
+}
+
+// setLink changes the device flags selected by the change mask and the given attributes of an
+// interface with an RTM_NEWLINK request, like `ip link set`.
+func setLink(name string, flags, change uint32, attrs ...[]byte) error {
+	conn, err := dialNetlink(syscall.NETLINK_ROUTE)
+	if err != nil {
+		return err
+	}
+	defer conn.close()
+
+	index, err := linkIndex(conn, name)
+	if err != nil {
+		return err
+	}
+
+	req := ifInfomsg(index)
+	binary.NativeEndian.PutUint32(req[8:12], flags)
+	binary.NativeEndian.PutUint32(req[12:16], change)
+	for _, a := range attrs {
+		req = append(req, a...)
+	}
+
+	_, err = conn.execute(syscall.RTM_NEWLINK, syscall.NLM_F_ACK, req)
+	return err
+}
+
+// changeAddress adds or deletes an address of an interface with an RTM_NEWADDR or RTM_DELADDR request.
+func changeAddress(msgType, flags uint16, name string, addr *net.IPNet) error {
+	family, ip := syscall.AF_INET6, addr.IP.To16()
+	if ip4 := addr.IP.To4(); ip4 != nil {
+		family, ip = syscall.AF_INET, ip4
+	}
+	prefixLen, bits := addr.Mask.Size()
+	if ip == nil || bits != len(ip)*8 {
+		return syscall.EINVAL
+	}
+
+	conn, err := dialNetlink(syscall.NETLINK_ROUTE)
+	if err != nil {
+		return err
+	}
+	defer conn.close()
+
+	index, err := linkIndex(conn, name)
+	if err != nil {
+		return err
+	}
+
+	req := make([]byte, syscall.SizeofIfAddrmsg)
+	req[0] = uint8(family)
+	req[1] = uint8(prefixLen)
+	binary.NativeEndian.PutUint32(req[4:8], uint32(index))
+	req = append(req, encodeAttr(syscall.IFA_LOCAL, ip)...)
+	req = append(req, encodeAttr(syscall.IFA_ADDRESS, ip)...)
+
+	_, err = conn.execute(msgType, flags|syscall.NLM_F_ACK, req)
+	return err
+}
+
+// changeRoute adds or deletes a static route with an RTM_NEWROUTE or RTM_DELROUTE request.
+func changeRoute(msgType, flags uint16, name string, dst *net.IPNet, gateway net.IP, metric uint32) error {
+	family, ip := uint8(syscall.AF_INET6), dst.IP.To16()
+	if ip4 := dst.IP.To4(); ip4 != nil {
+		family, ip = syscall.AF_INET, ip4
+	}
+	prefixLen, bits := dst.Mask.Size()
+	if ip == nil || bits != len(ip)*8 {
+		return syscall.EINVAL
+	}
+
+	conn, err := dialNetlink(syscall.NETLINK_ROUTE)
+	if err != nil {
+		return err
+	}
+	defer conn.close()
+
+	index, err := linkIndex(conn, name)
+	if err != nil {
+		return err
+	}
+
+	req := rtMsg(family, uint8(prefixLen), 0)
+	req[4] = syscall.RT_TABLE_MAIN
+	req[5] = syscall.RTPROT_STATIC
+	if msgType == syscall.RTM_NEWROUTE {
+		req[6] = syscall.RT_SCOPE_LINK
+		if gateway != nil {
+			req[6] = syscall.RT_SCOPE_UNIVERSE
+		}
+		req[7] = syscall.RTN_UNICAST
+	} else {
+		// Deletions match routes of any scope and type
+		req[6] = syscall.RT_SCOPE_NOWHERE
+	}
+
+	if prefixLen > 0 {
+		req = append(req, encodeAttr(syscall.RTA_DST, ip.Mask(dst.Mask))...)
+	}
+	if gateway != nil {
+		gw := gateway.To16()
+		if family == syscall.AF_INET {
+			gw = gateway.To4()
+		}
+		if gw == nil {
+			return syscall.EINVAL
+		}
+		req = append(req, encodeAttr(syscall.RTA_GATEWAY, gw)...)
+	}
+	req = append(req, encodeAttr(syscall.RTA_OIF, binary.NativeEndian.AppendUint32(nil, uint32(index)))...)
+	req = append(req, encodeAttr(syscall.RTA_PRIORITY, binary.NativeEndian.AppendUint32(nil, metric))...)
+
+	_, err = conn.execute(msgType, flags|syscall.NLM_F_ACK, req)
+	return err
+}
+
+// linkIndex resolves the name of an interface to its index.
+func linkIndex(conn *nlConn, name string) (int, error) {
+	// The kernel rejects names that are too long as invalid, they can't exist either
+	if name == "" || len(name) >= syscall.IFNAMSIZ {
+		return 0, ErrNoSuchInterface
+	}
+
+	msgs, err := conn.execute(syscall.RTM_GETLINK, 0, append(ifInfomsg(0), encodeString(syscall.IFLA_IFNAME, name)...))
+	if errors.Is(err, syscall.ENODEV) {
+		return 0, ErrNoSuchInterface
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	links, err := parseLinks(msgs)
+	if err != nil {
+		return 0, err
+	}
+	if len(links) == 0 {
+		return 0, ErrNoSuchInterface
+	}
+
+	return links[0].Index, nil
+}
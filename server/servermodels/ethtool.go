@@ -0,0 +1,550 @@
+//go:build linux
+
+package servermodels
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"runtime"
+	"sort"
+	"syscall"
+	"unsafe"
+
+	api "apimodule"
+)
+
+// Constants of the ethtool generic netlink family from linux/ethtool_netlink.h.
+const (
+	ethtoolGenlName    = "ethtool" // ETHTOOL_GENL_NAME
+	ethtoolGenlVersion = 1         // ETHTOOL_GENL_VERSION
+
+	ethtoolMsgLinkInfoGet     = 2  // ETHTOOL_MSG_LINKINFO_GET
+	ethtoolMsgLinkModesGet    = 4  // ETHTOOL_MSG_LINKMODES_GET
+	ethtoolMsgFeaturesGet     = 11 // ETHTOOL_MSG_FEATURES_GET
+	ethtoolMsgRingsGet        = 15 // ETHTOOL_MSG_RINGS_GET
+	ethtoolMsgChannelsGet     = 17 // ETHTOOL_MSG_CHANNELS_GET
+	ethtoolMsgPauseGet        = 21 // ETHTOOL_MSG_PAUSE_GET
+	ethtoolMsgModuleEEPROMGet = 31 // ETHTOOL_MSG_MODULE_EEPROM_GET
+
+	ethtoolAHeaderDevName = 2 // ETHTOOL_A_HEADER_DEV_NAME
+
+	// Every request and reply carries the header as its first attribute
+	ethtoolAHeader = 1 // ETHTOOL_A_*_HEADER
+
+	ethtoolALinkInfoPort = 2 // ETHTOOL_A_LINKINFO_PORT
+
+	ethtoolALinkModesAutoneg = 2 // ETHTOOL_A_LINKMODES_AUTONEG
+	ethtoolALinkModesOurs    = 3 // ETHTOOL_A_LINKMODES_OURS
+	ethtoolALinkModesPeer    = 4 // ETHTOOL_A_LINKMODES_PEER
+	ethtoolALinkModesSpeed   = 5 // ETHTOOL_A_LINKMODES_SPEED
+	ethtoolALinkModesDuplex  = 6 // ETHTOOL_A_LINKMODES_DUPLEX
+
+	ethtoolAFeaturesHW       = 2 // ETHTOOL_A_FEATURES_HW
+	ethtoolAFeaturesActive   = 4 // ETHTOOL_A_FEATURES_ACTIVE
+	ethtoolAFeaturesNoChange = 5 // ETHTOOL_A_FEATURES_NOCHANGE
+
+	ethtoolARingsRXMax      = 2 // ETHTOOL_A_RINGS_RX_MAX
+	ethtoolARingsRXMiniMax  = 3 // ETHTOOL_A_RINGS_RX_MINI_MAX
+	ethtoolARingsRXJumboMax = 4 // ETHTOOL_A_RINGS_RX_JUMBO_MAX
+	ethtoolARingsTXMax      = 5 // ETHTOOL_A_RINGS_TX_MAX
+	ethtoolARingsRX         = 6 // ETHTOOL_A_RINGS_RX
+	ethtoolARingsRXMini     = 7 // ETHTOOL_A_RINGS_RX_MINI
+	ethtoolARingsRXJumbo    = 8 // ETHTOOL_A_RINGS_RX_JUMBO
+	ethtoolARingsTX         = 9 // ETHTOOL_A_RINGS_TX
+
+	ethtoolAChannelsRXMax         = 2 // ETHTOOL_A_CHANNELS_RX_MAX
+	ethtoolAChannelsTXMax         = 3 // ETHTOOL_A_CHANNELS_TX_MAX
+	ethtoolAChannelsOtherMax      = 4 // ETHTOOL_A_CHANNELS_OTHER_MAX
+	ethtoolAChannelsCombinedMax   = 5 // ETHTOOL_A_CHANNELS_COMBINED_MAX
+	ethtoolAChannelsRXCount       = 6 // ETHTOOL_A_CHANNELS_RX_COUNT
+	ethtoolAChannelsTXCount       = 7 // ETHTOOL_A_CHANNELS_TX_COUNT
+	ethtoolAChannelsOtherCount    = 8 // ETHTOOL_A_CHANNELS_OTHER_COUNT
+	ethtoolAChannelsCombinedCount = 9 // ETHTOOL_A_CHANNELS_COMBINED_COUNT
+
+	ethtoolAPauseAutoneg = 2 // ETHTOOL_A_PAUSE_AUTONEG
+	ethtoolAPauseRX      = 3 // ETHTOOL_A_PAUSE_RX
+	ethtoolAPauseTX      = 4 // ETHTOOL_A_PAUSE_TX
+
+	ethtoolAModuleEEPROMOffset     = 2 // ETHTOOL_A_MODULE_EEPROM_OFFSET
+	ethtoolAModuleEEPROMLength     = 3 // ETHTOOL_A_MODULE_EEPROM_LENGTH
+	ethtoolAModuleEEPROMPage       = 4 // ETHTOOL_A_MODULE_EEPROM_PAGE
+	ethtoolAModuleEEPROMI2CAddress = 6 // ETHTOOL_A_MODULE_EEPROM_I2C_ADDRESS
+	ethtoolAModuleEEPROMData       = 7 // ETHTOOL_A_MODULE_EEPROM_DATA
+
+	ethtoolABitsetNoMask = 1 // ETHTOOL_A_BITSET_NOMASK
+	ethtoolABitsetBits   = 3 // ETHTOOL_A_BITSET_BITS
+	ethtoolABitsetBit    = 1 // ETHTOOL_A_BITSET_BITS_BIT
+	ethtoolABitsetName   = 2 // ETHTOOL_A_BITSET_BIT_NAME
+	ethtoolABitsetValue  = 3 // ETHTOOL_A_BITSET_BIT_VALUE
+
+	ethtoolSpeedUnknown  = 0xffffffff // SPEED_UNKNOWN
+	ethtoolDuplexHalf    = 0x00       // DUPLEX_HALF
+	ethtoolDuplexFull    = 0x01       // DUPLEX_FULL
+	ethtoolAutonegEnable = 0x01       // AUTONEG_ENABLE
+
+	moduleI2CAddress = 0x50 // I2C address of the module EEPROM, A0h in SFF-8472
+	modulePageSize   = 128  // Size of the lower page and of each upper page
+)
+
+// Constants of the legacy ethtool ioctl interface from linux/sockios.h and linux/ethtool.h.
+const (
+	siocEthtool      = 0x8946 // SIOCETHTOOL
+	ethtoolGDrvinfo  = 0x03   // ETHTOOL_GDRVINFO
+	ethtoolStringLen = 32     // ETHTOOL_BUSINFO_LEN and the length of the other strings
+)
+
+// ethtoolPorts maps PORT_* values to their names as used by the `ethtool` command.
+var ethtoolPorts = map[uint8]string{
+	0x00: "tp",    // PORT_TP
+	0x01: "aui",   // PORT_AUI
+	0x02: "bnc",   // PORT_BNC
+	0x03: "mii",   // PORT_MII
+	0x04: "fibre", // PORT_FIBRE
+	0x05: "da",    // PORT_DA
+	0xef: "none",  // PORT_NONE
+	0xff: "other", // PORT_OTHER
+}
+
+// Ethtool returns the device settings of an interface. The driver is queried through the ethtool
+// ioctl, everything else through the ethtool generic netlink family. Settings the device doesn't
+// support are left out.
+func (c *NetlinkCollector) Ethtool(name string) (*api.Ethtool, error) {
+	result := &api.Ethtool{Name: name}
+
+	// The ioctl fails with ENODEV for unknown interfaces before checking for driver support
+	driver, err := driverInfo(name)
+	switch {
+	case errors.Is(err, syscall.ENODEV):
+		return nil, ErrNoSuchInterface
+	case err == nil:
+		result.Driver = driver
+	}
+
+	// Kernels before 5.6 only report the driver
+	ethtool, err := dialEthtool()
+	if err != nil {
+		return result, nil
+	}
+	defer ethtool.close()
+
+	result.LinkModes, _ = ethtool.linkModeDetails(name)
+	result.Features, _ = ethtool.features(name)
+	result.Rings, _ = ethtool.rings(name)
+	result.Channels, _ = ethtool.channels(name)
+	result.Pause, _ = ethtool.pause(name)
+	result.Module, _ = ethtool.module(name)
+
+	return result, nil
+}
+
+// ethtoolDrvinfo mirrors struct ethtool_drvinfo from linux/ethtool.h.
+type ethtoolDrvinfo struct {
+	cmd         uint32
+	driver      [ethtoolStringLen]byte
+	version     [ethtoolStringLen]byte
+	fwVersion   [ethtoolStringLen]byte
+	busInfo     [ethtoolStringLen]byte
+	eromVersion [ethtoolStringLen]byte
+	_           [12]byte
+	_           [5]uint32 // Counts of private flags, statistics, tests and dump lengths.
+}
+
+// ifreqData mirrors struct ifreq using the ifr_data member of its union, padded to the
+// size of the union on 64-bit architectures.
+type ifreqData struct {
+	name [syscall.IFNAMSIZ]byte
+	data unsafe.Pointer
+	_    [16]byte
+}
+
+// driverInfo queries the driver of an interface with the ETHTOOL_GDRVINFO ioctl, which has
+// no netlink counterpart. Devices without a driver (e.g. loopback) return EOPNOTSUPP.
+func driverInfo(name string) (*api.DriverInfo, error) {
+	var ifr ifreqData
+	if len(name) >= len(ifr.name) {
+		return nil, syscall.ENODEV
+	}
+	copy(ifr.name[:], name)
+
+	fd, err := syscall.Socket(syscall.AF_INET, syscall.SOCK_DGRAM|syscall.SOCK_CLOEXEC, 0)
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+	defer syscall.Close(fd)
+
+	info := &ethtoolDrvinfo{cmd: ethtoolGDrvinfo}
+	ifr.data = unsafe.Pointer(info)
+
+	_, _, errno := syscall.Syscall(syscall.SYS_IOCTL, uintptr(fd), siocEthtool, uintptr(unsafe.Pointer(&ifr)))
+	runtime.KeepAlive(info)
+	if errno != 0 {
+		return nil, os.NewSyscallError("ioctl", errno)
+	}
+
+	return &api.DriverInfo{
+		Driver:          attrString(info.driver[:]),
+		Version:         attrString(info.version[:]),
+		FirmwareVersion: attrString(info.fwVersion[:]),
+		BusInfo:         attrString(info.busInfo[:]),
+	}, nil
+}
+
+// ethtoolClient queries the ethtool generic netlink family.
+type ethtoolClient struct {
+	conn   *nlConn // Generic netlink socket.
+	family uint16  // Resolved ID of the ethtool family.
+}
+
+// dialEthtool opens a generic netlink socket and resolves the ethtool family.
+// It fails on kernels built without ethtool netlink support (before 5.6).
+func dialEthtool() (*ethtoolClient, error) {
+	conn, err := dialNetlink(syscall.NETLINK_GENERIC)
+	if err != nil {
+		return nil, err
+	}
+
+	family, err := conn.resolveFamily(ethtoolGenlName)
+	if err != nil {
+		conn.close()
+		return nil, err
+	}
+
+	return &ethtoolClient{conn: conn, family: family}, nil
+}
+
+// close releases the underlying netlink socket.
+func (e *ethtoolClient) close() error {
+	return e.conn.close()
+}
+
+// get sends an ethtool request for the given interface and returns the attributes of the reply.
+// Devices that don't support the request return EOPNOTSUPP.
+func (e *ethtoolClient) get(cmd uint8, ifaceName string, attrs ...[]byte) ([]nlAttr, error) {
+	header := encodeAttr(ethtoolAHeader|syscall.NLA_F_NESTED, encodeString(ethtoolAHeaderDevName, ifaceName))
+
+	msgs, err := e.conn.execute(e.family, 0, genlMessage(cmd, ethtoolGenlVersion, append([][]byte{header}, attrs...)...))
+	if err != nil {
+		return nil, err
+	}
+	if len(msgs) == 0 || len(msgs[0].Data) < genlHdrLen {
+		return nil, fmt.Errorf("ethtool: empty reply to command %d for %s", cmd, ifaceName)
+	}
+
+	return parseAttrs(msgs[0].Data[genlHdrLen:])
+}
+
+// linkModes returns the speed in Mb/s (-1 if unknown) and the duplex mode ("half", "full"
+// or "unknown") of the given interface.
+// Devices without link settings (e.g. loopback) return EOPNOTSUPP.
+func (e *ethtoolClient) linkModes(ifaceName string) (speed int64, duplex string, err error) {
+	attrs, err := e.get(ethtoolMsgLinkModesGet, ifaceName)
+	if err != nil {
+		return 0, "", err
+	}
+
+	speed, duplex = -1, "unknown"
+	for _, a := range attrs {
+		switch a.Type {
+		case ethtoolALinkModesSpeed:
+			if v := attrUint32(a.Data); v != ethtoolSpeedUnknown {
+				speed = int64(v)
+			}
+		case ethtoolALinkModesDuplex:
+			duplex = duplexName(attrUint8(a.Data))
+		}
+	}
+
+	return speed, duplex, nil
+}
+
+// linkModeDetails returns the connector type, the autonegotiation state and the supported,
+// advertised and link partner link modes of the given interface, along with its speed and duplex.
+func (e *ethtoolClient) linkModeDetails(ifaceName string) (*api.LinkModes, error) {
+	attrs, err := e.get(ethtoolMsgLinkModesGet, ifaceName)
+	if err != nil {
+		return nil, err
+	}
+
+	modes := &api.LinkModes{Duplex: api.DuplexUnknown}
+	for _, a := range attrs {
+		switch a.Type {
+		case ethtoolALinkModesAutoneg:
+			modes.Autoneg = attrUint8(a.Data) == ethtoolAutonegEnable
+		case ethtoolALinkModesOurs:
+			// The mask holds the supported modes, the value the advertised ones
+			if modes.Supported, modes.Advertised, err = parseBitset(a.Data); err != nil {
+				return nil, err
+			}
+		case ethtoolALinkModesPeer:
+			if _, modes.PartnerAdvertised, err = parseBitset(a.Data); err != nil {
+				return nil, err
+			}
+		case ethtoolALinkModesSpeed:
+			if v := attrUint32(a.Data); v != ethtoolSpeedUnknown {
+				speed := int64(v)
+				modes.SpeedMbps = &speed
+			}
+		case ethtoolALinkModesDuplex:
+			modes.Duplex = duplexName(attrUint8(a.Data))
+		}
+	}
+
+	for _, list := range []*[]string{&modes.Supported, &modes.Advertised, &modes.PartnerAdvertised} {
+		if *list == nil {
+			*list = []string{}
+		}
+	}
+
+	// The connector type is part of the link info, which not every device reports
+	if attrs, err := e.get(ethtoolMsgLinkInfoGet, ifaceName); err == nil {
+		for _, a := range attrs {
+			if a.Type == ethtoolALinkInfoPort {
+				modes.Port = lookupName(ethtoolPorts, attrUint8(a.Data))
+			}
+		}
+	}
+
+	return modes, nil
+}
+
+// features returns the offload features of the given interface that are active or can be changed.
+func (e *ethtoolClient) features(ifaceName string) ([]api.Feature, error) {
+	attrs, err := e.get(ethtoolMsgFeaturesGet, ifaceName)
+	if err != nil {
+		return nil, err
+	}
+
+	var changeable, active, noChange []string
+	for _, a := range attrs {
+		switch a.Type {
+		case ethtoolAFeaturesHW:
+			_, changeable, err = parseBitset(a.Data)
+		case ethtoolAFeaturesActive:
+			_, active, err = parseBitset(a.Data)
+		case ethtoolAFeaturesNoChange:
+			_, noChange, err = parseBitset(a.Data)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return newFeatures(changeable, active, noChange), nil
+}
+
+// newFeatures merges the changeable, active and never changeable feature names into the
+// features of a device, sorted by name.
+func newFeatures(changeable, active, noChange []string) []api.Feature {
+	features := make(map[string]*api.Feature)
+	feature := func(name string) *api.Feature {
+		if f, ok := features[name]; ok {
+			return f
+		}
+		f := &api.Feature{Name: name, Fixed: true}
+		features[name] = f
+		return f
+	}
+
+	for _, name := range changeable {
+		feature(name).Fixed = false
+	}
+	for _, name := range active {
+		feature(name).Enabled = true
+	}
+	for _, name := range noChange {
+		if f, ok := features[name]; ok {
+			f.Fixed = true
+		}
+	}
+
+	list := make([]api.Feature, 0, len(features))
+	for _, f := range features {
+		list = append(list, *f)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+
+	return list
+}
+
+// rings returns the DMA ring sizes of the given interface.
+func (e *ethtoolClient) rings(ifaceName string) (*api.RingSizes, error) {
+	attrs, err := e.get(ethtoolMsgRingsGet, ifaceName)
+	if err != nil {
+		return nil, err
+	}
+
+	rings := &api.RingSizes{}
+	fields := map[uint16]*uint32{
+		ethtoolARingsRX:         &rings.RX,
+		ethtoolARingsRXMax:      &rings.RXMax,
+		ethtoolARingsRXMini:     &rings.RXMini,
+		ethtoolARingsRXMiniMax:  &rings.RXMiniMax,
+		ethtoolARingsRXJumbo:    &rings.RXJumbo,
+		ethtoolARingsRXJumboMax: &rings.RXJumboMax,
+		ethtoolARingsTX:         &rings.TX,
+		ethtoolARingsTXMax:      &rings.TXMax,
+	}
+	for _, a := range attrs {
+		if field, ok := fields[a.Type]; ok {
+			*field = attrUint32(a.Data)
+		}
+	}
+
+	return rings, nil
+}
+
+// channels returns the numbers of queues of the given interface.
+func (e *ethtoolClient) channels(ifaceName string) (*api.Channels, error) {
+	attrs, err := e.get(ethtoolMsgChannelsGet, ifaceName)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := &api.Channels{}
+	fields := map[uint16]*uint32{
+		ethtoolAChannelsRXCount:       &channels.RX,
+		ethtoolAChannelsRXMax:         &channels.RXMax,
+		ethtoolAChannelsTXCount:       &channels.TX,
+		ethtoolAChannelsTXMax:         &channels.TXMax,
+		ethtoolAChannelsOtherCount:    &channels.Other,
+		ethtoolAChannelsOtherMax:      &channels.OtherMax,
+		ethtoolAChannelsCombinedCount: &channels.Combined,
+		ethtoolAChannelsCombinedMax:   &channels.CombinedMax,
+	}
+	for _, a := range attrs {
+		if field, ok := fields[a.Type]; ok {
+			*field = attrUint32(a.Data)
+		}
+	}
+
+	return channels, nil
+}
+
+// pause returns the flow control settings of the given interface.
+func (e *ethtoolClient) pause(ifaceName string) (*api.PauseParams, error) {
+	attrs, err := e.get(ethtoolMsgPauseGet, ifaceName)
+	if err != nil {
+		return nil, err
+	}
+
+	pause := &api.PauseParams{}
+	for _, a := range attrs {
+		switch a.Type {
+		case ethtoolAPauseAutoneg:
+			pause.Autoneg = attrUint8(a.Data) != 0
+		case ethtoolAPauseRX:
+			pause.RX = attrUint8(a.Data) != 0
+		case ethtoolAPauseTX:
+			pause.TX = attrUint8(a.Data) != 0
+		}
+	}
+
+	return pause, nil
+}
+
+// module returns the identification of the transceiver module plugged into the given interface.
+// Devices without a module cage return EOPNOTSUPP, empty cages typically EIO or ENODEV.
+func (e *ethtoolClient) module(ifaceName string) (*api.ModuleInfo, error) {
+	lower, err := e.eeprom(ifaceName, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(lower) == 0 {
+		return nil, fmt.Errorf("ethtool: empty module EEPROM of %s", ifaceName)
+	}
+
+	// Only some module types keep their identification on upper page 00h
+	var upper []byte
+	if layout, ok := sffLayouts[lower[0]]; ok && layout.upperPage {
+		if upper, err = e.eeprom(ifaceName, modulePageSize); err != nil {
+			return nil, err
+		}
+	}
+
+	return parseModuleEEPROM(lower, upper), nil
+}
+
+// eeprom reads half a page of the module EEPROM at the given offset of page 00h, 0 for the
+// lower page and 128 for the upper page.
+func (e *ethtoolClient) eeprom(ifaceName string, offset uint32) ([]byte, error) {
+	attrs, err := e.get(ethtoolMsgModuleEEPROMGet, ifaceName,
+		encodeAttr(ethtoolAModuleEEPROMOffset, binary.NativeEndian.AppendUint32(nil, offset)),
+		encodeAttr(ethtoolAModuleEEPROMLength, binary.NativeEndian.AppendUint32(nil, modulePageSize)),
+		encodeAttr(ethtoolAModuleEEPROMPage, []byte{0}),
+		encodeAttr(ethtoolAModuleEEPROMI2CAddress, []byte{moduleI2CAddress}),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, a := range attrs {
+		if a.Type == ethtoolAModuleEEPROMData {
+			return a.Data, nil
+		}
+	}
+	return nil, nil
+}
+
+// parseBitset decodes an ethtool bitset in the verbose form, which lists its bits by name.
+// It returns the names of the bits in the mask and the names of the bits set in the value.
+// Bitsets without a mask only list the bits set in the value, which are returned twice.
+func parseBitset(b []byte) (mask, value []string, err error) {
+	attrs, err := parseAttrs(b)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	noMask := false
+	var bits []nlAttr
+	for _, a := range attrs {
+		switch a.Type {
+		case ethtoolABitsetNoMask:
+			noMask = true
+		case ethtoolABitsetBits:
+			if bits, err = parseAttrs(a.Data); err != nil {
+				return nil, nil, err
+			}
+		}
+	}
+
+	for _, bit := range bits {
+		if bit.Type != ethtoolABitsetBit {
+			continue
+		}
+		attrs, err := parseAttrs(bit.Data)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		name, set := "", noMask
+		for _, a := range attrs {
+			switch a.Type {
+			case ethtoolABitsetName:
+				name = attrString(a.Data)
+			case ethtoolABitsetValue:
+				set = true
+			}
+		}
+
+		mask = append(mask, name)
+		if set {
+			value = append(value, name)
+		}
+	}
+
+	return mask, value, nil
+}
+
+// duplexName returns the name of a DUPLEX_* value.
+func duplexName(duplex uint8) string {
+	switch duplex {
+	case ethtoolDuplexHalf:
+		return api.DuplexHalf
+	case ethtoolDuplexFull:
+		return api.DuplexFull
+	default:
+		return api.DuplexUnknown
+	}
+}
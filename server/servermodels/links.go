@@ -0,0 +1,235 @@
+//go:build linux
+
+package servermodels
+
+import (
+	"encoding/binary"
+	"net"
+	"strconv"
+
+	api "apimodule"
+)
+
+// setLinkInfo fills the kind, the master/slave relationships and the kind-specific details of
+// the interface from its link message. The other links are needed to resolve indexes to names
+// and to find the slaves of bonds and bridges.
+func setLinkInfo(iface *api.NetworkInterfaceV2, link rtLink, links []rtLink) {
+	names := make(map[int]string, len(links))
+	for _, l := range links {
+		names[l.Index] = l.Name
+	}
+
+	iface.Kind = api.KindDevice
+	if link.Kind != "" {
+		iface.Kind = link.Kind
+	}
+
+	iface.Master = names[link.Master]
+
+	var slaves []rtLink
+	for _, l := range links {
+		if l.Master == link.Index && l.Index != link.Index {
+			slaves = append(slaves, l)
+			iface.Slaves = append(iface.Slaves, l.Name)
+		}
+	}
+
+	switch link.Kind {
+	case api.KindBond:
+		iface.Details = &api.LinkDetails{Bond: newBondDetails(link, slaves, names)}
+	case api.KindBridge:
+		iface.Details = &api.LinkDetails{Bridge: newBridgeDetails(link, slaves)}
+	case api.KindVLAN:
+		iface.Details = &api.LinkDetails{VLAN: newVLANDetails(link, names)}
+	case api.KindVXLAN:
+		iface.Details = &api.LinkDetails{VXLAN: newVXLANDetails(link, names)}
+	case api.KindVeth:
+		iface.Details = &api.LinkDetails{Veth: newVethDetails(link, names)}
+	}
+}
+
+// newBondDetails decodes the IFLA_BOND_* attributes of a bond and the IFLA_BOND_SLAVE_*
+// attributes of its slaves.
+func newBondDetails(link rtLink, slaves []rtLink, names map[int]string) *api.BondDetails {
+	details := &api.BondDetails{Slaves: []api.BondSlave{}}
+	for _, a := range link.InfoData {
+		switch a.Type {
+		case iflaBondMode:
+			details.Mode = lookupName(bondModes, attrUint8(a.Data))
+		case iflaBondActiveSlave:
+			details.ActiveSlave = names[int(attrUint32(a.Data))]
+		case iflaBondMiimon:
+			details.MIIMonMs = int(attrUint32(a.Data))
+		}
+	}
+
+	for _, slave := range slaves {
+		if slave.SlaveKind != api.KindBond {
+			continue
+		}
+
+		bondSlave := api.BondSlave{Name: slave.Name}
+		for _, a := range slave.SlaveData {
+			switch a.Type {
+			case iflaBondSlaveState:
+				bondSlave.State = lookupName(bondSlaveStates, attrUint8(a.Data))
+			case iflaBondSlaveMiiStatus:
+				bondSlave.MIIStatus = lookupName(bondMIIStatuses, attrUint8(a.Data))
+			case iflaBondSlaveLinkFailureCount:
+				bondSlave.LinkFailures = attrUint32(a.Data)
+			}
+		}
+		details.Slaves = append(details.Slaves, bondSlave)
+	}
+
+	return details
+}
+
+// newBridgeDetails decodes the IFLA_BR_* attributes of a bridge and the IFLA_BRPORT_*
+// attributes of its ports.
+func newBridgeDetails(link rtLink, ports []rtLink) *api.BridgeDetails {
+	details := &api.BridgeDetails{Ports: []api.BridgePort{}}
+	for _, a := range link.InfoData {
+		switch a.Type {
+		case iflaBrStpState:
+			details.STPEnabled = attrUint32(a.Data) != 0
+		case iflaBrVlanFiltering:
+			details.VLANFiltering = attrUint8(a.Data) != 0
+		}
+	}
+
+	for _, port := range ports {
+		if port.SlaveKind != api.KindBridge {
+			continue
+		}
+
+		bridgePort := api.BridgePort{Name: port.Name}
+		for _, a := range port.SlaveData {
+			if a.Type == iflaBrportState {
+				bridgePort.State = lookupName(bridgePortStates, attrUint8(a.Data))
+			}
+		}
+		details.Ports = append(details.Ports, bridgePort)
+	}
+
+	return details
+}
+
+// newVLANDetails decodes the IFLA_VLAN_* attributes of a VLAN link.
+func newVLANDetails(link rtLink, names map[int]string) *api.VLANDetails {
+	details := &api.VLANDetails{Protocol: "802.1Q"}
+	if !link.LinkNetnsID {
+		details.Parent = names[link.Link]
+	}
+
+	for _, a := range link.InfoData {
+		switch a.Type {
+		case iflaVlanID:
+			details.ID = int(attrUint16(a.Data))
+		case iflaVlanProtocol:
+			// The protocol is an ethertype in network byte order
+			if len(a.Data) >= 2 && binary.BigEndian.Uint16(a.Data) == 0x88a8 {
+				details.Protocol = "802.1ad"
+			}
+		}
+	}
+
+	return details
+}
+
+// newVXLANDetails decodes the IFLA_VXLAN_* attributes of a VXLAN link.
+func newVXLANDetails(link rtLink, names map[int]string) *api.VXLANDetails {
+	details := &api.VXLANDetails{}
+	for _, a := range link.InfoData {
+		switch a.Type {
+		case iflaVxlanID:
+			details.VNI = attrUint32(a.Data)
+		case iflaVxlanGroup, iflaVxlanGroup6:
+			details.Remote = net.IP(a.Data).String()
+		case iflaVxlanLocal, iflaVxlanLocal6:
+			details.Local = net.IP(a.Data).String()
+		case iflaVxlanLink:
+			details.Parent = names[int(attrUint32(a.Data))]
+		case iflaVxlanPort:
+			// The port is in network byte order
+			if len(a.Data) >= 2 {
+				details.Port = int(binary.BigEndian.Uint16(a.Data))
+			}
+		}
+	}
+
+	return details
+}
+
+// newVethDetails returns the peer of a veth link, which is reported in IFLA_LINK.
+func newVethDetails(link rtLink, names map[int]string) *api.VethDetails {
+	details := &api.VethDetails{PeerIndex: link.Link}
+	if !link.LinkNetnsID {
+		details.Peer = names[link.Link]
+	}
+	return details
+}
+
+// lookupName returns the name of a value, or its number if it has none.
+func lookupName(names map[uint8]string, v uint8) string {
+	if name, ok := names[v]; ok {
+		return name
+	}
+	return strconv.Itoa(int(v))
+}
+
+// bondModes maps BOND_MODE_* values to their names as used by the bonding driver.
+var bondModes = map[uint8]string{
+	0: "balance-rr",
+	1: "active-backup",
+	2: "balance-xor",
+	3: "broadcast",
+	4: "802.3ad",
+	5: "balance-tlb",
+	6: "balance-alb",
+}
+
+// bondSlaveStates maps BOND_STATE_* values to their names.
+var bondSlaveStates = map[uint8]string{
+	0: "active", // BOND_STATE_ACTIVE
+	1: "backup", // BOND_STATE_BACKUP
+}
+
+// bondMIIStatuses maps BOND_LINK_* values to their names.
+var bondMIIStatuses = map[uint8]string{
+	0: "up",   // BOND_LINK_UP
+	1: "fail", // BOND_LINK_FAIL
+	2: "down", // BOND_LINK_DOWN
+	3: "back", // BOND_LINK_BACK
+}
+
+// bridgePortStates maps BR_STATE_* values to their names.
+var bridgePortStates = map[uint8]string{
+	0: "disabled",   // BR_STATE_DISABLED
+	1: "listening",  // BR_STATE_LISTENING
+	2: "learning",   // BR_STATE_LEARNING
+	3: "forwarding", // BR_STATE_FORWARDING
+	4: "blocking",   // BR_STATE_BLOCKING
+}
+
+// Kind-specific attribute types of IFLA_INFO_DATA and IFLA_INFO_SLAVE_DATA, from linux/if_link.h.
+const (
+	iflaBondMode                  = 1  // IFLA_BOND_MODE
+	iflaBondActiveSlave           = 2  // IFLA_BOND_ACTIVE_SLAVE
+	iflaBondMiimon                = 3  // IFLA_BOND_MIIMON
+	iflaBondSlaveState            = 1  // IFLA_BOND_SLAVE_STATE
+	iflaBondSlaveMiiStatus        = 2  // IFLA_BOND_SLAVE_MII_STATUS
+	iflaBondSlaveLinkFailureCount = 3  // IFLA_BOND_SLAVE_LINK_FAILURE_COUNT
+	iflaBrStpState                = 5  // IFLA_BR_STP_STATE
+	iflaBrVlanFiltering           = 7  // IFLA_BR_VLAN_FILTERING
+	iflaBrportState               = 1  // IFLA_BRPORT_STATE
+	iflaVlanID                    = 1  // IFLA_VLAN_ID
+	iflaVlanProtocol              = 5  // IFLA_VLAN_PROTOCOL
+	iflaVxlanID                   = 1  // IFLA_VXLAN_ID
+	iflaVxlanGroup                = 2  // IFLA_VXLAN_GROUP
+	iflaVxlanLink                 = 3  // IFLA_VXLAN_LINK
+	iflaVxlanLocal                = 4  // IFLA_VXLAN_LOCAL
+	iflaVxlanPort                 = 15 // IFLA_VXLAN_PORT
+	iflaVxlanGroup6               = 16 // IFLA_VXLAN_GROUP6
+	iflaVxlanLocal6               = 17 // IFLA_VXLAN_LOCAL6
+)
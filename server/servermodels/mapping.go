@@ -0,0 +1,45 @@
+package servermodels
+
+import (
+	"syscall"
+
+	api "apimodule"
+)
+
+// Operational states reported in IFLA_OPERSTATE (RFC 2863, see linux/if.h).
+const (
+	operUnknown        = 0 // IF_OPER_UNKNOWN
+	operNotPresent     = 1 // IF_OPER_NOTPRESENT
+	operDown           = 2 // IF_OPER_DOWN
+	operLowerLayerDown = 3 // IF_OPER_LOWERLAYERDOWN
+	operTesting        = 4 // IF_OPER_TESTING
+	operDormant        = 5 // IF_OPER_DORMANT
+	operUp             = 6 // IF_OPER_UP
+)
+
+// operStatuses maps IFLA_OPERSTATE values to RFC 2863 operational statuses.
+var operStatuses = map[uint8]string{
+	operUnknown:        api.OperStatusUnknown,
+	operNotPresent:     api.OperStatusNotPresent,
+	operDown:           api.OperStatusDown,
+	operLowerLayerDown: api.OperStatusLowerLayerDown,
+	operTesting:        api.OperStatusTesting,
+	operDormant:        api.OperStatusDormant,
+	operUp:             api.OperStatusUp,
+}
+
+// operStatus maps an IFLA_OPERSTATE value to the RFC 2863 operational status.
+func operStatus(state uint8) string {
+	if status, ok := operStatuses[state]; ok {
+		return status
+	}
+	return api.OperStatusUnknown
+}
+
+// adminStatus maps the IFF_UP device flag to the RFC 2863 administrative status.
+func adminStatus(flags uint32) string {
+	if flags&syscall.IFF_UP != 0 {
+		return api.AdminStatusUp
+	}
+	return api.AdminStatusDown
+}
@@ -0,0 +1,132 @@
+//go:build linux
+
+package servermodels
+
+import (
+	"encoding/binary"
+	"fmt"
+	"net"
+	"syscall"
+
+	api "apimodule"
+)
+
+// Neighbors returns the IPv4 (ARP) and IPv6 (NDP) neighbor table entries of all interfaces.
+func (c *NetlinkCollector) Neighbors() ([]api.Neighbor, error) {
+	conn, err := dialNetlink(syscall.NETLINK_ROUTE)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.close()
+
+	names, err := linkNames(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	msgs, err := conn.execute(syscall.RTM_GETNEIGH, syscall.NLM_F_DUMP, ndMsg(syscall.AF_UNSPEC))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseNeighbors(msgs, names)
+}
+
+// ndMsg returns an ndmsg header for the given family.
+func ndMsg(family uint8) []byte {
+	b := make([]byte, sizeofNdMsg)
+	b[0] = family
+	return b
+}
+
+// parseNeighbors decodes the IPv4 and IPv6 entries of RTM_NEWNEIGH messages.
+// The names map interface indexes to interface names.
+func parseNeighbors(msgs []syscall.NetlinkMessage, names map[int]string) ([]api.Neighbor, error) {
+	neighbors := []api.Neighbor{}
+	for _, m := range msgs {
+		if m.Header.Type != syscall.RTM_NEWNEIGH || len(m.Data) < sizeofNdMsg {
+			continue
+		}
+
+		family, _ := familyName(m.Data[0])
+		if family == "" {
+			// Skip bridge forwarding database and other non-IP entries
+			continue
+		}
+
+		neighbor := api.Neighbor{
+			Family:    family,
+			Interface: names[int(int32(binary.NativeEndian.Uint32(m.Data[4:8])))],
+			State:     neighborState(binary.NativeEndian.Uint16(m.Data[8:10])),
+			Flags:     []string{},
+		}
+
+		flags := m.Data[10]
+		for _, f := range neighborFlags {
+			if flags&f.flag != 0 {
+				neighbor.Flags = append(neighbor.Flags, f.name)
+			}
+		}
+
+		attrs, err := parseAttrs(m.Data[sizeofNdMsg:])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, a := range attrs {
+			switch a.Type {
+			case ndaDst:
+				neighbor.Address = net.IP(a.Data).String()
+			case ndaLladdr:
+				neighbor.MACAddress = net.HardwareAddr(a.Data).String()
+			}
+		}
+
+		neighbors = append(neighbors, neighbor)
+	}
+
+	return neighbors, nil
+}
+
+// neighborState returns the name of a NUD_* state, or its number if it is not a single known state.
+func neighborState(state uint16) string {
+	if name, ok := neighborStates[state]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%x", state)
+}
+
+// neighborStates maps NUD_* values (see linux/neighbour.h) to their api names.
+var neighborStates = map[uint16]string{
+	0x00: api.NeighborNone,       // NUD_NONE
+	0x01: api.NeighborIncomplete, // NUD_INCOMPLETE
+	0x02: api.NeighborReachable,  // NUD_REACHABLE
+	0x04: api.NeighborStale,      // NUD_STALE
+	0x08: api.NeighborDelay,      // NUD_DELAY
+	0x10: api.NeighborProbe,      // NUD_PROBE
+	0x20: api.NeighborFailed,     // NUD_FAILED
+	0x40: api.NeighborNoARP,      // NUD_NOARP
+	0x80: api.NeighborPermanent,  // NUD_PERMANENT
+}
+
+// neighborFlags maps NTF_* flags to their names as used by the `ip neigh` command.
+var neighborFlags = []struct {
+	flag uint8
+	name string
+}{
+	{0x01, "use"},          // NTF_USE
+	{0x02, "self"},         // NTF_SELF
+	{0x04, "master"},       // NTF_MASTER
+	{0x08, "proxy"},        // NTF_PROXY
+	{0x10, "extern_learn"}, // NTF_EXT_LEARNED
+	{0x20, "offload"},      // NTF_OFFLOADED
+	{0x40, "sticky"},       // NTF_STICKY
+	{0x80, "router"},       // NTF_ROUTER
+}
+
+// Neighbor table constants the syscall package does not define, from linux/neighbour.h.
+const (
+	sizeofNdMsg = 12 // sizeof(struct ndmsg)
+	ndaDst      = 1  // NDA_DST
+	ndaLladdr   = 2  // NDA_LLADDR
+)
@@ -0,0 +1,306 @@
+//go:build linux
+
+package servermodels
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"sync/atomic"
+	"syscall"
+)
+
+// nlAttr is a single netlink attribute (type-length-value).
+type nlAttr struct {
+	Type uint16 // Attribute type, with the nested/byte-order flags stripped.
+	Data []byte // Attribute payload without padding.
+}
+
+// nlConn is a minimal netlink socket used to talk to rtnetlink and generic netlink families.
+type nlConn struct {
+	fd  int    // Socket file descriptor.
+	pid uint32 // Port ID assigned to the socket by the kernel.
+}
+
+// Netlink socket options and error message constants from linux/netlink.h, which the
+// syscall package does not define.
+const (
+	solNetlink      = 270   // SOL_NETLINK
+	netlinkCapAck   = 10    // NETLINK_CAP_ACK
+	netlinkExtAck   = 11    // NETLINK_EXT_ACK
+	nlmFCapped      = 0x100 // NLM_F_CAPPED
+	nlmFAckTLVs     = 0x200 // NLM_F_ACK_TLVS
+	nlmsgerrAttrMsg = 1     // NLMSGERR_ATTR_MSG
+	sizeofNlMsgerr  = 4 + syscall.NLMSG_HDRLEN
+)
+
+// netlinkError is an error reported by the kernel along with its explanation (extended ACK),
+// e.g. "MTU greater than device maximum" for EINVAL. It unwraps to the syscall.Errno.
+type netlinkError struct {
+	errno   syscall.Errno
+	message string
+}
+
+// Error returns the explanation of the kernel followed by the description of the errno.
+func (e *netlinkError) Error() string {
+	return e.message + ": " + e.errno.Error()
+}
+
+// Unwrap returns the errno, so that the error matches it with errors.Is.
+func (e *netlinkError) Unwrap() error {
+	return e.errno
+}
+
+// nlSeq is shared by all sockets so that sequence numbers stay unique within the process.
+var nlSeq uint32
+
+// dialNetlink opens a netlink socket for the given protocol (e.g. syscall.NETLINK_ROUTE).
+func dialNetlink(protocol int) (*nlConn, error) {
+	fd, err := syscall.Socket(syscall.AF_NETLINK, syscall.SOCK_RAW|syscall.SOCK_CLOEXEC, protocol)
+	if err != nil {
+		return nil, os.NewSyscallError("socket", err)
+	}
+
+	if err := syscall.Bind(fd, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("bind", err)
+	}
+
+	sa, err := syscall.Getsockname(fd)
+	if err != nil {
+		syscall.Close(fd)
+		return nil, os.NewSyscallError("getsockname", err)
+	}
+
+	// Ask for the explanations of errors without the echoed request, kernels before 4.12
+	// don't support this and only report the errno
+	syscall.SetsockoptInt(fd, solNetlink, netlinkExtAck, 1)
+	syscall.SetsockoptInt(fd, solNetlink, netlinkCapAck, 1)
+
+	return &nlConn{fd: fd, pid: sa.(*syscall.SockaddrNetlink).Pid}, nil
+}
+
+// close releases the netlink socket.
+func (c *nlConn) close() error {
+	return syscall.Close(c.fd)
+}
+
+// execute sends a single request and collects every reply message belonging to it.
+// Dump requests are read until NLMSG_DONE; other requests until the first reply or ACK.
+// A negative errno reported by the kernel is returned as a syscall.Errno, or as a netlinkError
+// wrapping it if the kernel explained the error.
+func (c *nlConn) execute(msgType, flags uint16, payload []byte) ([]syscall.NetlinkMessage, error) {
+	seq := atomic.AddUint32(&nlSeq, 1)
+
+	req := make([]byte, syscall.NLMSG_HDRLEN, syscall.NLMSG_HDRLEN+len(payload))
+	binary.NativeEndian.PutUint32(req[0:4], uint32(syscall.NLMSG_HDRLEN+len(payload)))
+	binary.NativeEndian.PutUint16(req[4:6], msgType)
+	binary.NativeEndian.PutUint16(req[6:8], syscall.NLM_F_REQUEST|flags)
+	binary.NativeEndian.PutUint32(req[8:12], seq)
+	binary.NativeEndian.PutUint32(req[12:16], c.pid)
+	req = append(req, payload...)
+
+	if err := syscall.Sendto(c.fd, req, 0, &syscall.SockaddrNetlink{Family: syscall.AF_NETLINK}); err != nil {
+		return nil, os.NewSyscallError("sendto", err)
+	}
+
+	dump := flags&syscall.NLM_F_DUMP == syscall.NLM_F_DUMP
+	buf := make([]byte, 32*1024)
+	var replies []syscall.NetlinkMessage
+
+	for {
+		n, _, err := syscall.Recvfrom(c.fd, buf, 0)
+		if err != nil {
+			return nil, os.NewSyscallError("recvfrom", err)
+		}
+		if n < syscall.NLMSG_HDRLEN {
+			return nil, errors.New("netlink: short read")
+		}
+
+		msgs, err := syscall.ParseNetlinkMessage(buf[:n])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, m := range msgs {
+			if m.Header.Seq != seq || m.Header.Pid != c.pid {
+				continue
+			}
+
+			switch m.Header.Type {
+			case syscall.NLMSG_DONE:
+				return replies, nil
+			case syscall.NLMSG_ERROR:
+				if len(m.Data) < 4 {
+					return nil, errors.New("netlink: malformed error message")
+				}
+				if errno := -int32(binary.NativeEndian.Uint32(m.Data[0:4])); errno != 0 {
+					return nil, parseError(m, syscall.Errno(errno))
+				}
+				// A zero error code is an ACK and terminates the request.
+				return replies, nil
+			}
+
+			// Copy the payload as the receive buffer is reused for the next read.
+			m.Data = append([]byte(nil), m.Data...)
+			replies = append(replies, m)
+
+			if !dump && m.Header.Flags&syscall.NLM_F_MULTI == 0 {
+				return replies, nil
+			}
+		}
+	}
+}
+
+// parseError returns the errno of an NLMSG_ERROR message, wrapped in a netlinkError if
+// the kernel explained it in an NLMSGERR_ATTR_MSG attribute.
+func parseError(m syscall.NetlinkMessage, errno syscall.Errno) error {
+	if m.Header.Flags&nlmFAckTLVs == 0 || len(m.Data) < sizeofNlMsgerr {
+		return errno
+	}
+
+	// The TLVs follow the echoed request, which is only its header if capped
+	offset := sizeofNlMsgerr
+	if m.Header.Flags&nlmFCapped == 0 {
+		offset = 4 + nlAlign(int(binary.NativeEndian.Uint32(m.Data[4:8])))
+	}
+	if offset > len(m.Data) {
+		return errno
+	}
+
+	attrs, err := parseAttrs(m.Data[offset:])
+	if err != nil {
+		return errno
+	}
+	for _, a := range attrs {
+		if a.Type == nlmsgerrAttrMsg {
+			if message := attrString(a.Data); message != "" {
+				return &netlinkError{errno: errno, message: message}
+			}
+		}
+	}
+
+	return errno
+}
+
+// nlAlign rounds a length up to the netlink 4-byte alignment.
+func nlAlign(n int) int {
+	return (n + syscall.NLA_ALIGNTO - 1) &^ (syscall.NLA_ALIGNTO - 1)
+}
+
+// parseAttrs splits a buffer into its netlink attributes.
+func parseAttrs(b []byte) ([]nlAttr, error) {
+	var attrs []nlAttr
+	for len(b) >= syscall.SizeofRtAttr {
+		l := int(binary.NativeEndian.Uint16(b[0:2]))
+		t := binary.NativeEndian.Uint16(b[2:4])
+		if l < syscall.SizeofRtAttr || l > len(b) {
+			return nil, fmt.Errorf("netlink: invalid attribute length %d", l)
+		}
+
+		attrs = append(attrs, nlAttr{
+			Type: t & 0x3fff, // Strip NLA_F_NESTED and NLA_F_NET_BYTEORDER.
+			Data: b[syscall.SizeofRtAttr:l],
+		})
+
+		if nlAlign(l) >= len(b) {
+			break
+		}
+		b = b[nlAlign(l):]
+	}
+
+	return attrs, nil
+}
+
+// encodeAttr serializes a single netlink attribute including its padding.
+func encodeAttr(t uint16, data []byte) []byte {
+	l := syscall.SizeofRtAttr + len(data)
+	b := make([]byte, nlAlign(l))
+	binary.NativeEndian.PutUint16(b[0:2], uint16(l))
+	binary.NativeEndian.PutUint16(b[2:4], t)
+	copy(b[syscall.SizeofRtAttr:], data)
+	return b
+}
+
+// encodeString serializes a NUL-terminated string attribute.
+func encodeString(t uint16, s string) []byte {
+	return encodeAttr(t, append([]byte(s), 0))
+}
+
+// attrString decodes a NUL-terminated string attribute.
+func attrString(b []byte) string {
+	for i, c := range b {
+		if c == 0 {
+			return string(b[:i])
+		}
+	}
+	return string(b)
+}
+
+// attrUint8 decodes an 8-bit unsigned attribute.
+func attrUint8(b []byte) uint8 {
+	if len(b) < 1 {
+		return 0
+	}
+	return b[0]
+}
+
+// attrUint16 decodes a 16-bit unsigned attribute in host byte order.
+func attrUint16(b []byte) uint16 {
+	if len(b) < 2 {
+		return 0
+	}
+	return binary.NativeEndian.Uint16(b)
+}
+
+// attrUint32 decodes a 32-bit unsigned attribute in host byte order.
+func attrUint32(b []byte) uint32 {
+	if len(b) < 4 {
+		return 0
+	}
+	return binary.NativeEndian.Uint32(b)
+}
+
+// Generic netlink controller constants from linux/genetlink.h.
+const (
+	genlIDCtrl         = 0x10 // GENL_ID_CTRL
+	genlHdrLen         = 4    // GENL_HDRLEN
+	ctrlCmdGetFamily   = 3    // CTRL_CMD_GETFAMILY
+	ctrlAttrFamilyID   = 1    // CTRL_ATTR_FAMILY_ID
+	ctrlAttrFamilyName = 2    // CTRL_ATTR_FAMILY_NAME
+)
+
+// genlMessage builds a generic netlink payload: the genlmsghdr followed by the encoded attributes.
+func genlMessage(cmd, version uint8, attrs ...[]byte) []byte {
+	b := []byte{cmd, version, 0, 0}
+	for _, a := range attrs {
+		b = append(b, a...)
+	}
+	return b
+}
+
+// resolveFamily looks up the numeric ID of a generic netlink family by its name.
+func (c *nlConn) resolveFamily(name string) (uint16, error) {
+	msgs, err := c.execute(genlIDCtrl, 0, genlMessage(ctrlCmdGetFamily, 1, encodeString(ctrlAttrFamilyName, name)))
+	if err != nil {
+		return 0, fmt.Errorf("resolving generic netlink family %q: %w", name, err)
+	}
+
+	for _, m := range msgs {
+		if len(m.Data) < genlHdrLen {
+			continue
+		}
+		attrs, err := parseAttrs(m.Data[genlHdrLen:])
+		if err != nil {
+			return 0, err
+		}
+		for _, a := range attrs {
+			if a.Type == ctrlAttrFamilyID {
+				return attrUint16(a.Data), nil
+			}
+		}
+	}
+
+	return 0, fmt.Errorf("generic netlink family %q not found", name)
+}
@@ -0,0 +1,615 @@
+//go:build linux
+
+package servermodels
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"os"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	"testing"
+
+	api "apimodule"
+)
+
+// TestNewAddress tests the conversion of netlink address messages to typed addresses.
+func TestNewAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		addr     rtAddr
+		expected api.Address
+	}{
+		{
+			name:     "IPv4",
+			addr:     rtAddr{Family: syscall.AF_INET, PrefixLen: 24, Scope: 0, Flags: 0x80, IP: net.ParseIP("192.168.1.10").To4()},
+			expected: api.Address{Address: "192.168.1.10", PrefixLength: 24, Family: api.FamilyIPv4, Scope: "global", Flags: []string{"permanent"}},
+		},
+		{
+			name:     "IPv6",
+			addr:     rtAddr{Family: syscall.AF_INET6, PrefixLen: 64, Scope: 253, Flags: 0x40 | 0x80, IP: net.ParseIP("fe80::1")},
+			expected: api.Address{Address: "fe80::1", PrefixLength: 64, Family: api.FamilyIPv6, Scope: "link", Flags: []string{"tentative", "permanent"}},
+		},
+		{
+			name:     "UnknownScope",
+			addr:     rtAddr{Family: syscall.AF_INET, PrefixLen: 8, Scope: 100, IP: net.ParseIP("10.0.0.1").To4()},
+			expected: api.Address{Address: "10.0.0.1", PrefixLength: 8, Family: api.FamilyIPv4, Scope: "100", Flags: []string{}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := newAddress(test.addr); !reflect.DeepEqual(got, test.expected) {
+				t.Errorf("address mismatch: got %+v, want %+v", got, test.expected)
+			}
+		})
+	}
+}
+
+// TestParseAttrs tests that encoded netlink attributes are decoded back to the same values.
+func TestParseAttrs(t *testing.T) {
+	b := append(encodeString(syscall.IFLA_IFNAME, "eth0"), encodeAttr(syscall.IFLA_MTU, binary.NativeEndian.AppendUint32(nil, 1500))...)
+
+	attrs, err := parseAttrs(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(attrs) != 2 {
+		t.Fatalf("unexpected number of attributes: got %d, want 2", len(attrs))
+	}
+	if name := attrString(attrs[0].Data); name != "eth0" {
+		t.Errorf("name mismatch: got %q, want %q", name, "eth0")
+	}
+	if mtu := attrUint32(attrs[1].Data); mtu != 1500 {
+		t.Errorf("MTU mismatch: got %d, want %d", mtu, 1500)
+	}
+}
+
+// TestParseError tests that the explanation of the kernel is extracted from the extended ACK
+// of an NLMSG_ERROR message, whether or not the echoed request is capped to its header.
+func TestParseError(t *testing.T) {
+	const explanation = "MTU greater than device maximum"
+
+	// errorMessage builds an NLMSG_ERROR message echoing a request with the given payload.
+	errorMessage := func(flags uint16, payload []byte, tlvs []byte) syscall.NetlinkMessage {
+		errno := -int32(syscall.EINVAL)
+		data := binary.NativeEndian.AppendUint32(nil, uint32(errno))
+		data = binary.NativeEndian.AppendUint32(data, uint32(syscall.NLMSG_HDRLEN+len(payload)))
+		data = append(data, make([]byte, syscall.NLMSG_HDRLEN-4)...)
+		data = append(data, payload...)
+		data = append(data, tlvs...)
+		return syscall.NetlinkMessage{Header: syscall.NlMsghdr{Type: syscall.NLMSG_ERROR, Flags: flags}, Data: data}
+	}
+	request := append(make([]byte, syscall.SizeofIfInfomsg), encodeAttr(syscall.IFLA_MTU, binary.NativeEndian.AppendUint32(nil, 65536))...)
+	tlvs := encodeString(nlmsgerrAttrMsg, explanation)
+
+	tests := []struct {
+		name            string
+		message         syscall.NetlinkMessage
+		expectedMessage string
+	}{
+		{name: "Capped", message: errorMessage(nlmFCapped|nlmFAckTLVs, nil, tlvs), expectedMessage: explanation + ": invalid argument"},
+		{name: "Uncapped", message: errorMessage(nlmFAckTLVs, request, tlvs), expectedMessage: explanation + ": invalid argument"},
+		{name: "NoTLVs", message: errorMessage(nlmFCapped, nil, nil), expectedMessage: "invalid argument"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := parseError(test.message, syscall.EINVAL)
+			if !errors.Is(err, syscall.EINVAL) {
+				t.Errorf("error doesn't match EINVAL: %v", err)
+			}
+			if err.Error() != test.expectedMessage {
+				t.Errorf("message mismatch: got %q, want %q", err.Error(), test.expectedMessage)
+			}
+		})
+	}
+}
+
+// TestParseRoutes tests the decoding of RTM_NEWROUTE messages, including multipath routes.
+func TestParseRoutes(t *testing.T) {
+	names := map[int]string{2: "eth0", 3: "eth1"}
+
+	// Default route via a gateway, from a dhcp client
+	defaultRoute := []byte{syscall.AF_INET, 0, 0, 0, syscall.RT_TABLE_MAIN, syscall.RTPROT_DHCP, syscall.RT_SCOPE_UNIVERSE, syscall.RTN_UNICAST, 0, 0, 0, 0}
+	defaultRoute = append(defaultRoute, encodeAttr(syscall.RTA_GATEWAY, net.ParseIP("192.168.1.1").To4())...)
+	defaultRoute = append(defaultRoute, encodeAttr(syscall.RTA_OIF, binary.NativeEndian.AppendUint32(nil, 2))...)
+	defaultRoute = append(defaultRoute, encodeAttr(syscall.RTA_PRIORITY, binary.NativeEndian.AppendUint32(nil, 100))...)
+	defaultRoute = append(defaultRoute, encodeAttr(syscall.RTA_TABLE, binary.NativeEndian.AppendUint32(nil, syscall.RT_TABLE_MAIN))...)
+
+	// IPv6 multipath route in table 1000, which only fits into RTA_TABLE
+	nexthop := func(ifindex uint32, hops uint8, gateway string) []byte {
+		attr := encodeAttr(syscall.RTA_GATEWAY, net.ParseIP(gateway))
+		b := binary.NativeEndian.AppendUint16(nil, uint16(syscall.SizeofRtNexthop+len(attr)))
+		b = append(b, 0, hops)
+		b = binary.NativeEndian.AppendUint32(b, ifindex)
+		return append(b, attr...)
+	}
+	multipath := []byte{syscall.AF_INET6, 48, 0, 0, syscall.RT_TABLE_COMPAT, syscall.RTPROT_STATIC, syscall.RT_SCOPE_UNIVERSE, syscall.RTN_UNICAST, 0, 0, 0, 0}
+	multipath = append(multipath, encodeAttr(syscall.RTA_DST, net.ParseIP("2001:db8::"))...)
+	multipath = append(multipath, encodeAttr(syscall.RTA_TABLE, binary.NativeEndian.AppendUint32(nil, 1000))...)
+	multipath = append(multipath, encodeAttr(syscall.RTA_MULTIPATH, append(nexthop(2, 0, "fe80::1"), nexthop(3, 2, "fe80::2")...))...)
+
+	msgs := []syscall.NetlinkMessage{
+		{Header: syscall.NlMsghdr{Type: syscall.RTM_NEWROUTE}, Data: defaultRoute},
+		{Header: syscall.NlMsghdr{Type: syscall.RTM_NEWROUTE}, Data: multipath},
+		// MPLS routes are skipped
+		{Header: syscall.NlMsghdr{Type: syscall.RTM_NEWROUTE}, Data: []byte{28, 20, 0, 0, syscall.RT_TABLE_MAIN, 0, 0, 1, 0, 0, 0, 0}},
+	}
+
+	expected := []api.Route{
+		{
+			Family:      api.FamilyIPv4,
+			Destination: "0.0.0.0/0",
+			Gateway:     "192.168.1.1",
+			Interface:   "eth0",
+			Metric:      100,
+			Protocol:    "dhcp",
+			Scope:       "global",
+			Table:       "main",
+			Type:        "unicast",
+		},
+		{
+			Family:      api.FamilyIPv6,
+			Destination: "2001:db8::/48",
+			Protocol:    "static",
+			Scope:       "global",
+			Table:       "1000",
+			Type:        "unicast",
+			Nexthops: []api.Nexthop{
+				{Gateway: "fe80::1", Interface: "eth0", Weight: 1},
+				{Gateway: "fe80::2", Interface: "eth1", Weight: 3},
+			},
+		},
+	}
+
+	routes, err := parseRoutes(msgs, names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(routes, expected) {
+		t.Errorf("routes mismatch: got %+v, want %+v", routes, expected)
+	}
+}
+
+// TestParseRules tests the decoding of RTM_NEWRULE messages.
+func TestParseRules(t *testing.T) {
+	// from 10.0.0.0/8 fwmark 0x1/0xff lookup 100
+	marked := []byte{syscall.AF_INET, 0, 8, 0, 100, 0, 0, 1, 0, 0, 0, 0}
+	marked = append(marked, encodeAttr(fraSrc, net.ParseIP("10.0.0.0").To4())...)
+	marked = append(marked, encodeAttr(fraPriority, binary.NativeEndian.AppendUint32(nil, 1000))...)
+	marked = append(marked, encodeAttr(fraFwmark, binary.NativeEndian.AppendUint32(nil, 0x1))...)
+	marked = append(marked, encodeAttr(fraFwmask, binary.NativeEndian.AppendUint32(nil, 0xff))...)
+	marked = append(marked, encodeAttr(fraTable, binary.NativeEndian.AppendUint32(nil, 100))...)
+
+	// not iif eth0 prohibit
+	prohibit := []byte{syscall.AF_INET6, 0, 0, 0, 0, 0, 0, 8, fibRuleInvert, 0, 0, 0}
+	prohibit = append(prohibit, encodeString(fraIifname, "eth0")...)
+	prohibit = append(prohibit, encodeAttr(fraPriority, binary.NativeEndian.AppendUint32(nil, 2000))...)
+
+	msgs := []syscall.NetlinkMessage{
+		{Header: syscall.NlMsghdr{Type: syscall.RTM_NEWRULE}, Data: marked},
+		{Header: syscall.NlMsghdr{Type: syscall.RTM_NEWRULE}, Data: prohibit},
+	}
+
+	expected := []api.Rule{
+		{Family: api.FamilyIPv4, Priority: 1000, Source: "10.0.0.0/8", FwMark: 0x1, FwMask: 0xff, Action: "lookup", Table: "100"},
+		{Family: api.FamilyIPv6, Priority: 2000, InputInterface: "eth0", Invert: true, Action: "prohibit"},
+	}
+
+	rules, err := parseRules(msgs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rules, expected) {
+		t.Errorf("rules mismatch: got %+v, want %+v", rules, expected)
+	}
+}
+
+// TestParseNeighbors tests the decoding of RTM_NEWNEIGH messages for ARP and NDP entries.
+func TestParseNeighbors(t *testing.T) {
+	names := map[int]string{2: "eth0"}
+
+	// ndmsg: family, padding, ifindex, state, flags, type
+	ndmsg := func(family uint8, ifindex uint32, state uint16, flags uint8) []byte {
+		b := []byte{family, 0, 0, 0}
+		b = binary.NativeEndian.AppendUint32(b, ifindex)
+		b = binary.NativeEndian.AppendUint16(b, state)
+		return append(b, flags, 0)
+	}
+
+	arp := append(ndmsg(syscall.AF_INET, 2, 0x02, 0), encodeAttr(ndaDst, net.ParseIP("192.168.1.1").To4())...)
+	arp = append(arp, encodeAttr(ndaLladdr, []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55})...)
+
+	ndp := append(ndmsg(syscall.AF_INET6, 2, 0x04, 0x80), encodeAttr(ndaDst, net.ParseIP("fe80::1"))...)
+	ndp = append(ndp, encodeAttr(ndaLladdr, []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x66})...)
+
+	// Unresolved entries carry no link layer address
+	failed := append(ndmsg(syscall.AF_INET, 2, 0x20, 0), encodeAttr(ndaDst, net.ParseIP("192.168.1.99").To4())...)
+
+	msgs := []syscall.NetlinkMessage{
+		{Header: syscall.NlMsghdr{Type: syscall.RTM_NEWNEIGH}, Data: arp},
+		{Header: syscall.NlMsghdr{Type: syscall.RTM_NEWNEIGH}, Data: ndp},
+		{Header: syscall.NlMsghdr{Type: syscall.RTM_NEWNEIGH}, Data: failed},
+		// Bridge forwarding database entries are skipped
+		{Header: syscall.NlMsghdr{Type: syscall.RTM_NEWNEIGH}, Data: ndmsg(syscall.AF_BRIDGE, 2, 0x80, 0x02)},
+	}
+
+	expected := []api.Neighbor{
+		{Family: api.FamilyIPv4, Address: "192.168.1.1", MACAddress: "00:11:22:33:44:55", Interface: "eth0", State: api.NeighborReachable, Flags: []string{}},
+		{Family: api.FamilyIPv6, Address: "fe80::1", MACAddress: "00:11:22:33:44:66", Interface: "eth0", State: api.NeighborStale, Flags: []string{"router"}},
+		{Family: api.FamilyIPv4, Address: "192.168.1.99", Interface: "eth0", State: api.NeighborFailed, Flags: []string{}},
+	}
+
+	neighbors, err := parseNeighbors(msgs, names)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(neighbors, expected) {
+		t.Errorf("neighbors mismatch: got %+v, want %+v", neighbors, expected)
+	}
+}
+
+// TestSetLinkInfo tests the kinds, relationships and details of bonds, bridges, VLANs, VXLANs and veths.
+func TestSetLinkInfo(t *testing.T) {
+	u8 := func(v uint8) []byte { return []byte{v} }
+	u16 := func(v uint16) []byte { return binary.NativeEndian.AppendUint16(nil, v) }
+	u32 := func(v uint32) []byte { return binary.NativeEndian.AppendUint32(nil, v) }
+	be16 := func(v uint16) []byte { return binary.BigEndian.AppendUint16(nil, v) }
+
+	links := []rtLink{
+		{Index: 1, Name: "eth0", Master: 3, SlaveKind: "bond", SlaveData: []nlAttr{
+			{iflaBondSlaveState, u8(0)}, {iflaBondSlaveMiiStatus, u8(0)}, {iflaBondSlaveLinkFailureCount, u32(1)},
+		}},
+		{Index: 2, Name: "eth1", Master: 3, SlaveKind: "bond", SlaveData: []nlAttr{
+			{iflaBondSlaveState, u8(1)}, {iflaBondSlaveMiiStatus, u8(2)}, {iflaBondSlaveLinkFailureCount, u32(4)},
+		}},
+		{Index: 3, Name: "bond0", Master: 4, Kind: "bond", InfoData: []nlAttr{
+			{iflaBondMode, u8(1)}, {iflaBondActiveSlave, u32(1)}, {iflaBondMiimon, u32(100)},
+		}, SlaveKind: "bridge", SlaveData: []nlAttr{{iflaBrportState, u8(3)}}},
+		{Index: 4, Name: "br0", Kind: "bridge", InfoData: []nlAttr{
+			{iflaBrStpState, u32(1)}, {iflaBrVlanFiltering, u8(0)},
+		}},
+		{Index: 5, Name: "bond0.100", Link: 3, Kind: "vlan", InfoData: []nlAttr{
+			{iflaVlanID, u16(100)}, {iflaVlanProtocol, be16(0x8100)},
+		}},
+		{Index: 6, Name: "vxlan42", Kind: "vxlan", InfoData: []nlAttr{
+			{iflaVxlanID, u32(42)}, {iflaVxlanGroup, net.ParseIP("10.0.0.2").To4()}, {iflaVxlanLocal, net.ParseIP("10.0.0.1").To4()},
+			{iflaVxlanLink, u32(5)}, {iflaVxlanPort, be16(4789)},
+		}},
+		{Index: 7, Name: "veth0", Link: 8, Kind: "veth"},
+		{Index: 8, Name: "veth1", Link: 7, Kind: "veth"},
+		// The peer of a veth in another network namespace can't be resolved to a name
+		{Index: 9, Name: "veth2", Link: 2, LinkNetnsID: true, Kind: "veth"},
+	}
+
+	tests := []struct {
+		name     string
+		expected api.NetworkInterfaceV2
+	}{
+		{
+			name:     "eth0",
+			expected: api.NetworkInterfaceV2{Kind: api.KindDevice, Master: "bond0"},
+		},
+		{
+			name: "bond0",
+			expected: api.NetworkInterfaceV2{Kind: api.KindBond, Master: "br0", Slaves: []string{"eth0", "eth1"}, Details: &api.LinkDetails{Bond: &api.BondDetails{
+				Mode:        "active-backup",
+				ActiveSlave: "eth0",
+				MIIMonMs:    100,
+				Slaves: []api.BondSlave{
+					{Name: "eth0", State: "active", MIIStatus: "up", LinkFailures: 1},
+					{Name: "eth1", State: "backup", MIIStatus: "down", LinkFailures: 4},
+				},
+			}}},
+		},
+		{
+			name: "br0",
+			expected: api.NetworkInterfaceV2{Kind: api.KindBridge, Slaves: []string{"bond0"}, Details: &api.LinkDetails{Bridge: &api.BridgeDetails{
+				STPEnabled: true,
+				Ports:      []api.BridgePort{{Name: "bond0", State: "forwarding"}},
+			}}},
+		},
+		{
+			name:     "bond0.100",
+			expected: api.NetworkInterfaceV2{Kind: api.KindVLAN, Details: &api.LinkDetails{VLAN: &api.VLANDetails{ID: 100, Protocol: "802.1Q", Parent: "bond0"}}},
+		},
+		{
+			name:     "vxlan42",
+			expected: api.NetworkInterfaceV2{Kind: api.KindVXLAN, Details: &api.LinkDetails{VXLAN: &api.VXLANDetails{VNI: 42, Remote: "10.0.0.2", Local: "10.0.0.1", Port: 4789, Parent: "bond0.100"}}},
+		},
+		{
+			name:     "veth0",
+			expected: api.NetworkInterfaceV2{Kind: api.KindVeth, Details: &api.LinkDetails{Veth: &api.VethDetails{PeerIndex: 8, Peer: "veth1"}}},
+		},
+		{
+			name:     "veth2",
+			expected: api.NetworkInterfaceV2{Kind: api.KindVeth, Details: &api.LinkDetails{Veth: &api.VethDetails{PeerIndex: 2}}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var iface api.NetworkInterfaceV2
+			for _, link := range links {
+				if link.Name == test.name {
+					setLinkInfo(&iface, link, links)
+				}
+			}
+
+			if !reflect.DeepEqual(iface, test.expected) {
+				t.Errorf("link info mismatch: got %+v, want %+v", iface, test.expected)
+			}
+		})
+	}
+}
+
+// TestParseWireless tests the decoding of nl80211 interface and station messages.
+func TestParseWireless(t *testing.T) {
+	u32 := func(v uint32) []byte { return binary.NativeEndian.AppendUint32(nil, v) }
+	genl := func(cmd uint8, attrs ...[]byte) syscall.NetlinkMessage {
+		return syscall.NetlinkMessage{Data: genlMessage(cmd, nl80211GenlVersion, attrs...)}
+	}
+
+	// A station connected to an access point on channel 36 and a monitor interface
+	interfaces, err := parseWirelessInterfaces([]syscall.NetlinkMessage{
+		genl(7, encodeAttr(nl80211AttrIfindex, u32(3)), encodeAttr(nl80211AttrIftype, u32(nl80211IftypeStation)),
+			encodeAttr(nl80211AttrSSID, []byte("robots")), encodeAttr(nl80211AttrWiphyFreq, u32(5180))),
+		genl(7, encodeAttr(nl80211AttrIfindex, u32(4)), encodeAttr(nl80211AttrIftype, u32(6))),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	txBitrate := append(encodeAttr(nl80211RateInfoBitrate, binary.NativeEndian.AppendUint16(nil, 1000)), encodeAttr(nl80211RateInfoBitrate32, u32(8667))...)
+	rxBitrate := encodeAttr(nl80211RateInfoBitrate, binary.NativeEndian.AppendUint16(nil, 2400))
+	staInfo := append(encodeAttr(nl80211StaInfoSignal, []byte{0xc4}), encodeAttr(nl80211StaInfoTxBitrate|syscall.NLA_F_NESTED, txBitrate)...)
+	staInfo = append(staInfo, encodeAttr(nl80211StaInfoRxBitrate|syscall.NLA_F_NESTED, rxBitrate)...)
+	staInfo = append(staInfo, encodeAttr(nl80211StaInfoConnectedTime, u32(3600))...)
+
+	err = parseStation(interfaces[3], []syscall.NetlinkMessage{
+		genl(19, encodeAttr(nl80211AttrIfindex, u32(3)), encodeAttr(nl80211AttrMAC, []byte{0x00, 0x11, 0x22, 0x33, 0x44, 0x55}),
+			encodeAttr(nl80211AttrStaInfo|syscall.NLA_F_NESTED, staInfo)),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	signal := -60
+	expected := map[int]*api.Wireless{
+		3: {
+			Mode:             "station",
+			Connected:        true,
+			SSID:             "robots",
+			BSSID:            "00:11:22:33:44:55",
+			FrequencyMHz:     5180,
+			Channel:          36,
+			SignalDBm:        &signal,
+			TxBitrateMbps:    866.7,
+			RxBitrateMbps:    240,
+			ConnectedSeconds: 3600,
+		},
+		4: {Mode: "monitor"},
+	}
+	if !reflect.DeepEqual(interfaces, expected) {
+		t.Errorf("wireless mismatch: got %+v, want %+v", *interfaces[3], *expected[3])
+	}
+}
+
+// TestFrequencyChannel tests the conversion of frequencies to channel numbers in all bands.
+func TestFrequencyChannel(t *testing.T) {
+	tests := []struct {
+		mhz     int
+		channel int
+	}{
+		{2412, 1},
+		{2472, 13},
+		{2484, 14},
+		{5180, 36},
+		{5825, 165},
+		{5955, 1},
+		{6115, 33},
+		{60480, 2},
+		{900, 0},
+	}
+
+	for _, test := range tests {
+		if got := frequencyChannel(test.mhz); got != test.channel {
+			t.Errorf("channel of %d MHz mismatch: got %d, want %d", test.mhz, got, test.channel)
+		}
+	}
+}
+
+// TestNamespacePath tests resolving namespace references to their paths.
+func TestNamespacePath(t *testing.T) {
+	tests := []struct {
+		ref      string
+		expected string
+		err      error
+	}{
+		{ref: "blue", expected: "/run/netns/blue"},
+		{ref: "pid:42", expected: "/proc/42/ns/net"},
+		{ref: "pid:042", expected: "/proc/42/ns/net"},
+		{ref: "pid:0", err: ErrNoSuchNamespace},
+		{ref: "pid:self", err: ErrNoSuchNamespace},
+		{ref: "..", err: ErrNoSuchNamespace},
+		{ref: "a/b", err: ErrNoSuchNamespace},
+		{ref: "", err: ErrNoSuchNamespace},
+	}
+
+	for _, test := range tests {
+		t.Run(test.ref, func(t *testing.T) {
+			path, err := namespacePath("/run/netns", "/proc", test.ref)
+			if !errors.Is(err, test.err) {
+				t.Fatalf("error mismatch: got %v want %v", err, test.err)
+			}
+			if path != test.expected {
+				t.Errorf("path mismatch: got %q want %q", path, test.expected)
+			}
+		})
+	}
+}
+
+// TestListNamespaces tests listing named namespaces and deduplicating the ones of processes.
+// Plain files stand in for the namespace references, links to them share their inode.
+func TestListNamespaces(t *testing.T) {
+	root := t.TempDir()
+	netns := filepath.Join(root, "netns")
+	proc := filepath.Join(root, "proc")
+
+	for _, path := range []string{"netns/blue", "netns/red", "proc/1/ns/net", "proc/7/ns/net"} {
+		path = filepath.Join(root, path)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Process 42 runs in blue, process 100 alongside process 1, which is the current namespace
+	for link, target := range map[string]string{"proc/42/ns/net": "netns/blue", "proc/100/ns/net": "proc/1/ns/net", "proc/self/ns/net": "proc/1/ns/net"} {
+		link = filepath.Join(root, link)
+		if err := os.MkdirAll(filepath.Dir(link), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Link(filepath.Join(root, target), link); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	names := func(namespaces []api.Namespace) []string {
+		names := []string{}
+		for _, ns := range namespaces {
+			names = append(names, ns.Name)
+		}
+		return names
+	}
+
+	named, err := listNamespaces(netns, proc, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(named); !reflect.DeepEqual(got, []string{"blue", "red"}) {
+		t.Errorf("named namespaces mismatch: got %v", got)
+	}
+
+	all, err := listNamespaces(netns, proc, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := names(all); !reflect.DeepEqual(got, []string{"blue", "red", "pid:1", "pid:7"}) {
+		t.Errorf("namespaces mismatch: got %v", got)
+	}
+	if !all[2].Current || all[2].PID != 1 || all[0].Current {
+		t.Errorf("unexpected namespaces: %+v", all)
+	}
+
+	// A host without named namespaces has no /run/netns
+	if named, err := listNamespaces(filepath.Join(root, "missing"), proc, false); err != nil || len(named) != 0 {
+		t.Errorf("unexpected result without netns directory: %v, %v", named, err)
+	}
+}
+
+// TestParseBitset tests the decoding of verbose ethtool bitsets and the features built from them.
+func TestParseBitset(t *testing.T) {
+	bitset := func(noMask bool, bits map[string]bool) []byte {
+		var list []byte
+		for _, name := range []string{"1000baseT/Full", "10000baseT/Full", "tx-tcp-segmentation", "rx-gro"} {
+			set, ok := bits[name]
+			if !ok {
+				continue
+			}
+			bit := encodeString(ethtoolABitsetName, name)
+			if set {
+				bit = append(bit, encodeAttr(ethtoolABitsetValue, nil)...)
+			}
+			list = append(list, encodeAttr(ethtoolABitsetBit|syscall.NLA_F_NESTED, bit)...)
+		}
+		var b []byte
+		if noMask {
+			b = encodeAttr(ethtoolABitsetNoMask, nil)
+		}
+		return append(b, encodeAttr(ethtoolABitsetBits|syscall.NLA_F_NESTED, list)...)
+	}
+
+	// Both modes are supported, only 10GbE is advertised
+	mask, value, err := parseBitset(bitset(false, map[string]bool{"1000baseT/Full": false, "10000baseT/Full": true}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(mask, []string{"1000baseT/Full", "10000baseT/Full"}) || !reflect.DeepEqual(value, []string{"10000baseT/Full"}) {
+		t.Errorf("bitset mismatch: got mask %v and value %v", mask, value)
+	}
+
+	// Bitsets without a mask only list the bits that are set
+	_, active, err := parseBitset(bitset(true, map[string]bool{"tx-tcp-segmentation": false}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(active, []string{"tx-tcp-segmentation"}) {
+		t.Errorf("active features mismatch: got %v", active)
+	}
+
+	features := newFeatures([]string{"rx-gro"}, []string{"tx-tcp-segmentation"}, nil)
+	expected := []api.Feature{
+		{Name: "rx-gro", Enabled: false, Fixed: false},
+		{Name: "tx-tcp-segmentation", Enabled: true, Fixed: true},
+	}
+	if !reflect.DeepEqual(features, expected) {
+		t.Errorf("features mismatch: got %+v, want %+v", features, expected)
+	}
+}
+
+// TestParseModuleEEPROM tests decoding the identification of SFP and QSFP28 modules.
+func TestParseModuleEEPROM(t *testing.T) {
+	put := func(eeprom []byte, offset int, s string) {
+		copy(eeprom[offset:], s)
+	}
+
+	// SFF-8472 keeps everything on the lower page
+	sfp := make([]byte, 128)
+	sfp[0] = 0x03
+	put(sfp, 20, "FINISAR CORP.   ")
+	copy(sfp[37:], []byte{0x00, 0x90, 0x65})
+	put(sfp, 40, "FTLX8571D3BCL   ")
+	put(sfp, 56, "A   ")
+	binary.BigEndian.PutUint16(sfp[60:], 850)
+	put(sfp, 68, "AQG0B1K         ")
+	put(sfp, 84, "21031500")
+
+	// SFF-8636 keeps the identification on upper page 00h, the wavelength in steps of 0.05 nm
+	qsfpLower := make([]byte, 128)
+	qsfpLower[0] = 0x11
+	qsfpUpper := make([]byte, 128)
+	put(qsfpUpper, 148-128, "Mellanox        ")
+	copy(qsfpUpper[165-128:], []byte{0x00, 0x02, 0xc9})
+	put(qsfpUpper, 168-128, "MMA1B00-C100D   ")
+	put(qsfpUpper, 184-128, "B2")
+	binary.BigEndian.PutUint16(qsfpUpper[186-128:], 1310*20)
+	put(qsfpUpper, 196-128, "MT2031FT01234   ")
+	put(qsfpUpper, 212-128, "200801  ")
+
+	unknown := make([]byte, 128)
+	unknown[0] = 0x42
+
+	tests := []struct {
+		name     string
+		lower    []byte
+		upper    []byte
+		expected api.ModuleInfo
+	}{
+		{name: "SFP", lower: sfp, expected: api.ModuleInfo{Identifier: "SFP", VendorName: "FINISAR CORP.", VendorOUI: "00:90:65", PartNumber: "FTLX8571D3BCL", Revision: "A", SerialNumber: "AQG0B1K", DateCode: "21031500", WavelengthNm: 850}},
+		{name: "QSFP28", lower: qsfpLower, upper: qsfpUpper, expected: api.ModuleInfo{Identifier: "QSFP28", VendorName: "Mellanox", VendorOUI: "00:02:c9", PartNumber: "MMA1B00-C100D", Revision: "B2", SerialNumber: "MT2031FT01234", DateCode: "200801", WavelengthNm: 1310}},
+		{name: "Unknown", lower: unknown, expected: api.ModuleInfo{Identifier: "0x42"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if info := parseModuleEEPROM(test.lower, test.upper); !reflect.DeepEqual(*info, test.expected) {
+				t.Errorf("module mismatch: got %+v, want %+v", *info, test.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,198 @@
+//go:build linux
+
+package servermodels
+
+import (
+	"syscall"
+
+	api "apimodule"
+)
+
+// NetlinkCollector is a Collector that queries the live host through netlink.
+// Links and addresses are read with one rtnetlink dump each, speed and duplex
+// are queried per interface from the ethtool generic netlink family and the state
+// of wireless interfaces from the nl80211 generic netlink family.
+type NetlinkCollector struct{}
+
+// NewNetlinkCollector creates a new instance of NetlinkCollector.
+func NewNetlinkCollector() *NetlinkCollector {
+	return &NetlinkCollector{}
+}
+
+// Interfaces returns details about all available network interfaces.
+func (c *NetlinkCollector) Interfaces() ([]api.NetworkInterfaceV2, error) {
+	return c.InterfacesWith(AllDetails)
+}
+
+// InterfacesWith returns details about all available network interfaces. Addresses are only
+// dumped, and ethtool and nl80211 are only queried, if their details are selected.
+func (c *NetlinkCollector) InterfacesWith(details Details) ([]api.NetworkInterfaceV2, error) {
+	conn, err := dialNetlink(syscall.NETLINK_ROUTE)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.close()
+
+	// Get all network interfaces
+	links, err := dumpLinks(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	// Get all IP addresses
+	addrs, err := dumpSelectedAddrs(conn, details)
+	if err != nil {
+		return nil, err
+	}
+
+	// Speed and duplex are optional, so a missing ethtool family is not an error
+	ethtool := dialSelectedEthtool(details)
+	if ethtool != nil {
+		defer ethtool.close()
+	}
+
+	var wireless map[int]*api.Wireless
+	if details&DetailWireless != 0 {
+		wireless = dumpWireless()
+	}
+
+	interfaces := make([]api.NetworkInterfaceV2, 0, len(links))
+	for _, link := range links {
+		iface := newNetworkInterface(link, links, addrs, ethtool)
+		iface.Wireless = wireless[link.Index]
+		if details&DetailAddresses == 0 {
+			iface.Addresses = nil
+		}
+		interfaces = append(interfaces, iface)
+	}
+
+	return interfaces, nil
+}
+
+// Interface returns the details of a network interface by its name.
+func (c *NetlinkCollector) Interface(name string) (*api.NetworkInterfaceV2, error) {
+	return c.InterfaceWith(name, AllDetails)
+}
+
+// InterfaceWith returns the details of a network interface by its name, collecting only
+// the selected details.
+func (c *NetlinkCollector) InterfaceWith(name string, details Details) (*api.NetworkInterfaceV2, error) {
+	conn, err := dialNetlink(syscall.NETLINK_ROUTE)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.close()
+
+	// All links are needed to resolve the master, slaves and parent of the interface
+	links, err := dumpLinks(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	var link *rtLink
+	for i := range links {
+		if links[i].Name == name {
+			link = &links[i]
+			break
+		}
+	}
+	if link == nil {
+		// If interface not found, return an error
+		return nil, ErrNoSuchInterface
+	}
+
+	// Get all IP addresses, only the ones of this interface are used
+	addrs, err := dumpSelectedAddrs(conn, details)
+	if err != nil {
+		return nil, err
+	}
+
+	ethtool := dialSelectedEthtool(details)
+	if ethtool != nil {
+		defer ethtool.close()
+	}
+
+	iface := newNetworkInterface(*link, links, addrs, ethtool)
+	if details&DetailWireless != 0 {
+		iface.Wireless = dumpWireless()[link.Index]
+	}
+	if details&DetailAddresses == 0 {
+		iface.Addresses = nil
+	}
+	return &iface, nil
+}
+
+// dumpSelectedAddrs returns all IP addresses if their details are selected, or none otherwise.
+func dumpSelectedAddrs(conn *nlConn, details Details) ([]rtAddr, error) {
+	if details&DetailAddresses == 0 {
+		return nil, nil
+	}
+	return dumpAddrs(conn)
+}
+
+// dialSelectedEthtool connects to the ethtool family if speed and duplex are selected.
+// It returns nil if they aren't or the family is missing.
+func dialSelectedEthtool(details Details) *ethtoolClient {
+	if details&DetailLinkModes == 0 {
+		return nil
+	}
+	ethtool, err := dialEthtool()
+	if err != nil {
+		return nil
+	}
+	return ethtool
+}
+
+// dumpWireless returns the state of all wireless interfaces by their interface index.
+// Wireless details are optional, so hosts without nl80211 yield a nil map.
+func dumpWireless() map[int]*api.Wireless {
+	nl80211, err := dialNL80211()
+	if err != nil {
+		return nil
+	}
+	defer nl80211.close()
+
+	wireless, err := nl80211.wireless()
+	if err != nil {
+		return nil
+	}
+	return wireless
+}
+
+// newNetworkInterface assembles an api.NetworkInterfaceV2 from its netlink link and address messages.
+// The other links are used to resolve its relationships with them. The ethtool client may be nil,
+// in which case speed and duplex are reported as unknown.
+func newNetworkInterface(link rtLink, links []rtLink, addrs []rtAddr, ethtool *ethtoolClient) api.NetworkInterfaceV2 {
+	// Get IP addresses assigned to this interface
+	addresses := []api.Address{}
+	for _, addr := range addrs {
+		if addr.Index == link.Index {
+			addresses = append(addresses, newAddress(addr))
+		}
+	}
+
+	// Get Speed and Duplex
+	var speed *int64
+	duplex := api.DuplexNone
+	if ethtool != nil {
+		if mbps, mode, err := ethtool.linkModes(link.Name); err == nil {
+			speed, duplex = speedMbps(mbps), duplexMode(mode)
+		}
+	}
+
+	iface := api.NetworkInterfaceV2{
+		Name:        link.Name,
+		Alias:       link.Alias,
+		MACAddress:  link.HardwareAddr.String(),
+		MTU:         link.MTU,
+		SpeedMbps:   speed,
+		Duplex:      duplex,
+		AdminStatus: adminStatus(link.Flags),
+		OperStatus:  operStatus(link.OperState),
+		Addresses:   addresses,
+		Stats:       link.Stats,
+	}
+	setLinkInfo(&iface, link, links)
+
+	return iface
+}
@@ -0,0 +1,32 @@
+//go:build !linux
+
+package servermodels
+
+import (
+	"errors"
+	"fmt"
+
+	api "apimodule"
+)
+
+// errNoNetlink is returned by the NetlinkCollector on hosts without netlink.
+var errNoNetlink = fmt.Errorf("netlink is only available on Linux: %w", errors.ErrUnsupported)
+
+// NetlinkCollector is a stub of the netlink Collector for hosts other than Linux, which fails to
+// collect any interface. The SysfsCollector still serves a captured sysfs tree on these hosts.
+type NetlinkCollector struct{}
+
+// NewNetlinkCollector creates a new instance of NetlinkCollector.
+func NewNetlinkCollector() *NetlinkCollector {
+	return &NetlinkCollector{}
+}
+
+// Interfaces returns errNoNetlink.
+func (c *NetlinkCollector) Interfaces() ([]api.NetworkInterfaceV2, error) {
+	return nil, errNoNetlink
+}
+
+// Interface returns errNoNetlink.
+func (c *NetlinkCollector) Interface(name string) (*api.NetworkInterfaceV2, error) {
+	return nil, errNoNetlink
+}
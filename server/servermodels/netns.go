@@ -0,0 +1,224 @@
+//go:build linux
+
+package servermodels
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+
+	api "apimodule"
+	"golang.org/x/sys/unix"
+)
+
+// Locations of the namespace references, as used by `ip netns`.
+const (
+	netnsDir = "/run/netns" // Bind mounts of the named namespaces.
+	procDir  = "/proc"      // Process directories, each with a reference in ns/net.
+)
+
+// pidPrefix marks namespace references by process ID.
+const pidPrefix = "pid:"
+
+// Namespaces returns the named network namespaces and, if withPIDs is set, the namespaces
+// of processes that have no name.
+func (c *NetlinkCollector) Namespaces(withPIDs bool) ([]api.Namespace, error) {
+	return listNamespaces(netnsDir, procDir, withPIDs)
+}
+
+// InNamespace returns a Collector that queries the network namespace through netlink.
+func (c *NetlinkCollector) InNamespace(ref string) (Collector, error) {
+	path, err := namespacePath(netnsDir, procDir, ref)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, ErrNoSuchNamespace
+		}
+		return nil, err
+	}
+
+	return &netnsCollector{collector: c, path: path}, nil
+}
+
+// netnsCollector is a Collector that runs a NetlinkCollector inside another network namespace.
+// Netlink sockets stay bound to the namespace they were created in, so every query opens its
+// sockets after switching into the namespace.
+type netnsCollector struct {
+	collector *NetlinkCollector
+	path      string // Namespace reference, e.g. /run/netns/{name}.
+}
+
+// Interfaces returns details about all network interfaces of the namespace.
+func (c *netnsCollector) Interfaces() ([]api.NetworkInterfaceV2, error) {
+	return c.InterfacesWith(AllDetails)
+}
+
+// InterfacesWith returns details about all network interfaces of the namespace, collecting
+// only the selected details.
+func (c *netnsCollector) InterfacesWith(details Details) ([]api.NetworkInterfaceV2, error) {
+	var interfaces []api.NetworkInterfaceV2
+	err := withNamespace(c.path, func() (err error) {
+		interfaces, err = c.collector.InterfacesWith(details)
+		return err
+	})
+	return interfaces, err
+}
+
+// Interface returns the details of a network interface of the namespace by its name.
+func (c *netnsCollector) Interface(name string) (*api.NetworkInterfaceV2, error) {
+	return c.InterfaceWith(name, AllDetails)
+}
+
+// InterfaceWith returns the details of a network interface of the namespace by its name,
+// collecting only the selected details.
+func (c *netnsCollector) InterfaceWith(name string, details Details) (*api.NetworkInterfaceV2, error) {
+	var iface *api.NetworkInterfaceV2
+	err := withNamespace(c.path, func() (err error) {
+		iface, err = c.collector.InterfaceWith(name, details)
+		return err
+	})
+	return iface, err
+}
+
+// withNamespace runs fn with the calling thread switched into the network namespace at path.
+// The namespace is a property of the OS thread, so fn runs on a dedicated goroutine locked to
+// its thread and must not start goroutines of its own. If the thread can't be switched back,
+// the goroutine exits without unlocking it, which makes the runtime terminate the thread
+// instead of reusing it in the wrong namespace.
+func withNamespace(path string, fn func() error) error {
+	target, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ErrNoSuchNamespace
+		}
+		return err
+	}
+	defer target.Close()
+
+	errc := make(chan error, 1)
+	go func() {
+		runtime.LockOSThread()
+
+		origin, err := os.Open("/proc/thread-self/ns/net")
+		if err != nil {
+			runtime.UnlockOSThread()
+			errc <- err
+			return
+		}
+		defer origin.Close()
+
+		if err := setns(int(target.Fd())); err != nil {
+			runtime.UnlockOSThread()
+			errc <- err
+			return
+		}
+
+		fnErr := fn()
+
+		if err := setns(int(origin.Fd())); err != nil {
+			errc <- err
+			return
+		}
+
+		runtime.UnlockOSThread()
+		errc <- fnErr
+	}()
+
+	return <-errc
+}
+
+// setns switches the calling thread into the network namespace referenced by fd.
+func setns(fd int) error {
+	return os.NewSyscallError("setns", unix.Setns(fd, unix.CLONE_NEWNET))
+}
+
+// namespacePath returns the path of the reference to a network namespace, either the bind
+// mount of a named namespace or the ns/net link of a process.
+func namespacePath(netnsDir, procDir, ref string) (string, error) {
+	if pid, ok := strings.CutPrefix(ref, pidPrefix); ok {
+		n, err := strconv.Atoi(pid)
+		if err != nil || n <= 0 {
+			return "", ErrNoSuchNamespace
+		}
+		return filepath.Join(procDir, strconv.Itoa(n), "ns", "net"), nil
+	}
+
+	// Names are single path elements, like they are for `ip netns`
+	if ref == "" || ref == "." || ref == ".." || strings.ContainsRune(ref, '/') {
+		return "", ErrNoSuchNamespace
+	}
+	return filepath.Join(netnsDir, ref), nil
+}
+
+// listNamespaces lists the named namespaces in netnsDir, sorted by name, followed by the
+// namespaces of the processes in procDir that have no name if withPIDs is set. References
+// to the same namespace are identified by their inode, only the first one is listed.
+func listNamespaces(netnsDir, procDir string, withPIDs bool) ([]api.Namespace, error) {
+	current, _ := namespaceInode(filepath.Join(procDir, "self", "ns", "net"))
+	seen := make(map[uint64]bool)
+	namespaces := []api.Namespace{}
+
+	entries, err := os.ReadDir(netnsDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		inode, err := namespaceInode(filepath.Join(netnsDir, entry.Name()))
+		if err != nil || seen[inode] {
+			continue
+		}
+		seen[inode] = true
+		namespaces = append(namespaces, api.Namespace{Name: entry.Name(), Inode: inode, Current: inode == current})
+	}
+
+	if !withPIDs {
+		return namespaces, nil
+	}
+
+	entries, err = os.ReadDir(procDir)
+	if err != nil {
+		return nil, err
+	}
+
+	var pids []int
+	for _, entry := range entries {
+		if pid, err := strconv.Atoi(entry.Name()); err == nil && pid > 0 {
+			pids = append(pids, pid)
+		}
+	}
+	sort.Ints(pids)
+
+	for _, pid := range pids {
+		// Processes may exit meanwhile or belong to other users, both are skipped
+		inode, err := namespaceInode(filepath.Join(procDir, strconv.Itoa(pid), "ns", "net"))
+		if err != nil || seen[inode] {
+			continue
+		}
+		seen[inode] = true
+		namespaces = append(namespaces, api.Namespace{
+			Name:    pidPrefix + strconv.Itoa(pid),
+			Inode:   inode,
+			PID:     pid,
+			Current: inode == current,
+		})
+	}
+
+	return namespaces, nil
+}
+
+// namespaceInode returns the inode of the namespace a reference points to.
+func namespaceInode(path string) (uint64, error) {
+	var stat syscall.Stat_t
+	if err := syscall.Stat(path, &stat); err != nil {
+		return 0, err
+	}
+	return stat.Ino, nil
+}
@@ -0,0 +1,240 @@
+//go:build linux
+
+package servermodels
+
+import (
+	"encoding/binary"
+	"net"
+	"syscall"
+
+	api "apimodule"
+)
+
+// Constants of the nl80211 generic netlink family from linux/nl80211.h.
+const (
+	nl80211GenlName    = "nl80211" // NL80211_GENL_NAME
+	nl80211GenlVersion = 0         // The family is not versioned, iw sends 0
+
+	nl80211CmdGetInterface = 5  // NL80211_CMD_GET_INTERFACE
+	nl80211CmdGetStation   = 17 // NL80211_CMD_GET_STATION
+
+	nl80211AttrIfindex   = 3  // NL80211_ATTR_IFINDEX
+	nl80211AttrIftype    = 5  // NL80211_ATTR_IFTYPE
+	nl80211AttrMAC       = 6  // NL80211_ATTR_MAC
+	nl80211AttrStaInfo   = 21 // NL80211_ATTR_STA_INFO
+	nl80211AttrWiphyFreq = 38 // NL80211_ATTR_WIPHY_FREQ
+	nl80211AttrSSID      = 52 // NL80211_ATTR_SSID
+
+	nl80211StaInfoSignal        = 7  // NL80211_STA_INFO_SIGNAL
+	nl80211StaInfoTxBitrate     = 8  // NL80211_STA_INFO_TX_BITRATE
+	nl80211StaInfoRxBitrate     = 14 // NL80211_STA_INFO_RX_BITRATE
+	nl80211StaInfoConnectedTime = 16 // NL80211_STA_INFO_CONNECTED_TIME
+
+	nl80211RateInfoBitrate   = 1 // NL80211_RATE_INFO_BITRATE
+	nl80211RateInfoBitrate32 = 5 // NL80211_RATE_INFO_BITRATE32
+
+	nl80211IftypeStation   = 2 // NL80211_IFTYPE_STATION
+	nl80211IftypeP2PClient = 8 // NL80211_IFTYPE_P2P_CLIENT
+)
+
+// nl80211Iftypes maps NL80211_IFTYPE_* values to their names as used by the `iw` command.
+var nl80211Iftypes = map[uint8]string{
+	1:  "adhoc",      // NL80211_IFTYPE_ADHOC
+	2:  "station",    // NL80211_IFTYPE_STATION
+	3:  "ap",         // NL80211_IFTYPE_AP
+	4:  "ap_vlan",    // NL80211_IFTYPE_AP_VLAN
+	5:  "wds",        // NL80211_IFTYPE_WDS
+	6:  "monitor",    // NL80211_IFTYPE_MONITOR
+	7:  "mesh",       // NL80211_IFTYPE_MESH_POINT
+	8:  "p2p-client", // NL80211_IFTYPE_P2P_CLIENT
+	9:  "p2p-go",     // NL80211_IFTYPE_P2P_GO
+	10: "p2p-device", // NL80211_IFTYPE_P2P_DEVICE
+	11: "ocb",        // NL80211_IFTYPE_OCB
+	12: "nan",        // NL80211_IFTYPE_NAN
+}
+
+// nl80211Client queries the nl80211 generic netlink family.
+type nl80211Client struct {
+	conn   *nlConn // Generic netlink socket.
+	family uint16  // Resolved ID of the nl80211 family.
+}
+
+// dialNL80211 opens a generic netlink socket and resolves the nl80211 family.
+// It fails on hosts without cfg80211, i.e. without any wireless hardware support.
+func dialNL80211() (*nl80211Client, error) {
+	conn, err := dialNetlink(syscall.NETLINK_GENERIC)
+	if err != nil {
+		return nil, err
+	}
+
+	family, err := conn.resolveFamily(nl80211GenlName)
+	if err != nil {
+		conn.close()
+		return nil, err
+	}
+
+	return &nl80211Client{conn: conn, family: family}, nil
+}
+
+// close releases the underlying netlink socket.
+func (n *nl80211Client) close() error {
+	return n.conn.close()
+}
+
+// wireless returns the state of all wireless interfaces by their interface index.
+// Stations and P2P clients additionally report the link to their access point.
+func (n *nl80211Client) wireless() (map[int]*api.Wireless, error) {
+	msgs, err := n.conn.execute(n.family, syscall.NLM_F_DUMP, genlMessage(nl80211CmdGetInterface, nl80211GenlVersion))
+	if err != nil {
+		return nil, err
+	}
+
+	interfaces, err := parseWirelessInterfaces(msgs)
+	if err != nil {
+		return nil, err
+	}
+
+	for index, w := range interfaces {
+		if w.Mode != nl80211Iftypes[nl80211IftypeStation] && w.Mode != nl80211Iftypes[nl80211IftypeP2PClient] {
+			continue
+		}
+
+		// A station has a single entry, the access point it is associated with
+		ifindex := encodeAttr(nl80211AttrIfindex, binary.NativeEndian.AppendUint32(nil, uint32(index)))
+		msgs, err := n.conn.execute(n.family, syscall.NLM_F_DUMP, genlMessage(nl80211CmdGetStation, nl80211GenlVersion, ifindex))
+		if err != nil {
+			// The interface may have disappeared meanwhile, it is reported as not connected
+			continue
+		}
+		if err := parseStation(w, msgs); err != nil {
+			return nil, err
+		}
+	}
+
+	return interfaces, nil
+}
+
+// parseWirelessInterfaces decodes NL80211_CMD_NEW_INTERFACE messages into the state of
+// the wireless interfaces by their interface index.
+func parseWirelessInterfaces(msgs []syscall.NetlinkMessage) (map[int]*api.Wireless, error) {
+	interfaces := make(map[int]*api.Wireless)
+	for _, m := range msgs {
+		if len(m.Data) < genlHdrLen {
+			continue
+		}
+
+		attrs, err := parseAttrs(m.Data[genlHdrLen:])
+		if err != nil {
+			return nil, err
+		}
+
+		index := 0
+		w := &api.Wireless{}
+		for _, a := range attrs {
+			switch a.Type {
+			case nl80211AttrIfindex:
+				index = int(attrUint32(a.Data))
+			case nl80211AttrIftype:
+				w.Mode = lookupName(nl80211Iftypes, uint8(attrUint32(a.Data)))
+			case nl80211AttrSSID:
+				w.SSID = string(a.Data)
+			case nl80211AttrWiphyFreq:
+				w.FrequencyMHz = int(attrUint32(a.Data))
+				w.Channel = frequencyChannel(w.FrequencyMHz)
+			}
+		}
+
+		if index != 0 {
+			interfaces[index] = w
+		}
+	}
+
+	return interfaces, nil
+}
+
+// parseStation decodes the NL80211_CMD_NEW_STATION message of the access point a station
+// is associated with. Without one the station is not connected.
+func parseStation(w *api.Wireless, msgs []syscall.NetlinkMessage) error {
+	for _, m := range msgs {
+		if len(m.Data) < genlHdrLen {
+			continue
+		}
+
+		attrs, err := parseAttrs(m.Data[genlHdrLen:])
+		if err != nil {
+			return err
+		}
+
+		for _, a := range attrs {
+			switch a.Type {
+			case nl80211AttrMAC:
+				w.BSSID = net.HardwareAddr(a.Data).String()
+			case nl80211AttrStaInfo:
+				info, err := parseAttrs(a.Data)
+				if err != nil {
+					return err
+				}
+				for _, i := range info {
+					switch i.Type {
+					case nl80211StaInfoSignal:
+						signal := int(int8(attrUint8(i.Data)))
+						w.SignalDBm = &signal
+					case nl80211StaInfoTxBitrate:
+						w.TxBitrateMbps = parseBitrate(i.Data)
+					case nl80211StaInfoRxBitrate:
+						w.RxBitrateMbps = parseBitrate(i.Data)
+					case nl80211StaInfoConnectedTime:
+						w.ConnectedSeconds = attrUint32(i.Data)
+					}
+				}
+			}
+		}
+
+		w.Connected = true
+		return nil
+	}
+
+	return nil
+}
+
+// parseBitrate decodes a nested NL80211_STA_INFO_*_BITRATE attribute to Mb/s.
+// The 32-bit bitrate supersedes the 16-bit one, both are in units of 100 kb/s.
+func parseBitrate(b []byte) float64 {
+	attrs, err := parseAttrs(b)
+	if err != nil {
+		return 0
+	}
+
+	var rate uint32
+	for _, a := range attrs {
+		switch a.Type {
+		case nl80211RateInfoBitrate:
+			if rate == 0 {
+				rate = uint32(attrUint16(a.Data))
+			}
+		case nl80211RateInfoBitrate32:
+			rate = attrUint32(a.Data)
+		}
+	}
+
+	return float64(rate) / 10
+}
+
+// frequencyChannel returns the 802.11 channel number of a frequency in MHz, or 0 if unknown.
+func frequencyChannel(mhz int) int {
+	switch {
+	case mhz == 2484:
+		return 14
+	case mhz >= 2412 && mhz < 2484:
+		return (mhz - 2407) / 5
+	case mhz >= 5955 && mhz <= 7115:
+		// The 6 GHz band (802.11ax), checked before the overlapping 5 GHz formula
+		return (mhz - 5950) / 5
+	case mhz >= 5000 && mhz < 5955:
+		return (mhz - 5000) / 5
+	case mhz >= 58320 && mhz <= 70200:
+		return (mhz - 56160) / 2160
+	default:
+		return 0
+	}
+}
@@ -0,0 +1,388 @@
+//go:build linux
+
+package servermodels
+
+import (
+	"encoding/binary"
+	"errors"
+	"net"
+	"strconv"
+	"syscall"
+
+	api "apimodule"
+)
+
+// Routes returns the entries of all IPv4 and IPv6 routing tables.
+func (c *NetlinkCollector) Routes() ([]api.Route, error) {
+	conn, err := dialNetlink(syscall.NETLINK_ROUTE)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.close()
+
+	names, err := linkNames(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	msgs, err := conn.execute(syscall.RTM_GETROUTE, syscall.NLM_F_DUMP, rtMsg(syscall.AF_UNSPEC, 0, 0))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseRoutes(msgs, names)
+}
+
+// Rules returns the IPv4 and IPv6 policy routing rules.
+func (c *NetlinkCollector) Rules() ([]api.Rule, error) {
+	conn, err := dialNetlink(syscall.NETLINK_ROUTE)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.close()
+
+	// Rules are dumped per family, an unspecified family is rejected by older kernels
+	var rules []api.Rule
+	for _, family := range []uint8{syscall.AF_INET, syscall.AF_INET6} {
+		msgs, err := conn.execute(syscall.RTM_GETRULE, syscall.NLM_F_DUMP, rtMsg(family, 0, 0))
+		if err != nil {
+			if errors.Is(err, syscall.EAFNOSUPPORT) {
+				// IPv6 is disabled
+				continue
+			}
+			return nil, err
+		}
+
+		familyRules, err := parseRules(msgs)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, familyRules...)
+	}
+
+	return rules, nil
+}
+
+// LookupRoute returns the route the kernel selects for packets to the destination.
+func (c *NetlinkCollector) LookupRoute(dst net.IP) (*api.Route, error) {
+	conn, err := dialNetlink(syscall.NETLINK_ROUTE)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.close()
+
+	names, err := linkNames(conn)
+	if err != nil {
+		return nil, err
+	}
+
+	family, ip := uint8(syscall.AF_INET6), dst.To16()
+	if ip4 := dst.To4(); ip4 != nil {
+		family, ip = syscall.AF_INET, ip4
+	}
+
+	// RTM_F_FIB_MATCH asks for the matching table entry instead of a cloned host route
+	req := append(rtMsg(family, uint8(len(ip)*8), rtmFFibMatch), encodeAttr(syscall.RTA_DST, ip)...)
+	msgs, err := conn.execute(syscall.RTM_GETROUTE, 0, req)
+	if err != nil {
+		if errors.Is(err, syscall.ENETUNREACH) || errors.Is(err, syscall.EHOSTUNREACH) {
+			return nil, ErrNoRoute
+		}
+		return nil, err
+	}
+
+	routes, err := parseRoutes(msgs, names)
+	if err != nil {
+		return nil, err
+	}
+	if len(routes) == 0 {
+		return nil, ErrNoRoute
+	}
+
+	return &routes[0], nil
+}
+
+// rtMsg returns an rtmsg header (which has the same layout as a fib_rule_hdr) for the given
+// family, destination prefix length and flags.
+func rtMsg(family, dstLen uint8, flags uint32) []byte {
+	b := make([]byte, syscall.SizeofRtMsg)
+	b[0] = family
+	b[1] = dstLen
+	binary.NativeEndian.PutUint32(b[8:12], flags)
+	return b
+}
+
+// linkNames returns the names of all links by their index.
+func linkNames(c *nlConn) (map[int]string, error) {
+	links, err := dumpLinks(c)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int]string, len(links))
+	for _, link := range links {
+		names[link.Index] = link.Name
+	}
+
+	return names, nil
+}
+
+// parseRoutes decodes the IPv4 and IPv6 routes of RTM_NEWROUTE messages.
+// The names map interface indexes to interface names.
+func parseRoutes(msgs []syscall.NetlinkMessage, names map[int]string) ([]api.Route, error) {
+	routes := []api.Route{}
+	for _, m := range msgs {
+		if m.Header.Type != syscall.RTM_NEWROUTE || len(m.Data) < syscall.SizeofRtMsg {
+			continue
+		}
+
+		family, bits := familyName(m.Data[0])
+		if family == "" {
+			// Skip MPLS and other non-IP routes
+			continue
+		}
+
+		dstLen := int(m.Data[1])
+		table := uint32(m.Data[4])
+		route := api.Route{
+			Family:   family,
+			Protocol: routeProtocol(m.Data[5]),
+			Scope:    scopeName(m.Data[6]),
+			Type:     routeTypes[m.Data[7]],
+		}
+
+		attrs, err := parseAttrs(m.Data[syscall.SizeofRtMsg:])
+		if err != nil {
+			return nil, err
+		}
+
+		dst := make(net.IP, bits/8)
+		for _, a := range attrs {
+			switch a.Type {
+			case syscall.RTA_DST:
+				dst = net.IP(append([]byte(nil), a.Data...))
+			case syscall.RTA_PREFSRC:
+				route.Source = net.IP(a.Data).String()
+			case syscall.RTA_GATEWAY:
+				route.Gateway = net.IP(a.Data).String()
+			case rtaVia:
+				route.Gateway = parseVia(a.Data)
+			case syscall.RTA_OIF:
+				route.Interface = names[int(attrUint32(a.Data))]
+			case syscall.RTA_PRIORITY:
+				route.Metric = attrUint32(a.Data)
+			case syscall.RTA_TABLE:
+				// RTA_TABLE supersedes the 8-bit table of the header for IDs above 255
+				table = attrUint32(a.Data)
+			case syscall.RTA_MULTIPATH:
+				if route.Nexthops, err = parseNexthops(a.Data, names); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		route.Destination = (&net.IPNet{IP: dst, Mask: net.CIDRMask(dstLen, bits)}).String()
+		route.Table = TableName(table)
+		routes = append(routes, route)
+	}
+
+	return routes, nil
+}
+
+// parseNexthops decodes the rtnexthop structures of an RTA_MULTIPATH attribute.
+func parseNexthops(b []byte, names map[int]string) ([]api.Nexthop, error) {
+	var nexthops []api.Nexthop
+	for len(b) >= syscall.SizeofRtNexthop {
+		l := int(binary.NativeEndian.Uint16(b[0:2]))
+		if l < syscall.SizeofRtNexthop || l > len(b) {
+			return nil, errors.New("netlink: invalid nexthop length")
+		}
+
+		nexthop := api.Nexthop{
+			Weight:    int(b[3]) + 1, // rtnh_hops holds the weight minus one
+			Interface: names[int(int32(binary.NativeEndian.Uint32(b[4:8])))],
+		}
+
+		attrs, err := parseAttrs(b[syscall.SizeofRtNexthop:l])
+		if err != nil {
+			return nil, err
+		}
+		for _, a := range attrs {
+			switch a.Type {
+			case syscall.RTA_GATEWAY:
+				nexthop.Gateway = net.IP(a.Data).String()
+			case rtaVia:
+				nexthop.Gateway = parseVia(a.Data)
+			}
+		}
+
+		nexthops = append(nexthops, nexthop)
+		if nlAlign(l) >= len(b) {
+			break
+		}
+		b = b[nlAlign(l):]
+	}
+
+	return nexthops, nil
+}
+
+// parseVia decodes the gateway of an RTA_VIA attribute (struct rtvia), used for IPv4
+// routes with an IPv6 next hop.
+func parseVia(b []byte) string {
+	if len(b) < 2 {
+		return ""
+	}
+	return net.IP(b[2:]).String()
+}
+
+// parseRules decodes RTM_NEWRULE messages.
+func parseRules(msgs []syscall.NetlinkMessage) ([]api.Rule, error) {
+	rules := []api.Rule{}
+	for _, m := range msgs {
+		if m.Header.Type != syscall.RTM_NEWRULE || len(m.Data) < syscall.SizeofRtMsg {
+			continue
+		}
+
+		family, bits := familyName(m.Data[0])
+		if family == "" {
+			continue
+		}
+
+		dstLen, srcLen := int(m.Data[1]), int(m.Data[2])
+		table := uint32(m.Data[4])
+		rule := api.Rule{
+			Family: family,
+			Action: ruleActions[m.Data[7]],
+			Invert: binary.NativeEndian.Uint32(m.Data[8:12])&fibRuleInvert != 0,
+		}
+
+		attrs, err := parseAttrs(m.Data[syscall.SizeofRtMsg:])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, a := range attrs {
+			switch a.Type {
+			case fraDst:
+				rule.Destination = (&net.IPNet{IP: net.IP(a.Data), Mask: net.CIDRMask(dstLen, bits)}).String()
+			case fraSrc:
+				rule.Source = (&net.IPNet{IP: net.IP(a.Data), Mask: net.CIDRMask(srcLen, bits)}).String()
+			case fraIifname:
+				rule.InputInterface = attrString(a.Data)
+			case fraOifname:
+				rule.OutputInterface = attrString(a.Data)
+			case fraPriority:
+				rule.Priority = attrUint32(a.Data)
+			case fraFwmark:
+				rule.FwMark = attrUint32(a.Data)
+			case fraFwmask:
+				rule.FwMask = attrUint32(a.Data)
+			case fraTable:
+				table = attrUint32(a.Data)
+			}
+		}
+
+		if table != syscall.RT_TABLE_UNSPEC {
+			rule.Table = TableName(table)
+		}
+		rules = append(rules, rule)
+	}
+
+	return rules, nil
+}
+
+// familyName returns the api family name and address length in bits of an IP address family,
+// or an empty name for other families.
+func familyName(family uint8) (string, int) {
+	switch family {
+	case syscall.AF_INET:
+		return api.FamilyIPv4, 32
+	case syscall.AF_INET6:
+		return api.FamilyIPv6, 128
+	default:
+		return "", 0
+	}
+}
+
+// scopeName returns the name of an RT_SCOPE_* value, or its number if it has none.
+func scopeName(scope uint8) string {
+	if name, ok := addressScopes[scope]; ok {
+		return name
+	}
+	return strconv.Itoa(int(scope))
+}
+
+// routeProtocol returns the name of an RTPROT_* value as used by the `ip` command, or its number.
+func routeProtocol(protocol uint8) string {
+	if name, ok := routeProtocols[protocol]; ok {
+		return name
+	}
+	return strconv.Itoa(int(protocol))
+}
+
+// routeProtocols maps RTPROT_* values to their names.
+var routeProtocols = map[uint8]string{
+	syscall.RTPROT_UNSPEC:   "unspec",
+	syscall.RTPROT_REDIRECT: "redirect",
+	syscall.RTPROT_KERNEL:   "kernel",
+	syscall.RTPROT_BOOT:     "boot",
+	syscall.RTPROT_STATIC:   "static",
+	syscall.RTPROT_GATED:    "gated",
+	syscall.RTPROT_RA:       "ra",
+	syscall.RTPROT_MRT:      "mrt",
+	syscall.RTPROT_ZEBRA:    "zebra",
+	syscall.RTPROT_BIRD:     "bird",
+	syscall.RTPROT_DNROUTED: "dnrouted",
+	syscall.RTPROT_XORP:     "xorp",
+	syscall.RTPROT_NTK:      "ntk",
+	syscall.RTPROT_DHCP:     "dhcp",
+	18:                      "keepalived", // RTPROT_KEEPALIVED
+	42:                      "babel",      // RTPROT_BABEL
+	186:                     "bgp",        // RTPROT_BGP
+	187:                     "isis",       // RTPROT_ISIS
+	188:                     "ospf",       // RTPROT_OSPF
+	189:                     "rip",        // RTPROT_RIP
+	192:                     "eigrp",      // RTPROT_EIGRP
+}
+
+// routeTypes maps RTN_* values to their names.
+var routeTypes = map[uint8]string{
+	syscall.RTN_UNSPEC:      "unspec",
+	syscall.RTN_UNICAST:     "unicast",
+	syscall.RTN_LOCAL:       "local",
+	syscall.RTN_BROADCAST:   "broadcast",
+	syscall.RTN_ANYCAST:     "anycast",
+	syscall.RTN_MULTICAST:   "multicast",
+	syscall.RTN_BLACKHOLE:   "blackhole",
+	syscall.RTN_UNREACHABLE: "unreachable",
+	syscall.RTN_PROHIBIT:    "prohibit",
+	syscall.RTN_THROW:       "throw",
+	syscall.RTN_NAT:         "nat",
+	syscall.RTN_XRESOLVE:    "xresolve",
+}
+
+// ruleActions maps FR_ACT_* values to their names as used by the `ip rule` command.
+var ruleActions = map[uint8]string{
+	0: "unspec",      // FR_ACT_UNSPEC
+	1: "lookup",      // FR_ACT_TO_TBL
+	2: "goto",        // FR_ACT_GOTO
+	3: "nop",         // FR_ACT_NOP
+	6: "blackhole",   // FR_ACT_BLACKHOLE
+	7: "unreachable", // FR_ACT_UNREACHABLE
+	8: "prohibit",    // FR_ACT_PROHIBIT
+}
+
+// Routing constants the syscall package does not define, from linux/rtnetlink.h and linux/fib_rules.h.
+const (
+	rtaVia        = 18     // RTA_VIA
+	rtmFFibMatch  = 0x2000 // RTM_F_FIB_MATCH
+	fibRuleInvert = 0x2    // FIB_RULE_INVERT
+	fraDst        = 1      // FRA_DST
+	fraSrc        = 2      // FRA_SRC
+	fraIifname    = 3      // FRA_IIFNAME
+	fraPriority   = 6      // FRA_PRIORITY
+	fraFwmark     = 10     // FRA_FWMARK
+	fraTable      = 15     // FRA_TABLE
+	fraFwmask     = 16     // FRA_FWMASK
+	fraOifname    = 17     // FRA_OIFNAME
+)
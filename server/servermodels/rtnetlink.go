@@ -0,0 +1,261 @@
+//go:build linux
+
+package servermodels
+
+import (
+	"encoding/binary"
+	"net"
+	"strconv"
+	"syscall"
+
+	api "apimodule"
+)
+
+// rtLink holds the parts of an RTM_NEWLINK message that the collector needs.
+type rtLink struct {
+	Index        int                 // Interface index.
+	Name         string              // Interface name (IFLA_IFNAME).
+	Alias        string              // Description set by the administrator (IFLA_IFALIAS).
+	Flags        uint32              // Device flags (IFF_*).
+	MTU          int                 // Maximum Transmission Unit (IFLA_MTU).
+	HardwareAddr net.HardwareAddr    // Link layer address (IFLA_ADDRESS).
+	OperState    uint8               // RFC 2863 operational state (IFLA_OPERSTATE).
+	Stats        *api.InterfaceStats // Traffic counters (IFLA_STATS64).
+	Master       int                 // Index of the bond or bridge the link is enslaved to (IFLA_MASTER).
+	Link         int                 // Index of the parent or peer link (IFLA_LINK).
+	LinkNetnsID  bool                // Whether Link refers to another network namespace (IFLA_LINK_NETNSID).
+	Kind         string              // Link kind, e.g. bond or vlan (IFLA_INFO_KIND).
+	InfoData     []nlAttr            // Kind-specific attributes (IFLA_INFO_DATA).
+	SlaveKind    string              // Kind of the master of an enslaved link (IFLA_INFO_SLAVE_KIND).
+	SlaveData    []nlAttr            // Master-specific attributes of an enslaved link (IFLA_INFO_SLAVE_DATA).
+}
+
+// rtAddr holds the parts of an RTM_NEWADDR message that the collector needs.
+type rtAddr struct {
+	Index     int    // Index of the interface the address is assigned to.
+	Family    int    // Address family (AF_INET or AF_INET6).
+	PrefixLen int    // Length of the network prefix.
+	Scope     uint8  // Address scope (RT_SCOPE_*).
+	Flags     uint32 // Address flags (IFA_F_*).
+	IP        net.IP // The address itself.
+}
+
+// ifInfomsg returns an ifinfomsg header selecting the given interface index (0 for all).
+func ifInfomsg(index int) []byte {
+	b := make([]byte, syscall.SizeofIfInfomsg)
+	b[0] = syscall.AF_UNSPEC
+	binary.NativeEndian.PutUint32(b[4:8], uint32(int32(index)))
+	return b
+}
+
+// dumpLinks retrieves every link known to the kernel with a single RTM_GETLINK dump.
+func dumpLinks(c *nlConn) ([]rtLink, error) {
+	msgs, err := c.execute(syscall.RTM_GETLINK, syscall.NLM_F_DUMP, ifInfomsg(0))
+	if err != nil {
+		return nil, err
+	}
+
+	return parseLinks(msgs)
+}
+
+// parseLinks decodes RTM_NEWLINK messages.
+func parseLinks(msgs []syscall.NetlinkMessage) ([]rtLink, error) {
+	var links []rtLink
+	for _, m := range msgs {
+		if m.Header.Type != syscall.RTM_NEWLINK || len(m.Data) < syscall.SizeofIfInfomsg {
+			continue
+		}
+
+		link := rtLink{
+			Index: int(int32(binary.NativeEndian.Uint32(m.Data[4:8]))),
+			Flags: binary.NativeEndian.Uint32(m.Data[8:12]),
+		}
+
+		attrs, err := parseAttrs(m.Data[syscall.SizeofIfInfomsg:])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, a := range attrs {
+			switch a.Type {
+			case syscall.IFLA_IFNAME:
+				link.Name = attrString(a.Data)
+			case syscall.IFLA_IFALIAS:
+				link.Alias = attrString(a.Data)
+			case syscall.IFLA_MTU:
+				link.MTU = int(attrUint32(a.Data))
+			case syscall.IFLA_ADDRESS:
+				link.HardwareAddr = net.HardwareAddr(append([]byte(nil), a.Data...))
+			case syscall.IFLA_OPERSTATE:
+				link.OperState = attrUint8(a.Data)
+			case iflaStats64:
+				link.Stats = parseLinkStats64(a.Data)
+			case syscall.IFLA_MASTER:
+				link.Master = int(attrUint32(a.Data))
+			case syscall.IFLA_LINK:
+				link.Link = int(attrUint32(a.Data))
+			case iflaLinkNetnsID:
+				link.LinkNetnsID = true
+			case syscall.IFLA_LINKINFO:
+				if err := parseLinkInfo(&link, a.Data); err != nil {
+					return nil, err
+				}
+			}
+		}
+
+		links = append(links, link)
+	}
+
+	return links, nil
+}
+
+// parseLinkInfo decodes the nested IFLA_LINKINFO attribute into the link.
+func parseLinkInfo(link *rtLink, b []byte) error {
+	attrs, err := parseAttrs(b)
+	if err != nil {
+		return err
+	}
+
+	for _, a := range attrs {
+		switch a.Type {
+		case iflaInfoKind:
+			link.Kind = attrString(a.Data)
+		case iflaInfoSlaveKind:
+			link.SlaveKind = attrString(a.Data)
+		case iflaInfoData:
+			if link.InfoData, err = parseAttrs(a.Data); err != nil {
+				return err
+			}
+		case iflaInfoSlaveData:
+			if link.SlaveData, err = parseAttrs(a.Data); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// dumpAddrs retrieves every IPv4 and IPv6 address with a single RTM_GETADDR dump.
+func dumpAddrs(c *nlConn) ([]rtAddr, error) {
+	req := make([]byte, syscall.SizeofIfAddrmsg)
+	req[0] = syscall.AF_UNSPEC
+
+	msgs, err := c.execute(syscall.RTM_GETADDR, syscall.NLM_F_DUMP, req)
+	if err != nil {
+		return nil, err
+	}
+
+	var addrs []rtAddr
+	for _, m := range msgs {
+		if m.Header.Type != syscall.RTM_NEWADDR || len(m.Data) < syscall.SizeofIfAddrmsg {
+			continue
+		}
+
+		addr := rtAddr{
+			Family:    int(m.Data[0]),
+			PrefixLen: int(m.Data[1]),
+			Flags:     uint32(m.Data[2]),
+			Scope:     m.Data[3],
+			Index:     int(binary.NativeEndian.Uint32(m.Data[4:8])),
+		}
+
+		attrs, err := parseAttrs(m.Data[syscall.SizeofIfAddrmsg:])
+		if err != nil {
+			return nil, err
+		}
+
+		var address, local net.IP
+		for _, a := range attrs {
+			switch a.Type {
+			case syscall.IFA_ADDRESS:
+				address = net.IP(append([]byte(nil), a.Data...))
+			case syscall.IFA_LOCAL:
+				local = net.IP(append([]byte(nil), a.Data...))
+			case ifaFlags:
+				// IFA_FLAGS supersedes the 8-bit flags of the header.
+				addr.Flags = attrUint32(a.Data)
+			}
+		}
+
+		// On point-to-point links IFA_ADDRESS is the peer, the local address is in IFA_LOCAL.
+		addr.IP = address
+		if local != nil {
+			addr.IP = local
+		}
+		if addr.IP == nil {
+			continue
+		}
+
+		addrs = append(addrs, addr)
+	}
+
+	return addrs, nil
+}
+
+// Attribute types the syscall package does not define.
+const (
+	iflaStats64       = 23 // IFLA_STATS64
+	iflaLinkNetnsID   = 37 // IFLA_LINK_NETNSID
+	iflaInfoKind      = 1  // IFLA_INFO_KIND
+	iflaInfoData      = 2  // IFLA_INFO_DATA
+	iflaInfoSlaveKind = 4  // IFLA_INFO_SLAVE_KIND
+	iflaInfoSlaveData = 5  // IFLA_INFO_SLAVE_DATA
+	ifaFlags          = 8  // IFA_FLAGS
+)
+
+// addressScopes maps RT_SCOPE_* values to the scope names used by the `ip` command.
+var addressScopes = map[uint8]string{
+	0:   "global",  // RT_SCOPE_UNIVERSE
+	200: "site",    // RT_SCOPE_SITE
+	253: "link",    // RT_SCOPE_LINK
+	254: "host",    // RT_SCOPE_HOST
+	255: "nowhere", // RT_SCOPE_NOWHERE
+}
+
+// addressFlags lists the IFA_F_* address flags and their names.
+var addressFlags = []struct {
+	flag uint32
+	name string
+}{
+	{0x01, "secondary"},       // IFA_F_SECONDARY
+	{0x02, "nodad"},           // IFA_F_NODAD
+	{0x04, "optimistic"},      // IFA_F_OPTIMISTIC
+	{0x08, "dadfailed"},       // IFA_F_DADFAILED
+	{0x10, "homeaddress"},     // IFA_F_HOMEADDRESS
+	{0x20, "deprecated"},      // IFA_F_DEPRECATED
+	{0x40, "tentative"},       // IFA_F_TENTATIVE
+	{0x80, "permanent"},       // IFA_F_PERMANENT
+	{0x100, "managetempaddr"}, // IFA_F_MANAGETEMPADDR
+	{0x200, "noprefixroute"},  // IFA_F_NOPREFIXROUTE
+	{0x400, "mcautojoin"},     // IFA_F_MCAUTOJOIN
+	{0x800, "stable_privacy"}, // IFA_F_STABLE_PRIVACY
+}
+
+// newAddress converts a netlink address message to an api.Address.
+func newAddress(addr rtAddr) api.Address {
+	family := api.FamilyIPv6
+	if addr.Family == syscall.AF_INET {
+		family = api.FamilyIPv4
+	}
+
+	scope, ok := addressScopes[addr.Scope]
+	if !ok {
+		scope = strconv.Itoa(int(addr.Scope))
+	}
+
+	flags := []string{}
+	for _, f := range addressFlags {
+		if addr.Flags&f.flag != 0 {
+			flags = append(flags, f.name)
+		}
+	}
+
+	return api.Address{
+		Address:      addr.IP.String(),
+		PrefixLength: addr.PrefixLen,
+		Family:       family,
+		Scope:        scope,
+		Flags:        flags,
+	}
+}
@@ -3,258 +3,167 @@ package servermodels
 import (
 	"errors"
 	"net"
-	"os/exec"
 	"strconv"
-	"strings"
-)
 
-// NetworkInterface represents details about a network interface.
-type NetworkInterface struct {
-	Name              string   `json:"name"`               // Name of the network interface.
-	IPAddresses       []string `json:"ip_addresses"`       // List of IP addresses associated with the interface.
-	MACAddress        string   `json:"mac_address"`        // MAC address of the interface.
-	MTU               int      `json:"mtu"`                // Maximum Transmission Unit (MTU) of the interface.
-	Speed             string   `json:"speed"`              // Speed of the interface.
-	Duplex            string   `json:"duplex"`             // Duplex mode of the interface.
-	AdminStatus       string   `json:"admin_status"`       // Administrative status of the interface.
-	OperationalStatus string   `json:"operational_status"` // Operational status of the interface.
-}
+	api "apimodule"
+)
 
-// NetworkInterfaces represents a collection of network interfaces.
-type NetworkInterfaces struct {
-	Interfaces []NetworkInterface `json:"network_interface"` // List of network interfaces.
-}
+// ErrNoSuchInterface is returned by a Collector when the requested interface does not exist.
+var ErrNoSuchInterface = errors.New("there is no such interface")
 
-// Error represents an error response.
-type Error struct {
-	Error string `json:"error"` // Error message.
+// Collector gathers details about network interfaces from some source.
+type Collector interface {
+	// Interfaces returns details about all available network interfaces.
+	Interfaces() ([]api.NetworkInterfaceV2, error)
+	// Interface returns the details of a network interface by its name.
+	// It returns ErrNoSuchInterface if the interface doesn't exist.
+	Interface(name string) (*api.NetworkInterfaceV2, error)
 }
 
-// GetInterfaces returns details about all available network interfaces.
-func GetInterfaces() ([]NetworkInterface, error) {
-	// Get all network interfaces
-	ifaces, err := net.Interfaces()
-	if err != nil {
-		return nil, err
-	}
-
-	var interfaces []NetworkInterface
-
-	// Get IPv4 addresses
-	for _, iface := range ifaces {
-		addrs, _ := iface.Addrs()
-		var ipAddrs []string
-		for _, addr := range addrs {
-			ipAddrs = append(ipAddrs, strings.Split(addr.String(), "/")[0])
-		}
-
-		// Get MAC address
-		mac := iface.HardwareAddr.String()
+// Details selects the details of network interfaces that take extra queries to collect.
+type Details uint8
 
-		// Get MTU
-		mtu, err := getMTU(iface.Name)
-		if err != nil {
-			return nil, err
-		}
+const (
+	DetailAddresses Details = 1 << iota // IP addresses.
+	DetailLinkModes                     // Speed and duplex.
+	DetailWireless                      // State of wireless interfaces.
+	DetailStats                         // Traffic counters.
 
-		// Get Speed
-		speed, err := getSpeed(iface.Name)
-		if err != nil {
-			speed = "N/A"
-		}
-
-		// Get Duplex
-		duplex, err := getDuplex(iface.Name)
-		if err != nil {
-			duplex = "N/A"
-		}
-
-		// Get Admin Status
-		adminStatus, err := getAdminStatus(iface.Name)
-		if err != nil {
-			// If error, set admin status to unknown
-			adminStatus = "unknown"
-		}
-
-		// Get Operational Status
-		operationalStatus, err := getOperationalStatus(iface.Name)
-		if err != nil {
-			// If error, set operational status to unknown
-			operationalStatus = "unknown"
-		}
-
-		// Create a NetworkInterface object and append it to the list
-		interfaces = append(interfaces, NetworkInterface{
-			Name:              iface.Name,
-			IPAddresses:       ipAddrs,
-			MACAddress:        mac,
-			MTU:               mtu,
-			Speed:             speed,
-			Duplex:            duplex,
-			AdminStatus:       adminStatus,
-			OperationalStatus: operationalStatus,
-		})
-	}
+	AllDetails = DetailAddresses | DetailLinkModes | DetailWireless | DetailStats
+)
 
-	return interfaces, nil
+// FieldCollector is a Collector that can skip collecting the details nobody asked for.
+// Details that aren't selected are left at their zero value, unless they come at no extra
+// cost along with the others, e.g. the traffic counters in a netlink link dump.
+type FieldCollector interface {
+	Collector
+	// InterfacesWith returns details about all available network interfaces, collecting
+	// only the selected details.
+	InterfacesWith(details Details) ([]api.NetworkInterfaceV2, error)
+	// InterfaceWith returns the details of a network interface by its name, collecting
+	// only the selected details. It returns ErrNoSuchInterface if the interface doesn't exist.
+	InterfaceWith(name string, details Details) (*api.NetworkInterfaceV2, error)
 }
 
-// GetInterfaceByName returns the details of a network interface by its name.
-func GetInterfaceByName(name string) (*NetworkInterface, error) {
-	// Get all network interfaces
-	interfaces, err := GetInterfaces()
-	if err != nil {
-		return nil, err
-	}
-
-	// Search for the interface by name
-	for _, iface := range interfaces {
-		if iface.Name == name {
-			return &iface, nil
-		}
+// CollectInterfaces returns all network interfaces with at least the selected details.
+// The other details are only skipped if the collector is a FieldCollector.
+func CollectInterfaces(c Collector, details Details) ([]api.NetworkInterfaceV2, error) {
+	if fc, ok := c.(FieldCollector); ok {
+		return fc.InterfacesWith(details)
 	}
-
-	// If interface not found, return an error
-	return nil, errors.New("there is no such interface")
+	return c.Interfaces()
 }
 
-// getMTU retrieves the MTU for a given network interface name.
-func getMTU(interfaceName string) (int, error) {
-	// Execute the `ip link show` command to get interface details
-	cmd := exec.Command("ip", "link", "show", interfaceName)
-	output, err := cmd.Output()
-	if err != nil {
-		return 0, err
-	}
-
-	// Parse the output to extract MTU
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "mtu") {
-			fields := strings.Fields(line)
-			for i, field := range fields {
-				if field == "mtu" && i+1 < len(fields) {
-					mtuStr := fields[i+1]
-					mtu, err := strconv.Atoi(mtuStr)
-					if err != nil {
-						return 0, err
-					}
-					return mtu, nil
-				}
-			}
-		}
+// CollectInterface returns a network interface by its name with at least the selected details.
+// The other details are only skipped if the collector is a FieldCollector.
+func CollectInterface(c Collector, name string, details Details) (*api.NetworkInterfaceV2, error) {
+	if fc, ok := c.(FieldCollector); ok {
+		return fc.InterfaceWith(name, details)
 	}
+	return c.Interface(name)
+}
 
-	return 0, errors.New("MTU not found")
+// Configurator is implemented by collectors that can also change the configuration of interfaces.
+// Unknown interfaces yield ErrNoSuchInterface, errors of the kernel are returned as matching
+// syscall.Errno values (e.g. EEXIST for an address that is already assigned), possibly wrapped
+// along with the explanation of the kernel.
+type Configurator interface {
+	// SetMTU changes the Maximum Transmission Unit of an interface.
+	SetMTU(name string, mtu int) error
+	// SetAdminStatus brings an interface administratively up or down.
+	SetAdminStatus(name string, up bool) error
+	// SetAlias changes the description of an interface, an empty alias removes it.
+	SetAlias(name, alias string) error
+	// AddAddress assigns an IP address with the prefix length of its mask to an interface.
+	AddAddress(name string, addr *net.IPNet) error
+	// DeleteAddress removes an IP address with the prefix length of its mask from an interface.
+	DeleteAddress(name string, addr *net.IPNet) error
+	// AddRoute adds a static route through an interface to the main routing table.
+	// The gateway is nil for directly connected destinations.
+	AddRoute(name string, dst *net.IPNet, gateway net.IP, metric uint32) error
+	// DeleteRoute removes a static route through an interface from the main routing table.
+	DeleteRoute(name string, dst *net.IPNet, gateway net.IP, metric uint32) error
 }
 
-// getSpeed retrieves the network speed for a given network interface name using the `ethtool` command.
-func getSpeed(interfaceName string) (string, error) {
-	// Execute the `ethtool` command
-	cmd := exec.Command("ethtool", interfaceName)
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
+// EthtoolCollector is implemented by collectors that can also report the device settings of interfaces.
+type EthtoolCollector interface {
+	// Ethtool returns the driver, link modes, offload features, ring and channel sizes, pause
+	// parameters and transceiver module of an interface. It returns ErrNoSuchInterface if the
+	// interface doesn't exist.
+	Ethtool(name string) (*api.Ethtool, error)
+}
 
-	// Parse the output to extract the network speed
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "Speed:") {
-			fields := strings.Fields(line)
-			if len(fields) >= 2 {
-				speed := fields[1]
-				return speed, nil
-			}
-		}
-	}
+// NeighborCollector is implemented by collectors that can also report the neighbor table.
+type NeighborCollector interface {
+	// Neighbors returns the IPv4 (ARP) and IPv6 (NDP) neighbor table entries of all interfaces.
+	Neighbors() ([]api.Neighbor, error)
+}
 
-	return "", errors.New("speed not found")
+// ErrNoSuchNamespace is returned by a NamespaceCollector when the requested namespace does not exist.
+var ErrNoSuchNamespace = errors.New("there is no such network namespace")
+
+// NamespaceCollector is implemented by collectors that can also report the interfaces of
+// other network namespaces.
+type NamespaceCollector interface {
+	// Namespaces returns the named network namespaces and, if withPIDs is set, the namespaces
+	// of processes that have no name, each referenced by its lowest PID.
+	Namespaces(withPIDs bool) ([]api.Namespace, error)
+	// InNamespace returns a Collector for the interfaces of a network namespace, referenced by
+	// its name in /run/netns or as pid:{pid}. It returns ErrNoSuchNamespace if it doesn't exist.
+	InNamespace(ref string) (Collector, error)
 }
 
-// getDuplex retrieves the duplex mode of a network interface.
-func getDuplex(ifaceName string) (string, error) {
-	// Execute the `ethtool` command to get interface details
-	cmd := exec.Command("ethtool", ifaceName)
-	out, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
+// ErrNoRoute is returned by a RouteCollector when no route matches a destination.
+var ErrNoRoute = errors.New("there is no route to this destination")
+
+// RouteCollector is implemented by collectors that can also report the routing tables.
+type RouteCollector interface {
+	// Routes returns the entries of all IPv4 and IPv6 routing tables.
+	Routes() ([]api.Route, error)
+	// Rules returns the IPv4 and IPv6 policy routing rules.
+	Rules() ([]api.Rule, error)
+	// LookupRoute returns the route the kernel selects for packets to the destination.
+	// It returns ErrNoRoute if the destination is unreachable.
+	LookupRoute(dst net.IP) (*api.Route, error)
+}
 
-	// Parse the output to extract the duplex mode value
-	lines := strings.Split(string(out), "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "Duplex:") {
-			parts := strings.Fields(line)
-			if len(parts) > 1 {
-				return parts[1], nil
-			}
-		}
+// TableName returns the name of a routing table ID as used by the `ip` command, or its number.
+func TableName(table uint32) string {
+	switch table {
+	case rtTableMain:
+		return "main"
+	case rtTableLocal:
+		return "local"
+	case rtTableDefault:
+		return "default"
+	default:
+		return strconv.FormatUint(uint64(table), 10)
 	}
-
-	return "", errors.New("duplex mode not found")
 }
 
-// getAdminStatus returns the administrative status of the given network interface.
-func getAdminStatus(ifaceName string) (string, error) {
-	// Execute the `ip link show` command to get interface details
-	cmd := exec.Command("ip", "link", "show", ifaceName)
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
+// Routing table IDs of linux/rtnetlink.h, named by TableName on every platform.
+const (
+	rtTableDefault = 253 // RT_TABLE_DEFAULT
+	rtTableMain    = 254 // RT_TABLE_MAIN
+	rtTableLocal   = 255 // RT_TABLE_LOCAL
+)
 
-	// Parse the output to extract the administrative status value
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "state") {
-			fields := strings.Fields(line)
-			for i, field := range fields {
-				if field == "state" && i+1 < len(fields) {
-					state := fields[i+1]
-					if state == "UP" {
-						return "enabled", nil
-					} else if state == "DOWN" {
-						return "disabled", nil
-					} else {
-						return "unknown", nil
-					}
-				}
-			}
-		}
+// speedMbps returns a link speed in Mb/s, or nil if the speed is unknown (zero or negative).
+func speedMbps(mbps int64) *int64 {
+	if mbps <= 0 {
+		return nil
 	}
-
-	return "unknown", nil
+	return &mbps
 }
 
-// getOperationalStatus returns the operational status of the given network interface.
-func getOperationalStatus(ifaceName string) (string, error) {
-	// Execute the `ip link show` command to get interface details
-	cmd := exec.Command("ip", "link", "show", ifaceName)
-	output, err := cmd.Output()
-	if err != nil {
-		return "", err
-	}
-
-	// Parse the output to extract the operational status value
-	lines := strings.Split(string(output), "\n")
-	for _, line := range lines {
-		if strings.Contains(line, "state") {
-			fields := strings.Fields(line)
-			for i, field := range fields {
-				if field == "state" && i+1 < len(fields) {
-					state := fields[i+1]
-					if state == "UP" || state == "LOWER_UP" {
-						return "UP", nil
-					} else if state == "DOWN" {
-						return "DOWN", nil
-					} else {
-						return "unknown", nil
-					}
-				}
-			}
-		}
+// duplexMode maps a duplex mode as reported by ethtool and sysfs to one of the Duplex* constants.
+func duplexMode(mode string) string {
+	switch mode {
+	case "half":
+		return api.DuplexHalf
+	case "full":
+		return api.DuplexFull
+	default:
+		return api.DuplexUnknown
 	}
-	// If operational status not found, return an error
-	return "unknown", nil
 }
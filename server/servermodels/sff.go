@@ -0,0 +1,132 @@
+//go:build linux
+
+package servermodels
+
+import (
+	"encoding/binary"
+	"fmt"
+	"strings"
+
+	api "apimodule"
+)
+
+// sffField locates a field in the first 256 bytes of a module EEPROM, the lower page
+// followed by upper page 00h.
+type sffField struct {
+	offset int
+	length int
+}
+
+// sffLayout locates the identification fields of a family of module management interfaces.
+type sffLayout struct {
+	upperPage     bool                     // Whether the fields are on upper page 00h rather than on the lower page.
+	vendorName    sffField                 // ASCII, padded with spaces.
+	vendorOUI     sffField                 // IEEE company ID.
+	partNumber    sffField                 // ASCII, padded with spaces.
+	revision      sffField                 // ASCII, padded with spaces.
+	serialNumber  sffField                 // ASCII, padded with spaces.
+	dateCode      sffField                 // ASCII, YYMMDD followed by a lot code.
+	wavelength    int                      // Offset of the big-endian laser wavelength, 0 if the layout has none.
+	wavelengthDiv int                      // Units of the wavelength per nm.
+	copper        func(eeprom []byte) bool // Whether the module is a copper cable, whose wavelength field means something else.
+}
+
+// SFF-8472 (SFP), SFF-8636 (QSFP) and CMIS (QSFP-DD, OSFP) identification fields.
+var (
+	sff8472Layout = &sffLayout{
+		vendorName:    sffField{20, 16},
+		vendorOUI:     sffField{37, 3},
+		partNumber:    sffField{40, 16},
+		revision:      sffField{56, 4},
+		serialNumber:  sffField{68, 16},
+		dateCode:      sffField{84, 8},
+		wavelength:    60,
+		wavelengthDiv: 1,
+		copper:        func(eeprom []byte) bool { return eeprom[8]&0x0c != 0 }, // Passive or active cable
+	}
+	sff8636Layout = &sffLayout{
+		upperPage:     true,
+		vendorName:    sffField{148, 16},
+		vendorOUI:     sffField{165, 3},
+		partNumber:    sffField{168, 16},
+		revision:      sffField{184, 2},
+		serialNumber:  sffField{196, 16},
+		dateCode:      sffField{212, 8},
+		wavelength:    186,
+		wavelengthDiv: 20,
+		copper:        func(eeprom []byte) bool { return eeprom[147]>>4 >= 0x0a }, // Copper transmitter technology
+	}
+	cmisLayout = &sffLayout{
+		upperPage:    true,
+		vendorName:   sffField{129, 16},
+		vendorOUI:    sffField{145, 3},
+		partNumber:   sffField{148, 16},
+		revision:     sffField{164, 2},
+		serialNumber: sffField{166, 16},
+		dateCode:     sffField{182, 8},
+	}
+)
+
+// sffLayouts maps SFF-8024 identifiers to the layout of their identification fields.
+var sffLayouts = map[uint8]*sffLayout{
+	0x03: sff8472Layout, // SFP/SFP+/SFP28
+	0x0c: sff8636Layout, // QSFP
+	0x0d: sff8636Layout, // QSFP+
+	0x11: sff8636Layout, // QSFP28
+	0x18: cmisLayout,    // QSFP-DD
+	0x19: cmisLayout,    // OSFP
+	0x1e: cmisLayout,    // QSFP+ with CMIS
+}
+
+// sffIdentifiers maps SFF-8024 identifiers to the names of the module types.
+var sffIdentifiers = map[uint8]string{
+	0x01: "GBIC",
+	0x02: "SFF",
+	0x03: "SFP",
+	0x0c: "QSFP",
+	0x0d: "QSFP+",
+	0x11: "QSFP28",
+	0x18: "QSFP-DD",
+	0x19: "OSFP",
+	0x1e: "QSFP+ CMIS",
+}
+
+// parseModuleEEPROM decodes the identification of a module from the lower page of its EEPROM
+// and, for layouts that need it, upper page 00h. Modules of unknown types only report their identifier.
+func parseModuleEEPROM(lower, upper []byte) *api.ModuleInfo {
+	eeprom := make([]byte, 2*modulePageSize)
+	copy(eeprom, lower)
+	copy(eeprom[modulePageSize:], upper)
+
+	id := eeprom[0]
+	info := &api.ModuleInfo{Identifier: sffIdentifiers[id]}
+	if info.Identifier == "" {
+		info.Identifier = fmt.Sprintf("0x%02x", id)
+	}
+
+	layout, ok := sffLayouts[id]
+	if !ok {
+		return info
+	}
+
+	field := func(f sffField) []byte {
+		return eeprom[f.offset : f.offset+f.length]
+	}
+	text := func(f sffField) string {
+		return strings.TrimRight(string(field(f)), " \x00")
+	}
+
+	oui := field(layout.vendorOUI)
+	info.VendorName = text(layout.vendorName)
+	info.VendorOUI = fmt.Sprintf("%02x:%02x:%02x", oui[0], oui[1], oui[2])
+	info.PartNumber = text(layout.partNumber)
+	info.Revision = text(layout.revision)
+	info.SerialNumber = text(layout.serialNumber)
+	info.DateCode = text(layout.dateCode)
+
+	if layout.wavelength != 0 && !layout.copper(eeprom) {
+		info.WavelengthNm = int(binary.BigEndian.Uint16(eeprom[layout.wavelength:])) / layout.wavelengthDiv
+	}
+
+	return info
+}
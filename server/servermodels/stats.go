@@ -0,0 +1,33 @@
+//go:build linux
+
+package servermodels
+
+import (
+	"encoding/binary"
+
+	api "apimodule"
+)
+
+// parseLinkStats64 decodes the leading counters of a struct rtnl_link_stats64 (IFLA_STATS64).
+func parseLinkStats64(b []byte) *api.InterfaceStats {
+	if len(b) < 10*8 {
+		return nil
+	}
+
+	u64 := func(i int) uint64 {
+		return binary.NativeEndian.Uint64(b[i*8:])
+	}
+
+	return &api.InterfaceStats{
+		RxPackets:  u64(0),
+		TxPackets:  u64(1),
+		RxBytes:    u64(2),
+		TxBytes:    u64(3),
+		RxErrors:   u64(4),
+		TxErrors:   u64(5),
+		RxDropped:  u64(6),
+		TxDropped:  u64(7),
+		Multicast:  u64(8),
+		Collisions: u64(9),
+	}
+}
@@ -0,0 +1,274 @@
+package servermodels
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	api "apimodule"
+)
+
+// SysfsCollector is a Collector that reads interface details from /sys/class/net.
+// The sysfs tree is looked up below a configurable root directory, which allows
+// reading a host's /sys mounted into a container or a fixture tree in tests.
+// Sysfs doesn't expose layer 3 configuration, so IP addresses are never reported.
+// Link kinds are derived from the device type, without kind-specific details.
+type SysfsCollector struct {
+	root string // Directory that contains the sys/class/net tree.
+}
+
+// NewSysfsCollector creates a new instance of SysfsCollector reading below the given root.
+// An empty root means the root of the local filesystem.
+func NewSysfsCollector(root string) *SysfsCollector {
+	if root == "" {
+		root = "/"
+	}
+
+	return &SysfsCollector{
+		root: root,
+	}
+}
+
+// Interfaces returns details about all network interfaces found in sys/class/net.
+func (c *SysfsCollector) Interfaces() ([]api.NetworkInterfaceV2, error) {
+	return c.InterfacesWith(AllDetails)
+}
+
+// InterfacesWith returns details about all network interfaces found in sys/class/net,
+// reading only the attributes of the selected details.
+func (c *SysfsCollector) InterfacesWith(details Details) ([]api.NetworkInterfaceV2, error) {
+	entries, err := os.ReadDir(c.classNet())
+	if err != nil {
+		return nil, err
+	}
+
+	interfaces := make([]api.NetworkInterfaceV2, 0, len(entries))
+	for _, entry := range entries {
+		// Interfaces are symlinks to their device directories, regular files like the
+		// bonding_masters file of the bonding driver are not interfaces
+		if entry.Type().IsRegular() {
+			continue
+		}
+
+		iface, err := c.read(entry.Name(), details)
+		if err != nil {
+			return nil, err
+		}
+		interfaces = append(interfaces, *iface)
+	}
+
+	return interfaces, nil
+}
+
+// Interface returns the details of a network interface by its name.
+func (c *SysfsCollector) Interface(name string) (*api.NetworkInterfaceV2, error) {
+	return c.InterfaceWith(name, AllDetails)
+}
+
+// InterfaceWith returns the details of a network interface by its name, reading only the
+// attributes of the selected details.
+func (c *SysfsCollector) InterfaceWith(name string, details Details) (*api.NetworkInterfaceV2, error) {
+	// Reject names that would escape the sys/class/net directory
+	if name == "" || name == "." || name == ".." || strings.ContainsRune(name, '/') {
+		return nil, ErrNoSuchInterface
+	}
+
+	info, err := os.Stat(filepath.Join(c.classNet(), name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, ErrNoSuchInterface
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !info.IsDir() {
+		return nil, ErrNoSuchInterface
+	}
+
+	return c.read(name, details)
+}
+
+// classNet returns the path of the sys/class/net directory below the root.
+func (c *SysfsCollector) classNet() string {
+	return filepath.Join(c.root, "sys", "class", "net")
+}
+
+// read assembles an api.NetworkInterfaceV2 from the attribute files of a single interface.
+// The attributes of details that aren't selected are left unread.
+func (c *SysfsCollector) read(name string, details Details) (*api.NetworkInterfaceV2, error) {
+	// Get MTU
+	mtu, err := c.readInt(name, "mtu")
+	if err != nil {
+		return nil, err
+	}
+
+	// Get MAC address
+	mac, err := c.readString(name, "address")
+	if err != nil {
+		return nil, err
+	}
+
+	// Get Speed, which the kernel refuses to report for devices without link settings, and Duplex
+	var speed *int64
+	duplex := api.DuplexNone
+	if details&DetailLinkModes != 0 {
+		if mbps, err := c.readInt(name, "speed"); err == nil {
+			speed, duplex = speedMbps(mbps), api.DuplexUnknown
+		}
+		if mode, err := c.readString(name, "duplex"); err == nil {
+			duplex = duplexMode(mode)
+		}
+	}
+
+	// Get Admin Status from the IFF_UP device flag
+	admin := api.AdminStatusDown
+	if flags, err := c.readString(name, "flags"); err == nil {
+		if v, err := strconv.ParseUint(strings.TrimPrefix(flags, "0x"), 16, 32); err == nil {
+			admin = adminStatus(uint32(v))
+		}
+	}
+
+	// Get the alias, the attribute is empty if none is set
+	alias, _ := c.readString(name, "ifalias")
+
+	// Get Operational Status
+	operational := api.OperStatusUnknown
+	if state, err := c.readString(name, "operstate"); err == nil {
+		operational = operStatus(sysfsOperStates[state])
+	}
+
+	iface := &api.NetworkInterfaceV2{
+		Name:        name,
+		Kind:        c.readKind(name),
+		Alias:       alias,
+		MACAddress:  mac,
+		MTU:         int(mtu),
+		SpeedMbps:   speed,
+		Duplex:      duplex,
+		AdminStatus: admin,
+		OperStatus:  operational,
+		Master:      c.readMaster(name),
+		Slaves:      c.readSlaves(name),
+	}
+	if details&DetailAddresses != 0 {
+		iface.Addresses = []api.Address{}
+	}
+	if details&DetailStats != 0 {
+		iface.Stats = c.readStats(name)
+	}
+
+	return iface, nil
+}
+
+// readKind returns the link kind from the DEVTYPE of the uevent attribute of an interface.
+// Devices without a device type, or with one that isn't a link kind (e.g. wlan), are reported as devices.
+func (c *SysfsCollector) readKind(name string) string {
+	uevent, err := c.readString(name, "uevent")
+	if err != nil {
+		return api.KindDevice
+	}
+
+	for _, line := range strings.Split(uevent, "\n") {
+		switch devtype, _ := strings.CutPrefix(line, "DEVTYPE="); devtype {
+		case api.KindBond, api.KindBridge, api.KindVLAN, api.KindVXLAN:
+			return devtype
+		}
+	}
+
+	return api.KindDevice
+}
+
+// readMaster returns the name of the bond or bridge an interface is enslaved to,
+// which the master symlink points to.
+func (c *SysfsCollector) readMaster(name string) string {
+	target, err := os.Readlink(filepath.Join(c.classNet(), name, "master"))
+	if err != nil {
+		return ""
+	}
+	return filepath.Base(target)
+}
+
+// readSlaves returns the names of the interfaces enslaved to a bond (bonding/slaves)
+// or attached to a bridge (brif).
+func (c *SysfsCollector) readSlaves(name string) []string {
+	if slaves, err := c.readString(name, filepath.Join("bonding", "slaves")); err == nil {
+		return strings.Fields(slaves)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(c.classNet(), name, "brif"))
+	if err != nil {
+		return nil
+	}
+
+	var ports []string
+	for _, entry := range entries {
+		ports = append(ports, entry.Name())
+	}
+	return ports
+}
+
+// readStats reads the traffic counters from the statistics directory of an interface.
+// It returns nil if the counters are not available.
+func (c *SysfsCollector) readStats(name string) *api.InterfaceStats {
+	var stats api.InterfaceStats
+	counters := []struct {
+		attr  string
+		value *uint64
+	}{
+		{"rx_bytes", &stats.RxBytes},
+		{"tx_bytes", &stats.TxBytes},
+		{"rx_packets", &stats.RxPackets},
+		{"tx_packets", &stats.TxPackets},
+		{"rx_errors", &stats.RxErrors},
+		{"tx_errors", &stats.TxErrors},
+		{"rx_dropped", &stats.RxDropped},
+		{"tx_dropped", &stats.TxDropped},
+		{"multicast", &stats.Multicast},
+		{"collisions", &stats.Collisions},
+	}
+
+	for _, counter := range counters {
+		s, err := c.readString(name, filepath.Join("statistics", counter.attr))
+		if err != nil {
+			return nil
+		}
+		v, err := strconv.ParseUint(s, 10, 64)
+		if err != nil {
+			return nil
+		}
+		*counter.value = v
+	}
+
+	return &stats
+}
+
+// readString reads a single sysfs attribute of an interface without the trailing newline.
+func (c *SysfsCollector) readString(name, attr string) (string, error) {
+	b, err := os.ReadFile(filepath.Join(c.classNet(), name, attr))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+// readInt reads a single integer sysfs attribute of an interface.
+func (c *SysfsCollector) readInt(name, attr string) (int64, error) {
+	s, err := c.readString(name, attr)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseInt(s, 10, 64)
+}
+
+// sysfsOperStates maps the values of the operstate sysfs attribute to IFLA_OPERSTATE values.
+// Unlisted values map to the zero value, operUnknown.
+var sysfsOperStates = map[string]uint8{
+	"notpresent":     operNotPresent,
+	"down":           operDown,
+	"lowerlayerdown": operLowerLayerDown,
+	"testing":        operTesting,
+	"dormant":        operDormant,
+	"up":             operUp,
+}
@@ -0,0 +1,223 @@
+package server
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	api "apimodule"
+	models "servermodule/servermodels"
+)
+
+// maxConfirmTimeout limits how long changes in commit-confirmed mode can stay unconfirmed.
+const maxConfirmTimeout = time.Hour
+
+// errPendingChange is wrapped by the errors of write operations on an interface that has
+// unconfirmed changes, whose rollback would otherwise also revert the later changes.
+var errPendingChange = errors.New("confirm or roll it back first")
+
+// errNoPendingChange is returned for confirmations and rollbacks of unknown changes.
+var errNoPendingChange = errors.New("there is no pending change with this ID, it may have been rolled back already")
+
+// pendingChanges serializes the write operations and keeps the changes applied in
+// commit-confirmed mode until they are confirmed or rolled back.
+type pendingChanges struct {
+	mu      sync.Mutex
+	changes map[string]*pendingChange // By ID.
+}
+
+// pendingChange is an unconfirmed change along with the means to roll it back.
+type pendingChange struct {
+	api.PendingChange
+	configurator models.Configurator
+	timer        *time.Timer // Rolls the change back at its deadline.
+}
+
+// newPendingChanges creates an empty registry of pending changes.
+func newPendingChanges() *pendingChanges {
+	return &pendingChanges{changes: make(map[string]*pendingChange)}
+}
+
+// apply applies changes to an interface that has no pending change. With a timeout, the changes
+// are kept pending and rolled back unless they are confirmed before the timeout expires.
+func (p *pendingChanges) apply(c models.Configurator, name string, changes []api.FieldChange, timeout time.Duration) (*api.PendingChange, error) {
+	pending, err := p.applyAll(c, []api.ChangeSet{{Interface: name, Changes: changes}}, timeout)
+	if err != nil || len(pending) == 0 {
+		return nil, err
+	}
+	return &pending[0], nil
+}
+
+// applyAll applies the change sets of interfaces that have no pending change, in order. If a
+// change set fails, the change sets applied before it are reverted as well. With a timeout, each
+// change set is kept pending on its own and rolled back unless it's confirmed before the timeout expires.
+// Empty change sets are skipped, they neither conflict with pending changes nor become pending.
+func (p *pendingChanges) applyAll(c models.Configurator, sets []api.ChangeSet, timeout time.Duration) ([]api.PendingChange, error) {
+	sets = slices.DeleteFunc(slices.Clone(sets), func(set api.ChangeSet) bool { return len(set.Changes) == 0 })
+	if len(sets) == 0 {
+		return nil, nil
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, set := range sets {
+		for _, change := range p.changes {
+			if change.Interface == set.Interface {
+				return nil, fmt.Errorf("interface %s has unconfirmed change %s; %w", set.Interface, change.ID, errPendingChange)
+			}
+		}
+	}
+
+	for i, set := range sets {
+		if err := applyChanges(c, set.Interface, set.Changes); err != nil {
+			for j := i - 1; j >= 0; j-- {
+				if revertErr := revertChanges(c, sets[j].Interface, sets[j].Changes); revertErr != nil {
+					log.Printf("Reverting the changes of %s: %v", sets[j].Interface, revertErr)
+				}
+			}
+			return nil, err
+		}
+	}
+	if timeout == 0 {
+		return nil, nil
+	}
+
+	pending := make([]api.PendingChange, 0, len(sets))
+	deadline := time.Now().Add(timeout)
+	for _, set := range sets {
+		change := &pendingChange{
+			PendingChange: api.PendingChange{ID: newChangeID(), Interface: set.Interface, Changes: set.Changes, Deadline: deadline},
+			configurator:  c,
+		}
+		change.timer = time.AfterFunc(timeout, func() {
+			p.expire(change.ID, change.Interface)
+		})
+		p.changes[change.ID] = change
+		pending = append(pending, change.PendingChange)
+	}
+
+	return pending, nil
+}
+
+// confirm keeps the changes of a pending change and stops their rollback.
+func (p *pendingChanges) confirm(id string) (*api.PendingChange, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	change, ok := p.changes[id]
+	if !ok {
+		return nil, errNoPendingChange
+	}
+	change.timer.Stop()
+	delete(p.changes, id)
+
+	return &change.PendingChange, nil
+}
+
+// rollback reverts the changes of a pending change in reverse order. The change is no longer
+// pending afterwards, even if reverting some of its changes failed.
+func (p *pendingChanges) rollback(id string) (*api.PendingChange, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	change, ok := p.changes[id]
+	if !ok {
+		return nil, errNoPendingChange
+	}
+	change.timer.Stop()
+	delete(p.changes, id)
+
+	return &change.PendingChange, revertChanges(change.configurator, change.Interface, change.Changes)
+}
+
+// expire rolls back a pending change that wasn't confirmed in time and logs the outcome.
+// Changes that were confirmed or rolled back in the meantime are left alone.
+func (p *pendingChanges) expire(id, name string) {
+	if _, err := p.rollback(id); err == nil {
+		log.Printf("Rolled back unconfirmed change %s of %s", id, name)
+	} else if !errors.Is(err, errNoPendingChange) {
+		log.Printf("Rolling back unconfirmed change %s of %s: %v", id, name, err)
+	}
+}
+
+// rollbackAll rolls back every pending change, e.g. before the server stops.
+func (p *pendingChanges) rollbackAll() {
+	for _, change := range p.list() {
+		p.expire(change.ID, change.Interface)
+	}
+}
+
+// list returns the pending changes ordered by deadline.
+func (p *pendingChanges) list() []api.PendingChange {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	changes := make([]api.PendingChange, 0, len(p.changes))
+	for _, change := range p.changes {
+		changes = append(changes, change.PendingChange)
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].Deadline.Before(changes[j].Deadline)
+	})
+
+	return changes
+}
+
+// newChangeID returns a random identifier for a pending change.
+func newChangeID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		panic(err) // crypto/rand doesn't fail on Linux
+	}
+	return hex.EncodeToString(b)
+}
+
+// The changesHandler() method is the handler function for the /v2/changes endpoint.
+// It lists the changes applied in commit-confirmed mode that aren't confirmed yet.
+func (s *server) changesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.respond(w, http.StatusOK, api.PendingChanges{Changes: s.changes.list()})
+	}
+}
+
+// The confirmChangeHandler() method is the handler function for POST requests to the
+// /v2/changes/{id}/confirm endpoint. It keeps the changes of a pending change.
+func (s *server) confirmChangeHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		change, err := s.changes.confirm(r.PathValue("id"))
+		if err != nil {
+			s.error(w, http.StatusNotFound, err)
+			return
+		}
+
+		log.Printf("Confirmed change %s of %s", change.ID, change.Interface)
+		s.respond(w, http.StatusOK, change)
+	}
+}
+
+// The rollbackChangeHandler() method is the handler function for DELETE requests to the
+// /v2/changes/{id} endpoint. It rolls back a pending change before its deadline.
+func (s *server) rollbackChangeHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		change, err := s.changes.rollback(r.PathValue("id"))
+		if errors.Is(err, errNoPendingChange) {
+			s.error(w, http.StatusNotFound, err)
+			return
+		}
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+
+		log.Printf("Rolled back change %s of %s", change.ID, change.Interface)
+		s.respond(w, http.StatusOK, change)
+	}
+}
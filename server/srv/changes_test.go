@@ -0,0 +1,154 @@
+package server
+
+import (
+	"errors"
+	"net"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	api "apimodule"
+)
+
+// fakeConfigurator records the configuration of a single interface, rejecting MTUs above 9000.
+type fakeConfigurator struct {
+	mu        sync.Mutex
+	mtu       int
+	alias     string
+	addresses []string
+}
+
+func (c *fakeConfigurator) SetMTU(name string, mtu int) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if mtu > 9000 {
+		return syscall.EINVAL
+	}
+	c.mtu = mtu
+	return nil
+}
+
+func (c *fakeConfigurator) SetAdminStatus(name string, up bool) error {
+	return nil
+}
+
+func (c *fakeConfigurator) SetAlias(name, alias string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.alias = alias
+	return nil
+}
+
+func (c *fakeConfigurator) AddAddress(name string, addr *net.IPNet) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.addresses = append(c.addresses, addr.String())
+	return nil
+}
+
+func (c *fakeConfigurator) DeleteAddress(name string, addr *net.IPNet) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for i, address := range c.addresses {
+		if address == addr.String() {
+			c.addresses = append(c.addresses[:i], c.addresses[i+1:]...)
+			return nil
+		}
+	}
+	return syscall.EADDRNOTAVAIL
+}
+
+func (c *fakeConfigurator) AddRoute(name string, dst *net.IPNet, gateway net.IP, metric uint32) error {
+	return nil
+}
+
+func (c *fakeConfigurator) DeleteRoute(name string, dst *net.IPNet, gateway net.IP, metric uint32) error {
+	return nil
+}
+
+// state returns the recorded MTU, alias and number of addresses.
+func (c *fakeConfigurator) state() (int, string, int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.mtu, c.alias, len(c.addresses)
+}
+
+// TestApplyChangesReverts tests that the changes applied before a failing change are reverted.
+func TestApplyChangesReverts(t *testing.T) {
+	c := &fakeConfigurator{mtu: 1500}
+	changes := []api.FieldChange{
+		{Field: api.FieldAlias, Old: "", New: "uplink"},
+		{Field: api.FieldAddresses, New: "192.0.2.10/24"},
+		{Field: api.FieldMTU, Old: "1500", New: "65536"},
+	}
+
+	err := applyChanges(c, "eth0", changes)
+	if !errors.Is(err, syscall.EINVAL) {
+		t.Fatalf("unexpected error: got %v, want EINVAL", err)
+	}
+	if mtu, alias, addresses := c.state(); mtu != 1500 || alias != "" || addresses != 0 {
+		t.Errorf("changes not reverted: mtu %d, alias %q, %d addresses", mtu, alias, addresses)
+	}
+}
+
+// TestPendingChanges tests that pending changes are rolled back at their deadline unless they are
+// confirmed, and that they block other changes of the same interface in the meantime.
+func TestPendingChanges(t *testing.T) {
+	c := &fakeConfigurator{mtu: 1500}
+	p := newPendingChanges()
+	mtuChange := []api.FieldChange{{Field: api.FieldMTU, Old: "1500", New: "9000"}}
+	aliasChange := []api.FieldChange{{Field: api.FieldAlias, Old: "", New: "uplink"}}
+
+	pending, err := p.apply(c, "eth0", mtuChange, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mtu, _, _ := c.state(); mtu != 9000 {
+		t.Fatalf("change not applied: mtu %d", mtu)
+	}
+	if _, err := p.apply(c, "eth0", aliasChange, 0); !errors.Is(err, errPendingChange) {
+		t.Errorf("unexpected error for a change of an interface with a pending change: %v", err)
+	} else if expected := "interface eth0 has unconfirmed change " + pending.ID + "; confirm or roll it back first"; err.Error() != expected {
+		t.Errorf("unexpected error message: got %q want %q", err.Error(), expected)
+	}
+
+	// Empty change sets don't become pending, nor do they conflict with the pending change
+	if empty, err := p.apply(c, "eth0", nil, 50*time.Millisecond); empty != nil || err != nil {
+		t.Errorf("unexpected result of an empty change: %+v, %v", empty, err)
+	}
+	if empty, err := p.applyAll(c, []api.ChangeSet{{Interface: "eth0"}, {Interface: "eth1"}}, 50*time.Millisecond); empty != nil || err != nil {
+		t.Errorf("unexpected result of empty change sets: %+v, %v", empty, err)
+	}
+	if changes := p.list(); len(changes) != 1 || changes[0].ID != pending.ID {
+		t.Errorf("unexpected pending changes: %+v", changes)
+	}
+
+	// The change is rolled back at its deadline
+	deadline := time.Now().Add(time.Second)
+	for mtu, _, _ := c.state(); mtu != 1500; mtu, _, _ = c.state() {
+		if time.Now().After(deadline) {
+			t.Fatalf("change not rolled back: mtu %d", mtu)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if _, err := p.confirm(pending.ID); !errors.Is(err, errNoPendingChange) {
+		t.Errorf("unexpected error confirming a rolled back change: %v", err)
+	}
+
+	// A confirmed change is kept past its deadline
+	pending, err = p.apply(c, "eth0", aliasChange, 50*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.confirm(pending.ID); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	if _, alias, _ := c.state(); alias != "uplink" {
+		t.Errorf("confirmed change rolled back: alias %q", alias)
+	}
+	if changes := p.list(); len(changes) != 0 {
+		t.Errorf("unexpected pending changes: %+v", changes)
+	}
+}
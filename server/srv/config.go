@@ -0,0 +1,78 @@
+package server
+
+import (
+	"os"
+	"strconv"
+	"time"
+)
+
+// Config represents the configuration for the server.
+type Config struct {
+	Port             string        // Port to listen on for incoming HTTP requests.
+	GRPCPort         string        // Port to listen on for incoming gRPC requests, the gRPC service is disabled if empty.
+	SampleInterval   time.Duration // Interval at which the background sampler polls the collector.
+	HistorySize      int           // Number of samples kept per interface.
+	EventBacklog     int           // Number of events kept for clients resuming the event stream.
+	V1Deprecation    time.Time     // Date the v1 API was deprecated, announced in the Deprecation header.
+	V1Sunset         time.Time     // Date the v1 API will be removed, announced in the Sunset header.
+	NetnsPIDs        bool          // Whether network namespaces of processes are listed and served by PID.
+	WriteToken       string        // Bearer token granting write access, write operations are disabled if empty.
+	DesiredStateFile string        // File the desired state of the interfaces is persisted to, kept in memory only if empty.
+}
+
+// NewConfig creates a new instance of Config and reads configuration from environment variables.
+func NewConfig() *Config {
+	port := os.Getenv("PORT")
+	if port == "" {
+		port = ":8080" // Default port if not provided
+	}
+
+	grpcPort := os.Getenv("GRPC_PORT") // The gRPC service is disabled if not provided
+
+	sampleInterval, err := time.ParseDuration(os.Getenv("SAMPLE_INTERVAL"))
+	if err != nil || sampleInterval <= 0 {
+		sampleInterval = 5 * time.Second // Default sample interval if not provided
+	}
+
+	historySize, err := strconv.Atoi(os.Getenv("HISTORY_SIZE"))
+	if err != nil || historySize <= 0 {
+		historySize = 720 // Default history size if not provided, one hour at the default interval
+	}
+
+	eventBacklog, err := strconv.Atoi(os.Getenv("EVENT_BACKLOG"))
+	if err != nil || eventBacklog <= 0 {
+		eventBacklog = 1000 // Default event backlog if not provided
+	}
+
+	v1Deprecation, err := time.Parse(time.RFC3339, os.Getenv("V1_DEPRECATION"))
+	if err != nil {
+		v1Deprecation = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC) // Default deprecation date, the release of v2
+	}
+
+	v1Sunset, err := time.Parse(time.RFC3339, os.Getenv("V1_SUNSET"))
+	if err != nil {
+		v1Sunset = v1Deprecation.AddDate(0, 6, 0) // Default sunset date if not provided, six months after the deprecation
+	}
+
+	netnsPIDs, err := strconv.ParseBool(os.Getenv("NETNS_PIDS"))
+	if err != nil {
+		netnsPIDs = false // Only named network namespaces by default
+	}
+
+	writeToken := os.Getenv("WRITE_TOKEN") // Write operations are disabled if not provided
+
+	desiredStateFile := os.Getenv("DESIRED_STATE_FILE") // The desired state is kept in memory only if not provided
+
+	return &Config{
+		Port:             port,
+		GRPCPort:         grpcPort,
+		SampleInterval:   sampleInterval,
+		HistorySize:      historySize,
+		EventBacklog:     eventBacklog,
+		V1Deprecation:    v1Deprecation,
+		V1Sunset:         v1Sunset,
+		NetnsPIDs:        netnsPIDs,
+		WriteToken:       writeToken,
+		DesiredStateFile: desiredStateFile,
+	}
+}
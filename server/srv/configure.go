@@ -0,0 +1,438 @@
+package server
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	api "apimodule"
+	models "servermodule/servermodels"
+
+	"gopkg.in/yaml.v3"
+)
+
+// maxRequestBody limits the size of the request body of write operations.
+const maxRequestBody = 64 << 10
+
+// The writeAccess() method guards the handler of a write operation. Write operations are disabled
+// unless a write token is configured, which requests then have to present as a bearer token.
+func (s *server) writeAccess(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// The response format is checked up front, so that a write isn't applied without a response
+		if !s.acceptable(w) {
+			return
+		}
+
+		if s.writeToken == "" {
+			s.error(w, http.StatusForbidden, errors.New("write operations are disabled"))
+			return
+		}
+
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.writeToken)) != 1 {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="interfacer"`)
+			s.error(w, http.StatusUnauthorized, errors.New("a valid write token is required"))
+			return
+		}
+
+		log.Printf("Write operation: %s %s from %s", r.Method, r.URL.Path, r.RemoteAddr)
+		next(w, r)
+	}
+}
+
+// writeMode is the mode of a write operation, selected by its query parameters.
+type writeMode struct {
+	dryRun  bool          // Compute the changes without applying them.
+	timeout time.Duration // Roll the changes back unless confirmed within the timeout, 0 to keep them right away.
+}
+
+// errInvalidWriteQuery is returned when a write operation receives unsupported query parameters.
+var errInvalidWriteQuery = errors.New("only ?dry_run={true|false} and ?confirm_timeout={seconds} input formats are allowed")
+
+// parseWriteMode reads the mode of a write operation from the ?dry_run= and ?confirm_timeout=
+// query parameters, which can't be combined.
+func parseWriteMode(r *http.Request) (writeMode, error) {
+	var mode writeMode
+	for key, values := range r.URL.Query() {
+		if len(values) != 1 {
+			return mode, errInvalidWriteQuery
+		}
+
+		switch key {
+		case "dry_run":
+			dryRun, err := strconv.ParseBool(values[0])
+			if err != nil {
+				return mode, errInvalidWriteQuery
+			}
+			mode.dryRun = dryRun
+		case "confirm_timeout":
+			seconds, err := strconv.Atoi(values[0])
+			if err != nil || seconds <= 0 || time.Duration(seconds)*time.Second > maxConfirmTimeout {
+				return mode, fmt.Errorf("invalid confirm_timeout: expected a number of seconds between 1 and %d", int(maxConfirmTimeout.Seconds()))
+			}
+			mode.timeout = time.Duration(seconds) * time.Second
+		default:
+			return mode, errInvalidWriteQuery
+		}
+	}
+
+	if mode.dryRun && mode.timeout != 0 {
+		return mode, errors.New("dry_run and confirm_timeout can't be combined")
+	}
+	return mode, nil
+}
+
+// The patchInterfaceHandler() method is the handler function for PATCH requests to the
+// /v2/network/{name} endpoint. It changes the MTU, the alias and the administrative status
+// of an interface, in this order, and returns the resulting state of the interface.
+func (s *server) patchInterfaceHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		configurator, mode, ok := s.writeRequest(w, r)
+		if !ok {
+			return
+		}
+
+		var patch api.InterfacePatch
+		if !s.decodeBody(w, r, &patch) {
+			return
+		}
+		if err := validatePatch(patch); err != nil {
+			s.error(w, http.StatusBadRequest, err)
+			return
+		}
+
+		iface, ok := s.pathInterface(w, r, models.AllDetails)
+		if !ok {
+			return
+		}
+
+		s.commit(w, r, configurator, mode, iface.Name, planPatch(iface, patch), http.StatusOK)
+	}
+}
+
+// validatePatch checks that a patch changes something and that its values are valid.
+func validatePatch(patch api.InterfacePatch) error {
+	if patch.MTU == nil && patch.AdminStatus == nil && patch.Alias == nil {
+		return errors.New("the patch has to change at least one of mtu, admin_status and alias")
+	}
+	if patch.MTU != nil && *patch.MTU <= 0 {
+		return errors.New("invalid mtu: expected a positive number")
+	}
+	if patch.AdminStatus != nil && *patch.AdminStatus != api.AdminStatusUp && *patch.AdminStatus != api.AdminStatusDown {
+		return fmt.Errorf("invalid admin_status: expected %s or %s", api.AdminStatusUp, api.AdminStatusDown)
+	}
+	return nil
+}
+
+// planPatch computes the changes a patch makes to the current state of an interface.
+// Fields that already have the requested value are left out.
+func planPatch(iface *api.NetworkInterfaceV2, patch api.InterfacePatch) []api.FieldChange {
+	changes := []api.FieldChange{}
+	if patch.MTU != nil && *patch.MTU != iface.MTU {
+		changes = append(changes, api.FieldChange{Field: api.FieldMTU, Old: strconv.Itoa(iface.MTU), New: strconv.Itoa(*patch.MTU)})
+	}
+	if patch.Alias != nil && *patch.Alias != iface.Alias {
+		changes = append(changes, api.FieldChange{Field: api.FieldAlias, Old: iface.Alias, New: *patch.Alias})
+	}
+	if patch.AdminStatus != nil && *patch.AdminStatus != iface.AdminStatus {
+		changes = append(changes, api.FieldChange{Field: api.FieldAdminStatus, Old: iface.AdminStatus, New: *patch.AdminStatus})
+	}
+	return changes
+}
+
+// The addAddressHandler() method is the handler function for POST requests to the
+// /v2/network/{name}/addresses endpoint. It assigns an address to an interface and
+// returns the resulting state of the interface.
+func (s *server) addAddressHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		configurator, mode, ok := s.writeRequest(w, r)
+		if !ok {
+			return
+		}
+
+		var request api.AddressRequest
+		if !s.decodeBody(w, r, &request) {
+			return
+		}
+
+		ip, prefix, err := net.ParseCIDR(request.Address)
+		if err != nil {
+			s.error(w, http.StatusBadRequest, errors.New("invalid address: expected an IP address with its prefix length in CIDR notation"))
+			return
+		}
+		addr := &net.IPNet{IP: ip, Mask: prefix.Mask}
+
+		iface, ok := s.pathInterface(w, r, models.AllDetails)
+		if !ok {
+			return
+		}
+		if assignedAddress(iface, ip) != nil {
+			s.writeError(w, fmt.Errorf("%s is already assigned to %s: %w", ip, iface.Name, syscall.EEXIST))
+			return
+		}
+
+		if !mode.dryRun {
+			w.Header().Set("Location", "/v2/network/"+iface.Name+"/addresses/"+ip.String())
+		}
+		changes := []api.FieldChange{{Field: api.FieldAddresses, New: addr.String()}}
+		s.commit(w, r, configurator, mode, iface.Name, changes, http.StatusCreated)
+	}
+}
+
+// The deleteAddressHandler() method is the handler function for DELETE requests to the
+// /v2/network/{name}/addresses/{address} endpoint. It removes an address, given without its
+// prefix length, from an interface and returns the resulting state of the interface.
+func (s *server) deleteAddressHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		configurator, mode, ok := s.writeRequest(w, r)
+		if !ok {
+			return
+		}
+
+		ip := net.ParseIP(r.PathValue("address"))
+		if ip == nil {
+			s.error(w, http.StatusBadRequest, errors.New("invalid address: expected an IP address without prefix length"))
+			return
+		}
+
+		iface, ok := s.pathInterface(w, r, models.AllDetails)
+		if !ok {
+			return
+		}
+
+		// The kernel needs the prefix length to identify IPv6 addresses
+		addr := assignedAddress(iface, ip)
+		if addr == nil {
+			s.error(w, http.StatusNotFound, errors.New("the address is not assigned to this interface"))
+			return
+		}
+
+		changes := []api.FieldChange{{Field: api.FieldAddresses, Old: addr.String()}}
+		s.commit(w, r, configurator, mode, iface.Name, changes, http.StatusOK)
+	}
+}
+
+// assignedAddress returns the address of the interface matching the IP, along with its prefix
+// length, or nil if the IP isn't assigned to the interface.
+func assignedAddress(iface *api.NetworkInterfaceV2, ip net.IP) *net.IPNet {
+	for _, address := range iface.Addresses {
+		if assigned := net.ParseIP(address.Address); assigned != nil && assigned.Equal(ip) {
+			ip = ipForm(ip)
+			return &net.IPNet{IP: ip, Mask: net.CIDRMask(address.PrefixLength, len(ip)*8)}
+		}
+	}
+	return nil
+}
+
+// The writeRequest() method prepares a write operation, returning the configurator of the
+// collector and the mode selected by the query parameters. It responds with an error and
+// reports false if the collector can't change interfaces or the parameters are invalid.
+func (s *server) writeRequest(w http.ResponseWriter, r *http.Request) (models.Configurator, writeMode, bool) {
+	configurator, ok := capability[models.Configurator](s, w)
+	if !ok {
+		return nil, writeMode{}, false
+	}
+
+	mode, err := parseWriteMode(r)
+	if err != nil {
+		s.error(w, http.StatusBadRequest, err)
+		return nil, writeMode{}, false
+	}
+
+	return configurator, mode, true
+}
+
+// The commit() method carries out the changes of a write operation on an interface in its mode.
+// In dry-run mode it responds with the changes only. Otherwise it applies them and responds with
+// the given status code and the resulting state of the interface, which is wrapped in the pending
+// change in commit-confirmed mode.
+func (s *server) commit(w http.ResponseWriter, r *http.Request, c models.Configurator, mode writeMode, name string, changes []api.FieldChange, code int) {
+	if mode.dryRun {
+		s.respond(w, http.StatusOK, api.ChangeSet{Interface: name, DryRun: true, Changes: changes})
+		return
+	}
+
+	pending, err := s.changes.apply(c, name, changes, mode.timeout)
+	if err != nil {
+		s.writeError(w, err)
+		return
+	}
+
+	iface, ok := s.pathInterface(w, r, models.AllDetails)
+	if !ok {
+		return
+	}
+
+	if pending == nil {
+		s.respond(w, code, iface)
+		return
+	}
+
+	log.Printf("Applied change %s of %s, rolled back at %s unless confirmed", pending.ID, name, pending.Deadline.Format(time.RFC3339))
+	pending.State = iface
+	s.respond(w, code, pending)
+}
+
+// applyChanges applies changes to an interface in order. If a change fails, the changes
+// applied before it are reverted, so that the interface is left as it was.
+func applyChanges(c models.Configurator, name string, changes []api.FieldChange) error {
+	for i, change := range changes {
+		if err := applyChange(c, name, change, false); err != nil {
+			if revertErr := revertChanges(c, name, changes[:i]); revertErr != nil {
+				log.Printf("Reverting the changes of %s: %v", name, revertErr)
+			}
+			return err
+		}
+	}
+	return nil
+}
+
+// revertChanges reverts changes of an interface in reverse order. It reverts as many
+// changes as possible and returns the errors of those that failed.
+func revertChanges(c models.Configurator, name string, changes []api.FieldChange) error {
+	var errs []error
+	for i := len(changes) - 1; i >= 0; i-- {
+		if err := applyChange(c, name, changes[i], true); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// applyChange sets a field of an interface to the new value of the change, or back to
+// the old value if the change is reverted.
+func applyChange(c models.Configurator, name string, change api.FieldChange, revert bool) error {
+	value, previous := change.New, change.Old
+	if revert {
+		value, previous = previous, value
+	}
+
+	switch change.Field {
+	case api.FieldMTU:
+		mtu, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		if err := c.SetMTU(name, mtu); err != nil {
+			return fmt.Errorf("setting the MTU of %s to %d: %w", name, mtu, err)
+		}
+	case api.FieldAlias:
+		if err := c.SetAlias(name, value); err != nil {
+			return fmt.Errorf("setting the alias of %s: %w", name, err)
+		}
+	case api.FieldAdminStatus:
+		if err := c.SetAdminStatus(name, value == api.AdminStatusUp); err != nil {
+			return fmt.Errorf("setting %s %s: %w", name, value, err)
+		}
+	case api.FieldAddresses:
+		// Addresses are added if the value is set and removed otherwise
+		cidr := value
+		if cidr == "" {
+			cidr = previous
+		}
+		ip, prefix, err := net.ParseCIDR(cidr)
+		if err != nil {
+			return err
+		}
+		addr := &net.IPNet{IP: ip, Mask: prefix.Mask}
+
+		if value != "" {
+			if err := c.AddAddress(name, addr); err != nil {
+				return fmt.Errorf("adding %s to %s: %w", addr, name, err)
+			}
+		} else if err := c.DeleteAddress(name, addr); err != nil {
+			return fmt.Errorf("removing %s from %s: %w", addr, name, err)
+		}
+	case api.FieldRoutes:
+		// Routes are added if the value is set and removed otherwise
+		spec := value
+		if spec == "" {
+			spec = previous
+		}
+		dst, gateway, metric, err := parseRouteSpec(spec)
+		if err != nil {
+			return err
+		}
+
+		if value != "" {
+			if err := c.AddRoute(name, dst, gateway, metric); err != nil {
+				return fmt.Errorf("adding the route %s through %s: %w", spec, name, err)
+			}
+		} else if err := c.DeleteRoute(name, dst, gateway, metric); err != nil {
+			return fmt.Errorf("removing the route %s through %s: %w", spec, name, err)
+		}
+	default:
+		return fmt.Errorf("unknown field %q", change.Field)
+	}
+	return nil
+}
+
+// The decodeBody() method decodes the request body of a write operation into v. Bodies with
+// a YAML media type are decoded as YAML, any other body as JSON. It responds with an error
+// and reports false if the body is invalid or has unknown fields.
+func (s *server) decodeBody(w http.ResponseWriter, r *http.Request, v any) bool {
+	body := io.Reader(http.MaxBytesReader(w, r.Body, maxRequestBody))
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); yamlMediaTypes[mediaType] {
+		converted, err := yamlToJSON(body)
+		if err != nil {
+			s.error(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+			return false
+		}
+		body = bytes.NewReader(converted)
+	}
+
+	decoder := json.NewDecoder(body)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		s.error(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %w", err))
+		return false
+	}
+	return true
+}
+
+// yamlMediaTypes are the media types of YAML request bodies.
+var yamlMediaTypes = map[string]bool{
+	"application/yaml":   true,
+	"application/x-yaml": true,
+	"text/yaml":          true,
+	"text/x-yaml":        true,
+}
+
+// yamlToJSON converts a YAML document to JSON, so that it's decoded with the JSON field names
+// and the same checks as a JSON document.
+func yamlToJSON(r io.Reader) ([]byte, error) {
+	var doc any
+	if err := yaml.NewDecoder(r).Decode(&doc); err != nil {
+		return nil, err
+	}
+	return json.Marshal(doc)
+}
+
+// The writeError() method responds to a failed write operation with the status code that
+// describes the error of the kernel best, along with the error message.
+func (s *server) writeError(w http.ResponseWriter, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, models.ErrNoSuchInterface), errors.Is(err, syscall.ENODEV), errors.Is(err, syscall.EADDRNOTAVAIL):
+		code = http.StatusNotFound
+	case errors.Is(err, syscall.EPERM), errors.Is(err, syscall.EACCES):
+		// The server lacks the CAP_NET_ADMIN capability
+		code = http.StatusForbidden
+	case errors.Is(err, syscall.EEXIST), errors.Is(err, syscall.EBUSY), errors.Is(err, errPendingChange):
+		code = http.StatusConflict
+	case errors.Is(err, syscall.EINVAL), errors.Is(err, syscall.ERANGE), errors.Is(err, syscall.EOPNOTSUPP), errors.Is(err, syscall.EAFNOSUPPORT):
+		code = http.StatusUnprocessableEntity
+	}
+	s.error(w, code, err)
+}
@@ -0,0 +1,592 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	api "apimodule"
+	"servermodule/metrics"
+	models "servermodule/servermodels"
+)
+
+// errNoDesiredState is returned by the desired state endpoints before a desired state is set.
+var errNoDesiredState = errors.New("no desired state is set")
+
+// errRoutesUnsupported is returned when the desired state manages routes the collector can't report.
+var errRoutesUnsupported = errors.New("the desired state manages routes, which are not supported by the collector")
+
+// desiredStateStore keeps the desired state of the interfaces, optionally persisted to a file,
+// and the drift of the interfaces from it found by the latest check.
+type desiredStateStore struct {
+	file string // Path the desired state is persisted to, empty to keep it in memory only.
+
+	mu    sync.Mutex
+	state *api.DesiredState      // Nil if no desired state is set.
+	drift map[string]driftStatus // By interface name.
+
+	checkMu sync.Mutex // Serializes drift checks, so that their events are published in order.
+}
+
+// driftStatus is the result of the latest drift check of a managed interface.
+type driftStatus struct {
+	changes []api.FieldChange // Changes that bring the interface to its desired state.
+	missing bool              // Whether the interface doesn't exist.
+}
+
+// drifted reports whether the interface isn't in its desired state.
+func (d driftStatus) drifted() bool {
+	return d.missing || len(d.changes) > 0
+}
+
+// newDesiredStateStore creates a store persisting the desired state to file, if set,
+// and loads the desired state persisted there before.
+func newDesiredStateStore(file string) *desiredStateStore {
+	store := &desiredStateStore{file: file, drift: make(map[string]driftStatus)}
+	if file == "" {
+		return store
+	}
+
+	data, err := os.ReadFile(file)
+	if errors.Is(err, os.ErrNotExist) {
+		return store
+	}
+	if err == nil {
+		var state api.DesiredState
+		if err = json.Unmarshal(data, &state); err == nil {
+			if err = validateDesiredState(state); err == nil {
+				store.state = &state
+			}
+		}
+	}
+	if err != nil {
+		log.Printf("Ignoring the desired state in %s: %v", file, err)
+	}
+
+	return store
+}
+
+// get returns the desired state, or nil if none is set.
+func (d *desiredStateStore) get() *api.DesiredState {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.state
+}
+
+// set replaces the desired state, nil removes it. The desired state is persisted first,
+// so that it's kept unchanged if it can't be persisted.
+func (d *desiredStateStore) set(state *api.DesiredState) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.file != "" {
+		if err := persistDesiredState(d.file, state); err != nil {
+			return err
+		}
+	}
+
+	d.state = state
+	return nil
+}
+
+// persistDesiredState writes the desired state to a file, replacing it atomically,
+// or removes the file if the state is nil.
+func persistDesiredState(file string, state *api.DesiredState) error {
+	if state == nil {
+		if err := os.Remove(file); err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		return nil
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(append(data, '\n')); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), file)
+}
+
+// update records the drift of the managed interfaces and returns the events for the interfaces
+// that drifted from or returned to their desired state since the previous check.
+func (d *desiredStateStore) update(drift map[string]driftStatus, now time.Time) []api.Event {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var events []api.Event
+	if d.state != nil {
+		for _, desired := range d.state.Interfaces {
+			prev, cur := d.drift[desired.Name], drift[desired.Name]
+			switch {
+			case cur.drifted() && !prev.drifted():
+				events = append(events, api.Event{Type: api.EventDriftDetected, Interface: desired.Name, Timestamp: now, Changes: cur.changes})
+			case !cur.drifted() && prev.drifted():
+				events = append(events, api.Event{Type: api.EventDriftResolved, Interface: desired.Name, Timestamp: now})
+			}
+		}
+	}
+
+	d.drift = drift
+	return events
+}
+
+// writeMetrics writes whether the managed interfaces are in their desired state and the
+// number of changes that bring them there, as found by the latest drift check.
+func (d *desiredStateStore) writeMetrics(w *metrics.Writer) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var names []string
+	if d.state != nil {
+		for _, desired := range d.state.Interfaces {
+			if _, ok := d.drift[desired.Name]; ok {
+				names = append(names, desired.Name)
+			}
+		}
+	}
+
+	w.Family("interfacer_desired_state_in_sync", "Whether the interface is in its desired state.", "gauge")
+	for _, name := range names {
+		w.Sample("interfacer_desired_state_in_sync", metrics.Labels{"name": name}, boolValue(!d.drift[name].drifted()))
+	}
+	w.Family("interfacer_desired_state_changes", "Number of changes that bring the interface to its desired state.", "gauge")
+	for _, name := range names {
+		w.Sample("interfacer_desired_state_changes", metrics.Labels{"name": name}, float64(len(d.drift[name].changes)))
+	}
+}
+
+// The watchDrift() method checks the drift of the interfaces from their desired state
+// at every interval until the context is cancelled.
+func (s *server) watchDrift(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.checkDrift(); err != nil {
+			log.Printf("Drift check error: %s", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// The checkDrift() method plans the changes that bring the interfaces to their desired state,
+// records them as their drift and publishes the events of interfaces whose drift changed.
+func (s *server) checkDrift() error {
+	s.desired.checkMu.Lock()
+	defer s.desired.checkMu.Unlock()
+
+	drift := make(map[string]driftStatus)
+	if state := s.desired.get(); state != nil {
+		plan, err := s.plan(*state)
+		if err != nil {
+			return err
+		}
+		for _, desired := range state.Interfaces {
+			drift[desired.Name] = driftStatus{}
+		}
+		for _, set := range plan.Interfaces {
+			drift[set.Interface] = driftStatus{changes: set.Changes}
+		}
+		for _, name := range plan.Missing {
+			drift[name] = driftStatus{missing: true}
+		}
+	}
+
+	s.events.publish(s.desired.update(drift, time.Now()))
+	return nil
+}
+
+// The plan() method computes the changes that bring the interfaces to their desired state.
+func (s *server) plan(state api.DesiredState) (api.Plan, error) {
+	routes, _ := s.source.(models.RouteCollector)
+	return planDesiredState(s.collector, routes, state)
+}
+
+// planDesiredState computes the changes that bring the interfaces to their desired state from the
+// current state reported by the collectors. The routes collector may be nil if no routes are managed.
+func planDesiredState(collector models.Collector, routes models.RouteCollector, state api.DesiredState) (api.Plan, error) {
+	var current []api.Route
+	for _, desired := range state.Interfaces {
+		if desired.Routes == nil {
+			continue
+		}
+		if routes == nil {
+			return api.Plan{}, errRoutesUnsupported
+		}
+
+		var err error
+		if current, err = routes.Routes(); err != nil {
+			return api.Plan{}, err
+		}
+		break
+	}
+
+	plan := api.Plan{Interfaces: []api.ChangeSet{}}
+	for _, desired := range state.Interfaces {
+		iface, err := models.CollectInterface(collector, desired.Name, models.DetailAddresses)
+		if errors.Is(err, models.ErrNoSuchInterface) {
+			plan.Missing = append(plan.Missing, desired.Name)
+			continue
+		}
+		if err != nil {
+			return api.Plan{}, err
+		}
+
+		changes, err := planInterface(iface, desired, current)
+		if err != nil {
+			return api.Plan{}, err
+		}
+		if len(changes) > 0 {
+			plan.Interfaces = append(plan.Interfaces, api.ChangeSet{Interface: desired.Name, DryRun: true, Changes: changes})
+		}
+	}
+
+	plan.InSync = len(plan.Interfaces) == 0 && len(plan.Missing) == 0
+	return plan, nil
+}
+
+// planInterface computes the changes that bring an interface to its desired state. The MTU,
+// alias and administrative status change first, like in a PATCH request. Then the new addresses
+// are added before the old ones are removed, and the old routes are removed before the new ones
+// are added, so that replaced routes don't collide.
+func planInterface(iface *api.NetworkInterfaceV2, desired api.DesiredInterface, routes []api.Route) ([]api.FieldChange, error) {
+	changes := planPatch(iface, api.InterfacePatch{MTU: desired.MTU, AdminStatus: desired.AdminStatus, Alias: desired.Alias})
+
+	if desired.Addresses != nil {
+		var current []string
+		for _, address := range iface.Addresses {
+			// Link-local addresses are assigned by the kernel
+			if address.Scope != "link" {
+				current = append(current, net.ParseIP(address.Address).String()+"/"+strconv.Itoa(address.PrefixLength))
+			}
+		}
+
+		var wanted []string
+		for _, address := range desired.Addresses {
+			ip, prefix, err := net.ParseCIDR(address)
+			if err != nil {
+				return nil, err
+			}
+			wanted = append(wanted, (&net.IPNet{IP: ip, Mask: prefix.Mask}).String())
+		}
+
+		changes = append(changes, setChanges(api.FieldAddresses, current, wanted)...)
+	}
+
+	if desired.Routes != nil {
+		var current []string
+		for _, route := range routes {
+			if route.Table == "main" && route.Interface == iface.Name && route.Protocol == "static" && route.Type == "unicast" && len(route.Nexthops) == 0 {
+				current = append(current, routeSpec(route.Destination, route.Gateway, route.Metric))
+			}
+		}
+
+		var wanted []string
+		for _, route := range desired.Routes {
+			spec, err := desiredRouteSpec(route)
+			if err != nil {
+				return nil, err
+			}
+			wanted = append(wanted, spec)
+		}
+
+		changes = append(changes, setChanges(api.FieldRoutes, current, wanted)...)
+	}
+
+	return changes, nil
+}
+
+// setChanges returns the changes that turn the current values of a set-like field into the
+// wanted ones. The additions come before the removals for addresses, and after them for routes.
+func setChanges(field string, current, wanted []string) []api.FieldChange {
+	have, want := make(map[string]bool), make(map[string]bool)
+	for _, value := range current {
+		have[value] = true
+	}
+
+	var added, removed []api.FieldChange
+	for _, value := range wanted {
+		if !have[value] && !want[value] {
+			added = append(added, api.FieldChange{Field: field, New: value})
+		}
+		want[value] = true
+	}
+	for _, value := range current {
+		if !want[value] {
+			removed = append(removed, api.FieldChange{Field: field, Old: value})
+		}
+	}
+
+	if field == api.FieldRoutes {
+		return append(removed, added...)
+	}
+	return append(added, removed...)
+}
+
+// routeSpec returns the notation of a static route used in changes, e.g. "0.0.0.0/0 via
+// 192.0.2.1 metric 100". The destination is always a prefix, so that its family is explicit.
+func routeSpec(dst, gateway string, metric uint32) string {
+	spec := dst
+	if gateway != "" {
+		spec += " via " + gateway
+	}
+	return spec + " metric " + strconv.FormatUint(uint64(metric), 10)
+}
+
+// desiredRouteSpec returns the notation of a desired route with its values in the form the
+// kernel reports them. The default destination takes the family of the gateway, and IPv6
+// routes without a metric get the kernel's default metric.
+func desiredRouteSpec(route api.DesiredRoute) (string, error) {
+	var gateway net.IP
+	if route.Gateway != "" {
+		if gateway = net.ParseIP(route.Gateway); gateway == nil {
+			return "", fmt.Errorf("invalid gateway %q: expected an IP address", route.Gateway)
+		}
+	}
+
+	destination := route.Destination
+	if destination == "default" {
+		destination = "0.0.0.0/0"
+		if gateway != nil && gateway.To4() == nil {
+			destination = "::/0"
+		}
+	}
+	_, dst, err := net.ParseCIDR(destination)
+	if err != nil {
+		return "", fmt.Errorf("invalid destination %q: expected a prefix in CIDR notation or default", route.Destination)
+	}
+
+	ipv4 := dst.IP.To4() != nil
+	if gateway != nil && (gateway.To4() != nil) != ipv4 {
+		return "", fmt.Errorf("the gateway %s of %s has a different address family", gateway, dst)
+	}
+
+	metric := route.Metric
+	if !ipv4 && metric == 0 {
+		metric = 1024 // IP6_RT_PRIO_USER
+	}
+
+	var gw string
+	if gateway != nil {
+		gw = gateway.String()
+	}
+	return routeSpec(dst.String(), gw, metric), nil
+}
+
+// parseRouteSpec parses the notation of a static route used in changes.
+func parseRouteSpec(spec string) (*net.IPNet, net.IP, uint32, error) {
+	fields := strings.Fields(spec)
+	invalid := fmt.Errorf("invalid route %q", spec)
+	if len(fields) != 3 && len(fields) != 5 || fields[len(fields)-2] != "metric" {
+		return nil, nil, 0, invalid
+	}
+
+	_, dst, err := net.ParseCIDR(fields[0])
+	if err != nil {
+		return nil, nil, 0, invalid
+	}
+
+	var gateway net.IP
+	if len(fields) == 5 {
+		if fields[1] != "via" {
+			return nil, nil, 0, invalid
+		}
+		if gateway = net.ParseIP(fields[2]); gateway == nil {
+			return nil, nil, 0, invalid
+		}
+	}
+
+	metric, err := strconv.ParseUint(fields[len(fields)-1], 10, 32)
+	if err != nil {
+		return nil, nil, 0, invalid
+	}
+
+	return dst, gateway, uint32(metric), nil
+}
+
+// validateDesiredState checks that a desired state manages every interface once and that its values are valid.
+func validateDesiredState(state api.DesiredState) error {
+	seen := make(map[string]bool)
+	for _, desired := range state.Interfaces {
+		if desired.Name == "" {
+			return errors.New("invalid interface: expected a name")
+		}
+		if seen[desired.Name] {
+			return fmt.Errorf("invalid interface %s: listed more than once", desired.Name)
+		}
+		seen[desired.Name] = true
+
+		if desired.MTU != nil && *desired.MTU <= 0 {
+			return fmt.Errorf("invalid mtu of %s: expected a positive number", desired.Name)
+		}
+		if desired.AdminStatus != nil && *desired.AdminStatus != api.AdminStatusUp && *desired.AdminStatus != api.AdminStatusDown {
+			return fmt.Errorf("invalid admin_status of %s: expected %s or %s", desired.Name, api.AdminStatusUp, api.AdminStatusDown)
+		}
+		for _, address := range desired.Addresses {
+			if _, _, err := net.ParseCIDR(address); err != nil {
+				return fmt.Errorf("invalid address %q of %s: expected an IP address with its prefix length in CIDR notation", address, desired.Name)
+			}
+		}
+		for _, route := range desired.Routes {
+			if _, err := desiredRouteSpec(route); err != nil {
+				return fmt.Errorf("invalid route of %s: %w", desired.Name, err)
+			}
+		}
+	}
+	return nil
+}
+
+// The getDesiredStateHandler() method is the handler function for GET requests to the
+// /v2/desired-state endpoint. It returns the desired state of the interfaces.
+func (s *server) getDesiredStateHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		state := s.desired.get()
+		if state == nil {
+			s.error(w, http.StatusNotFound, errNoDesiredState)
+			return
+		}
+
+		s.respond(w, http.StatusOK, state)
+	}
+}
+
+// The putDesiredStateHandler() method is the handler function for PUT requests to the
+// /v2/desired-state endpoint. It replaces the desired state of the interfaces with a JSON
+// or YAML document, without applying it, and returns the new desired state.
+func (s *server) putDesiredStateHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var state api.DesiredState
+		if !s.decodeBody(w, r, &state) {
+			return
+		}
+		if err := validateDesiredState(state); err != nil {
+			s.error(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if err := s.desired.set(&state); err != nil {
+			s.error(w, http.StatusInternalServerError, fmt.Errorf("persisting the desired state: %w", err))
+			return
+		}
+		if err := s.checkDrift(); err != nil {
+			log.Printf("Drift check error: %s", err.Error())
+		}
+
+		s.respond(w, http.StatusOK, state)
+	}
+}
+
+// The deleteDesiredStateHandler() method is the handler function for DELETE requests to the
+// /v2/desired-state endpoint. It removes the desired state, which leaves the interfaces unmanaged.
+func (s *server) deleteDesiredStateHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if err := s.desired.set(nil); err != nil {
+			s.error(w, http.StatusInternalServerError, fmt.Errorf("removing the desired state: %w", err))
+			return
+		}
+		if err := s.checkDrift(); err != nil {
+			log.Printf("Drift check error: %s", err.Error())
+		}
+
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// The planHandler() method is the handler function for the /v2/desired-state/plan endpoint.
+// It returns the changes that bring the interfaces to their desired state.
+func (s *server) planHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		plan, ok := s.currentPlan(w)
+		if !ok {
+			return
+		}
+
+		s.respond(w, http.StatusOK, plan)
+	}
+}
+
+// The applyHandler() method is the handler function for POST requests to the
+// /v2/desired-state/apply endpoint. It applies the changes that bring the interfaces to
+// their desired state, in the mode selected by the ?dry_run= and ?confirm_timeout= parameters.
+func (s *server) applyHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		configurator, mode, ok := s.writeRequest(w, r)
+		if !ok {
+			return
+		}
+
+		plan, ok := s.currentPlan(w)
+		if !ok {
+			return
+		}
+		if mode.dryRun {
+			s.respond(w, http.StatusOK, plan)
+			return
+		}
+
+		pending, err := s.changes.applyAll(configurator, plan.Interfaces, mode.timeout)
+		if err != nil {
+			s.writeError(w, err)
+			return
+		}
+		for i := range plan.Interfaces {
+			plan.Interfaces[i].DryRun = false
+		}
+		if err := s.checkDrift(); err != nil {
+			log.Printf("Drift check error: %s", err.Error())
+		}
+
+		s.respond(w, http.StatusOK, api.ApplyResult{Applied: plan.Interfaces, Pending: pending, Missing: plan.Missing})
+	}
+}
+
+// The currentPlan() method plans the changes that bring the interfaces to their desired state.
+// It responds with an error and reports false if no desired state is set or it can't be planned.
+func (s *server) currentPlan(w http.ResponseWriter) (api.Plan, bool) {
+	state := s.desired.get()
+	if state == nil {
+		s.error(w, http.StatusNotFound, errNoDesiredState)
+		return api.Plan{}, false
+	}
+
+	plan, err := s.plan(*state)
+	if errors.Is(err, errRoutesUnsupported) {
+		s.error(w, http.StatusNotImplemented, err)
+		return api.Plan{}, false
+	}
+	if err != nil {
+		s.error(w, http.StatusInternalServerError, err)
+		return api.Plan{}, false
+	}
+
+	return plan, true
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	api "apimodule"
+)
+
+// TestPlanInterface tests the changes planned for an interface, including the ordering of
+// address and route changes and the fields and routes that aren't managed.
+func TestPlanInterface(t *testing.T) {
+	iface := &api.NetworkInterfaceV2{
+		Name:        "eth0",
+		MTU:         1500,
+		AdminStatus: api.AdminStatusUp,
+		Addresses: []api.Address{
+			{Address: "192.0.2.10", PrefixLength: 24, Scope: "global"},
+			{Address: "fe80::1", PrefixLength: 64, Scope: "link"},
+		},
+	}
+	routes := []api.Route{
+		{Destination: "0.0.0.0/0", Gateway: "192.0.2.1", Interface: "eth0", Metric: 100, Protocol: "static", Table: "main", Type: "unicast"},
+		{Destination: "198.51.100.0/24", Gateway: "192.0.2.1", Interface: "eth0", Protocol: "boot", Table: "main", Type: "unicast"},
+		{Destination: "203.0.113.0/24", Interface: "eth1", Protocol: "static", Table: "main", Type: "unicast"},
+	}
+	mtu, currentMTU, up := 9000, 1500, api.AdminStatusUp
+
+	tests := []struct {
+		name     string
+		desired  api.DesiredInterface
+		expected []api.FieldChange
+	}{
+		{name: "InSync", desired: api.DesiredInterface{Name: "eth0", MTU: &currentMTU, AdminStatus: &up}, expected: nil},
+		{name: "Unmanaged", desired: api.DesiredInterface{Name: "eth0"}, expected: nil},
+		{name: "MTU", desired: api.DesiredInterface{Name: "eth0", MTU: &mtu, AdminStatus: &up}, expected: []api.FieldChange{{Field: api.FieldMTU, Old: "1500", New: "9000"}}},
+		{
+			name:    "Addresses",
+			desired: api.DesiredInterface{Name: "eth0", Addresses: []string{"192.0.2.20/24", "192.0.2.20/24"}},
+			expected: []api.FieldChange{
+				{Field: api.FieldAddresses, New: "192.0.2.20/24"},
+				{Field: api.FieldAddresses, Old: "192.0.2.10/24"},
+			},
+		},
+		{name: "SameAddresses", desired: api.DesiredInterface{Name: "eth0", Addresses: []string{"192.0.2.10/24"}}, expected: nil},
+		{
+			name:    "Routes",
+			desired: api.DesiredInterface{Name: "eth0", Routes: []api.DesiredRoute{{Destination: "default", Gateway: "192.0.2.254", Metric: 100}, {Destination: "2001:db8::/32"}}},
+			expected: []api.FieldChange{
+				{Field: api.FieldRoutes, Old: "0.0.0.0/0 via 192.0.2.1 metric 100"},
+				{Field: api.FieldRoutes, New: "0.0.0.0/0 via 192.0.2.254 metric 100"},
+				{Field: api.FieldRoutes, New: "2001:db8::/32 metric 1024"},
+			},
+		},
+		{name: "SameRoutes", desired: api.DesiredInterface{Name: "eth0", Routes: []api.DesiredRoute{{Destination: "default", Gateway: "192.0.2.1", Metric: 100}}}, expected: nil},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			changes, err := planInterface(iface, test.desired, routes)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (len(changes) != 0 || len(test.expected) != 0) && !reflect.DeepEqual(changes, test.expected) {
+				t.Errorf("changes mismatch: got %+v, want %+v", changes, test.expected)
+			}
+		})
+	}
+}
+
+// TestParseRouteSpec tests that the notation of desired routes is parsed back to the same route.
+func TestParseRouteSpec(t *testing.T) {
+	tests := []struct {
+		route        api.DesiredRoute
+		expectedSpec string
+	}{
+		{route: api.DesiredRoute{Destination: "default", Gateway: "192.0.2.1"}, expectedSpec: "0.0.0.0/0 via 192.0.2.1 metric 0"},
+		{route: api.DesiredRoute{Destination: "default", Gateway: "2001:db8::1"}, expectedSpec: "::/0 via 2001:db8::1 metric 1024"},
+		{route: api.DesiredRoute{Destination: "198.51.100.7/24", Metric: 50}, expectedSpec: "198.51.100.0/24 metric 50"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.expectedSpec, func(t *testing.T) {
+			spec, err := desiredRouteSpec(test.route)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if spec != test.expectedSpec {
+				t.Fatalf("spec mismatch: got %q, want %q", spec, test.expectedSpec)
+			}
+
+			dst, gateway, metric, err := parseRouteSpec(spec)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var gw string
+			if gateway != nil {
+				gw = gateway.String()
+			}
+			if got := routeSpec(dst.String(), gw, metric); got != spec {
+				t.Errorf("parsed route mismatch: got %q, want %q", got, spec)
+			}
+		})
+	}
+
+	for _, spec := range []string{"", "default via 192.0.2.1 metric 0", "0.0.0.0/0 gw 192.0.2.1 metric 0", "0.0.0.0/0 metric x"} {
+		if _, _, _, err := parseRouteSpec(spec); err == nil {
+			t.Errorf("expected an error for %q", spec)
+		}
+	}
+}
+
+// TestDriftEvents tests that events are only published when an interface drifts from or
+// returns to its desired state.
+func TestDriftEvents(t *testing.T) {
+	store := newDesiredStateStore("")
+	if err := store.set(&api.DesiredState{Interfaces: []api.DesiredInterface{{Name: "eth0"}, {Name: "eth1"}}}); err != nil {
+		t.Fatal(err)
+	}
+	mtuChange := []api.FieldChange{{Field: api.FieldMTU, Old: "1500", New: "9000"}}
+	now := time.Now()
+
+	steps := []struct {
+		drift    map[string]driftStatus
+		expected []api.Event
+	}{
+		{
+			drift:    map[string]driftStatus{"eth0": {changes: mtuChange}, "eth1": {}},
+			expected: []api.Event{{Type: api.EventDriftDetected, Interface: "eth0", Timestamp: now, Changes: mtuChange}},
+		},
+		{drift: map[string]driftStatus{"eth0": {changes: mtuChange}, "eth1": {}}, expected: nil},
+		{
+			drift: map[string]driftStatus{"eth0": {}, "eth1": {missing: true}},
+			expected: []api.Event{
+				{Type: api.EventDriftResolved, Interface: "eth0", Timestamp: now},
+				{Type: api.EventDriftDetected, Interface: "eth1", Timestamp: now},
+			},
+		},
+	}
+
+	for i, step := range steps {
+		if events := store.update(step.drift, now); !reflect.DeepEqual(events, step.expected) {
+			t.Errorf("step %d: events mismatch: got %+v, want %+v", i, events, step.expected)
+		}
+	}
+}
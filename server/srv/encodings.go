@@ -0,0 +1,244 @@
+package server
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"servermodule/codec"
+)
+
+// encoding is a response format that can be selected with the Accept header or the ?format= parameter.
+type encoding struct {
+	name        string                            // Name of the format in the ?format= parameter.
+	contentType string                            // Content type of the responses.
+	mediaTypes  []string                          // Media types that select the format in the Accept header.
+	encode      func(w io.Writer, tree any) error // Encodes a tree returned by codec.Tree, nil for JSON.
+}
+
+// Columns that the table formats expand into one row per element, which makes one row per
+// address of an interface in the v1 and v2 models.
+var expandedColumns = []string{"addresses", "ip_addresses"}
+
+// encodings are the response formats in the order of preference, which breaks ties in the Accept
+// header, e.g. */* selects JSON. The dot format is only served by the /topology endpoint.
+var encodings = []*encoding{
+	{
+		name:        "json",
+		contentType: "application/json",
+		mediaTypes:  []string{"application/json", mediaTypeV1, mediaTypeV2},
+	},
+	{
+		name:        "yaml",
+		contentType: "application/yaml",
+		mediaTypes:  []string{"application/yaml", "application/x-yaml", "text/yaml", "text/x-yaml"},
+		encode:      codec.WriteYAML,
+	},
+	{
+		name:        "csv",
+		contentType: "text/csv; charset=utf-8",
+		mediaTypes:  []string{"text/csv"},
+		encode: func(w io.Writer, tree any) error {
+			return codec.WriteTable(w, tree, ',', expandedColumns...)
+		},
+	},
+	{
+		name:        "tsv",
+		contentType: "text/tab-separated-values; charset=utf-8",
+		mediaTypes:  []string{"text/tab-separated-values"},
+		encode: func(w io.Writer, tree any) error {
+			return codec.WriteTable(w, tree, '\t', expandedColumns...)
+		},
+	},
+	{
+		name:        "msgpack",
+		contentType: "application/msgpack",
+		mediaTypes:  []string{"application/msgpack", "application/x-msgpack", "application/vnd.msgpack"},
+		encode:      codec.WriteMessagePack,
+	},
+	{
+		name:        "cbor",
+		contentType: "application/cbor",
+		mediaTypes:  []string{"application/cbor"},
+		encode:      codec.WriteCBOR,
+	},
+	{
+		name:        "dot",
+		contentType: "text/vnd.graphviz; charset=utf-8",
+		mediaTypes:  []string{"text/vnd.graphviz"},
+	},
+}
+
+// Encodings with a special meaning.
+var (
+	jsonEncoding = encodings[0]
+	dotEncoding  = encodings[len(encodings)-1]
+)
+
+// errNotAcceptable is returned when none of the requested formats is supported.
+var errNotAcceptable = errors.New("unsupported response format, expected one of json, yaml, csv, tsv, msgpack or cbor " +
+	"with the Accept header or the ?format= parameter")
+
+// negotiateEncoding returns the response format selected by the ?format= parameter of a request or,
+// without it, by its Accept header. Requests without either are served as JSON. It returns nil
+// if none of the requested formats is supported.
+func negotiateEncoding(r *http.Request) *encoding {
+	if values, ok := r.URL.Query()["format"]; ok {
+		if len(values) != 1 {
+			return nil
+		}
+		for _, e := range encodings {
+			if e.name == values[0] {
+				return e
+			}
+		}
+		return nil
+	}
+
+	accept := r.Header.Values("Accept")
+	if len(accept) == 0 {
+		return jsonEncoding
+	}
+
+	var best *encoding
+	var bestQ float64
+	for _, e := range encodings {
+		if q := acceptQuality(accept, e.mediaTypes); q > bestQ {
+			best, bestQ = e, q
+		}
+	}
+	return best
+}
+
+// acceptQuality returns the highest quality that the Accept header values give to any of the
+// media types. A media type takes the quality of the most specific media range matching it,
+// i.e. type/subtype before type/* before */*.
+func acceptQuality(accept []string, mediaTypes []string) float64 {
+	var quality float64
+	for _, mediaType := range mediaTypes {
+		q, specificity := 0.0, -1
+		for _, mediaRange := range acceptRanges(accept) {
+			s := mediaRange.match(mediaType)
+			if s > specificity {
+				q, specificity = mediaRange.q, s
+			}
+		}
+		quality = max(quality, q)
+	}
+	return quality
+}
+
+// mediaRange is a media range of the Accept header with its quality.
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+// acceptRanges parses the media ranges of the Accept header values, skipping invalid ones.
+func acceptRanges(accept []string) []mediaRange {
+	var ranges []mediaRange
+	for _, value := range accept {
+		for _, part := range strings.Split(value, ",") {
+			mediaType, params, err := mime.ParseMediaType(part)
+			if err != nil {
+				continue
+			}
+
+			q := 1.0
+			if v, ok := params["q"]; ok {
+				if q, err = strconv.ParseFloat(v, 64); err != nil {
+					continue
+				}
+			}
+			ranges = append(ranges, mediaRange{mediaType: mediaType, q: q})
+		}
+	}
+	return ranges
+}
+
+// match returns how specifically the media range matches a media type: 2 for the media type
+// itself, 1 for type/*, 0 for */* and -1 if it doesn't match.
+func (m mediaRange) match(mediaType string) int {
+	if m.mediaType == mediaType {
+		return 2
+	}
+	if m.mediaType == "*/*" {
+		return 0
+	}
+	if prefix, ok := strings.CutSuffix(m.mediaType, "/*"); ok && strings.HasPrefix(mediaType, prefix+"/") {
+		return 1
+	}
+	return -1
+}
+
+// withEncoding negotiates the response format of a request and returns the response writer
+// carrying it to respond(), along with the request without the ?format= parameter, so that
+// the handlers don't have to allow it.
+func withEncoding(w http.ResponseWriter, r *http.Request) (http.ResponseWriter, *http.Request) {
+	e := negotiateEncoding(r)
+	if query := r.URL.Query(); query.Has("format") {
+		query.Del("format")
+		r = r.Clone(r.Context())
+		r.URL.RawQuery = query.Encode()
+	}
+	return &encodingWriter{ResponseWriter: w, encoding: e}, r
+}
+
+// encodingWriter is a response writer that carries the negotiated response format.
+// A nil encoding means that none of the requested formats is supported.
+type encodingWriter struct {
+	http.ResponseWriter
+	encoding *encoding
+}
+
+// Flush flushes the underlying ResponseWriter if it supports flushing.
+func (ew *encodingWriter) Flush() {
+	if flusher, ok := ew.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Hijack takes over the connection of the underlying ResponseWriter if it supports hijacking.
+func (ew *encodingWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	hijacker, ok := ew.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	return hijacker.Hijack()
+}
+
+// Unwrap returns the underlying ResponseWriter, for use by http.ResponseController.
+func (ew *encodingWriter) Unwrap() http.ResponseWriter {
+	return ew.ResponseWriter
+}
+
+// responseEncoding returns the response format negotiated for a response writer, looking through
+// the writers wrapping it. Writers that weren't negotiated, e.g. in tests of single handlers, use JSON.
+func responseEncoding(w http.ResponseWriter) *encoding {
+	for {
+		switch rw := w.(type) {
+		case *encodingWriter:
+			return rw.encoding
+		case interface{ Unwrap() http.ResponseWriter }:
+			w = rw.Unwrap()
+		default:
+			return jsonEncoding
+		}
+	}
+}
+
+// The acceptable() method responds with 406 Not Acceptable and reports false if none of the
+// requested response formats can be encoded by respond().
+func (s *server) acceptable(w http.ResponseWriter) bool {
+	if e := responseEncoding(w); e == jsonEncoding || e != nil && e.encode != nil {
+		return true
+	}
+	s.error(w, http.StatusNotAcceptable, errNotAcceptable)
+	return false
+}
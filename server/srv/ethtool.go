@@ -0,0 +1,32 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	models "servermodule/servermodels"
+)
+
+// The ethtoolHandler() method is the handler function for the /network/{name}/ethtool endpoint.
+// It returns the driver, link modes, offload features, ring and channel sizes, pause parameters
+// and transceiver module of a single interface.
+func (s *server) ethtoolHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		collector, ok := capability[models.EthtoolCollector](s, w)
+		if !ok {
+			return
+		}
+
+		ethtool, err := collector.Ethtool(r.PathValue("name"))
+		if errors.Is(err, models.ErrNoSuchInterface) {
+			s.error(w, http.StatusNotFound, err)
+			return
+		}
+		if err != nil {
+			s.error(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, http.StatusOK, ethtool)
+	}
+}
@@ -0,0 +1,127 @@
+package server
+
+import (
+	"slices"
+	"sync"
+
+	api "apimodule"
+)
+
+// The eventBroker struct fans out interface change events to subscribers and keeps
+// a bounded backlog of recent events, so that subscribers can resume after a reconnect.
+type eventBroker struct {
+	mu          sync.Mutex
+	lastID      uint64
+	backlog     []api.Event // Most recent events, oldest first.
+	size        int         // Maximum number of events kept in the backlog.
+	subscribers map[chan api.Event]struct{}
+}
+
+// subscriberBuffer is the number of events buffered per subscriber.
+// Subscribers falling further behind are disconnected and have to resume.
+const subscriberBuffer = 64
+
+// newEventBroker creates a new eventBroker keeping up to size events for resumption.
+func newEventBroker(size int) *eventBroker {
+	return &eventBroker{
+		size:        size,
+		subscribers: make(map[chan api.Event]struct{}),
+	}
+}
+
+// publish assigns sequence numbers to the events and delivers them to every subscriber.
+func (b *eventBroker) publish(events []api.Event) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for _, event := range events {
+		b.lastID++
+		event.ID = b.lastID
+
+		b.backlog = append(b.backlog, event)
+		if len(b.backlog) > b.size {
+			b.backlog = slices.Delete(b.backlog, 0, len(b.backlog)-b.size)
+		}
+
+		for ch := range b.subscribers {
+			select {
+			case ch <- event:
+			default:
+				// The subscriber is too slow, drop it rather than blocking the sampler
+				delete(b.subscribers, ch)
+				close(ch)
+			}
+		}
+	}
+}
+
+// subscribe registers a new subscriber. If lastID is non-zero, the backlog events
+// published after that ID are returned so the subscriber can catch up first.
+// The returned channel is closed when the subscriber falls behind or cancel is called.
+func (b *eventBroker) subscribe(lastID uint64) ([]api.Event, <-chan api.Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var missed []api.Event
+	if lastID != 0 {
+		for _, event := range b.backlog {
+			if event.ID > lastID {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	ch := make(chan api.Event, subscriberBuffer)
+	b.subscribers[ch] = struct{}{}
+
+	cancel := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+
+	return missed, ch, cancel
+}
+
+// diffSnapshots returns the events describing the changes of an interface between two snapshots.
+func diffSnapshots(name string, prev, cur api.InterfaceSnapshot) []api.Event {
+	var events []api.Event
+	newEvent := func(eventType string) api.Event {
+		return api.Event{Type: eventType, Interface: name, Timestamp: cur.Timestamp}
+	}
+
+	// Link state
+	if prev.OperationalStatus != "UP" && cur.OperationalStatus == "UP" {
+		events = append(events, newEvent(api.EventLinkUp))
+	} else if prev.OperationalStatus == "UP" && cur.OperationalStatus != "UP" {
+		events = append(events, newEvent(api.EventLinkDown))
+	}
+
+	// MTU
+	if prev.MTU != cur.MTU {
+		event := newEvent(api.EventMTUChanged)
+		event.OldMTU, event.NewMTU = prev.MTU, cur.MTU
+		events = append(events, event)
+	}
+
+	// Addresses
+	for _, addr := range cur.IPAddresses {
+		if !slices.Contains(prev.IPAddresses, addr) {
+			event := newEvent(api.EventAddressAdded)
+			event.Address = addr
+			events = append(events, event)
+		}
+	}
+	for _, addr := range prev.IPAddresses {
+		if !slices.Contains(cur.IPAddresses, addr) {
+			event := newEvent(api.EventAddressRemoved)
+			event.Address = addr
+			events = append(events, event)
+		}
+	}
+
+	return events
+}
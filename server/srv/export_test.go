@@ -0,0 +1,7 @@
+package server
+
+// Routes returns the patterns of the routes registered by the server, for the tests of the
+// server_test package.
+func (s *server) Routes() []string {
+	return s.router.Routes()
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	api "apimodule"
+	models "servermodule/servermodels"
+)
+
+// interfaceModel describes the fields of an interface model that ?fields= selects from.
+type interfaceModel struct {
+	fields  []string                  // JSON names of the fields, in the order of the model.
+	sources map[string]models.Details // Costly details that fields are derived from, by name.
+	filters map[string]filterParser   // Filter parameters of the /network requests served in the model.
+}
+
+// The v1 and v2 interface models. Fields without a source come with the links.
+var (
+	v1Model = newInterfaceModel(api.NetworkInterface{}, map[string]models.Details{
+		"ip_addresses": models.DetailAddresses,
+		"speed":        models.DetailLinkModes,
+		"duplex":       models.DetailLinkModes,
+		"stats":        models.DetailStats,
+	}, v1FilterParams)
+	v2Model = newInterfaceModel(api.NetworkInterfaceV2{}, map[string]models.Details{
+		"addresses":  models.DetailAddresses,
+		"speed_mbps": models.DetailLinkModes,
+		"duplex":     models.DetailLinkModes,
+		"wireless":   models.DetailWireless,
+		"stats":      models.DetailStats,
+	}, filterParams)
+)
+
+// newInterfaceModel describes the model of the given struct from the JSON names of its fields,
+// whose /network requests take the given filter parameters.
+func newInterfaceModel(model any, sources map[string]models.Details, filters map[string]filterParser) *interfaceModel {
+	t := reflect.TypeOf(model)
+	m := &interfaceModel{sources: sources, filters: filters}
+	for i := 0; i < t.NumField(); i++ {
+		name, _, _ := strings.Cut(t.Field(i).Tag.Get("json"), ",")
+		m.fields = append(m.fields, name)
+	}
+	return m
+}
+
+// fieldSet holds the fields selected with ?fields=. A nil set selects every field.
+type fieldSet map[string]bool
+
+// The requestFields() method parses the ?fields= parameter of a request for interfaces in the
+// model. It responds with an error and reports false if the parameter is invalid.
+func (s *server) requestFields(w http.ResponseWriter, r *http.Request, m *interfaceModel) (fieldSet, bool) {
+	values, ok := r.URL.Query()["fields"]
+	if !ok {
+		return nil, true
+	}
+	if len(values) != 1 || values[0] == "" {
+		s.error(w, http.StatusBadRequest, errors.New("invalid fields parameter: expected a single comma-separated list of fields"))
+		return nil, false
+	}
+
+	fields, err := m.parseFields(values[0])
+	if err != nil {
+		s.error(w, http.StatusBadRequest, err)
+		return nil, false
+	}
+	return fields, true
+}
+
+// parseFields parses the comma-separated field names of a ?fields= parameter.
+func (m *interfaceModel) parseFields(param string) (fieldSet, error) {
+	fields := make(fieldSet)
+	for _, name := range strings.Split(param, ",") {
+		name = strings.TrimSpace(name)
+		if !m.has(name) {
+			return nil, fmt.Errorf("invalid fields parameter: unknown field %q, expected some of %s", name, strings.Join(m.fields, ", "))
+		}
+		fields[name] = true
+	}
+	return fields, nil
+}
+
+// has reports whether the model has a field with the given JSON name.
+func (m *interfaceModel) has(name string) bool {
+	for _, field := range m.fields {
+		if field == name {
+			return true
+		}
+	}
+	return false
+}
+
+// details returns the costly details that the selected fields are derived from.
+func (m *interfaceModel) details(fields fieldSet) models.Details {
+	if fields == nil {
+		return models.AllDetails
+	}
+
+	var details models.Details
+	for name := range fields {
+		details |= m.sources[name]
+	}
+	return details
+}
+
+// project reduces an interface in the model to the selected fields, keeping their order in the model.
+// Fields that are left out of the interface when empty are also left out of the projection.
+func (m *interfaceModel) project(iface any, fields fieldSet) (projection, error) {
+	b, err := json.Marshal(iface)
+	if err != nil {
+		return nil, err
+	}
+	var values map[string]json.RawMessage
+	if err := json.Unmarshal(b, &values); err != nil {
+		return nil, err
+	}
+
+	p := make(projection, 0, len(fields))
+	for _, name := range m.fields {
+		if value, ok := values[name]; ok && fields[name] {
+			p = append(p, projectedField{name: name, value: value})
+		}
+	}
+	return p, nil
+}
+
+// projectAll reduces interfaces in the model to the selected fields.
+func projectAll[T any](m *interfaceModel, interfaces []T, fields fieldSet) ([]projection, error) {
+	projections := make([]projection, 0, len(interfaces))
+	for _, iface := range interfaces {
+		p, err := m.project(iface, fields)
+		if err != nil {
+			return nil, err
+		}
+		projections = append(projections, p)
+	}
+	return projections, nil
+}
+
+// projection is an interface reduced to some of its fields.
+// It's encoded as a JSON object with the fields in order.
+type projection []projectedField
+
+// projectedField is a field of a projection with its JSON encoded value.
+type projectedField struct {
+	name  string
+	value json.RawMessage
+}
+
+// MarshalJSON encodes the projection as a JSON object.
+func (p projection) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, field := range p {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		name, err := json.Marshal(field.name)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(name)
+		buf.WriteByte(':')
+		buf.Write(field.value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,216 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"net"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+
+	api "apimodule"
+	models "servermodule/servermodels"
+)
+
+// macPrefixPattern matches the leading octets of a MAC address in lower case.
+var macPrefixPattern = regexp.MustCompile(`^[0-9a-f]{2}(:[0-9a-f]{2})*$`)
+
+// interfaceMatcher reports whether an interface matches a single filter value.
+type interfaceMatcher func(iface *api.NetworkInterfaceV2) bool
+
+// interfaceFilter holds the filter parameters of a /network request. An interface is selected
+// if it matches every parameter, and it matches a parameter that is repeated if it matches one
+// of its values. An empty filter selects every interface.
+type interfaceFilter struct {
+	params  [][]interfaceMatcher // Matchers of the values, by parameter.
+	details models.Details       // Costly details that the parameters match on.
+}
+
+// filterParser parses a value of a filter parameter to its matcher.
+type filterParser func(value string) (interfaceMatcher, error)
+
+// filterParams are the query parameters of a v2 /network request that filter the interfaces.
+var filterParams = map[string]filterParser{
+	"name":         nameMatcher,
+	"kind":         kindMatcher,
+	"oper_status":  operStatusMatcher,
+	"admin_status": adminStatusMatcher,
+	"has_ipv4":     familyMatcher(api.FamilyIPv4),
+	"has_ipv6":     familyMatcher(api.FamilyIPv6),
+	"in_cidr":      cidrMatcher,
+	"mac_prefix":   macPrefixMatcher,
+}
+
+// v1FilterParams are the filter parameters of a v1 /network request, which match the statuses
+// with the values of the v1 model.
+var v1FilterParams = func() map[string]filterParser {
+	params := maps.Clone(filterParams)
+	params["oper_status"] = v1OperStatusMatcher
+	params["admin_status"] = v1AdminStatusMatcher
+	return params
+}()
+
+// filterDetails are the costly details that filter parameters match on, by parameter.
+var filterDetails = map[string]models.Details{
+	"has_ipv4": models.DetailAddresses,
+	"has_ipv6": models.DetailAddresses,
+	"in_cidr":  models.DetailAddresses,
+}
+
+// add parses the values of a filter parameter and adds them to the filter.
+func (f *interfaceFilter) add(key string, parse filterParser, values []string) error {
+	matchers := make([]interfaceMatcher, 0, len(values))
+	for _, value := range values {
+		matcher, err := parse(value)
+		if err != nil {
+			return fmt.Errorf("invalid %s parameter: %w", key, err)
+		}
+		matchers = append(matchers, matcher)
+	}
+	f.params = append(f.params, matchers)
+	f.details |= filterDetails[key]
+	return nil
+}
+
+// apply returns the interfaces selected by the filter, keeping their order.
+func (f *interfaceFilter) apply(interfaces []api.NetworkInterfaceV2) []api.NetworkInterfaceV2 {
+	if len(f.params) == 0 {
+		return interfaces
+	}
+
+	selected := make([]api.NetworkInterfaceV2, 0, len(interfaces))
+	for i := range interfaces {
+		if f.matches(&interfaces[i]) {
+			selected = append(selected, interfaces[i])
+		}
+	}
+	return selected
+}
+
+// matches reports whether the interface is selected by the filter.
+func (f *interfaceFilter) matches(iface *api.NetworkInterfaceV2) bool {
+	for _, matchers := range f.params {
+		matched := false
+		for _, matcher := range matchers {
+			if matched = matcher(iface); matched {
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// nameMatcher matches the names of interfaces against a shell glob, e.g. eth* or wlan[0-3].
+func nameMatcher(pattern string) (interfaceMatcher, error) {
+	if _, err := path.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("expected a glob pattern: %w", err)
+	}
+	return func(iface *api.NetworkInterfaceV2) bool {
+		matched, _ := path.Match(pattern, iface.Name)
+		return matched
+	}, nil
+}
+
+// kindMatcher matches the kind of interfaces, which may be any link kind of the kernel.
+func kindMatcher(kind string) (interfaceMatcher, error) {
+	return func(iface *api.NetworkInterfaceV2) bool {
+		return iface.Kind == kind
+	}, nil
+}
+
+// operStatusMatcher matches the operational status of interfaces.
+func operStatusMatcher(status string) (interfaceMatcher, error) {
+	switch status {
+	case api.OperStatusUp, api.OperStatusDown, api.OperStatusTesting, api.OperStatusUnknown,
+		api.OperStatusDormant, api.OperStatusNotPresent, api.OperStatusLowerLayerDown:
+	default:
+		return nil, fmt.Errorf("unknown operational status %q", status)
+	}
+	return func(iface *api.NetworkInterfaceV2) bool {
+		return iface.OperStatus == status
+	}, nil
+}
+
+// adminStatusMatcher matches the administrative status of interfaces.
+func adminStatusMatcher(status string) (interfaceMatcher, error) {
+	if status != api.AdminStatusUp && status != api.AdminStatusDown {
+		return nil, fmt.Errorf("expected %s or %s", api.AdminStatusUp, api.AdminStatusDown)
+	}
+	return func(iface *api.NetworkInterfaceV2) bool {
+		return iface.AdminStatus == status
+	}, nil
+}
+
+// v1OperStatusMatcher matches the operational status of interfaces as the v1 model reports it.
+func v1OperStatusMatcher(status string) (interfaceMatcher, error) {
+	if status != "UP" && status != "DOWN" && status != "unknown" {
+		return nil, errors.New("expected UP, DOWN or unknown")
+	}
+	return func(iface *api.NetworkInterfaceV2) bool {
+		return iface.V1().OperationalStatus == status
+	}, nil
+}
+
+// v1AdminStatusMatcher matches the administrative status of interfaces as the v1 model reports it.
+func v1AdminStatusMatcher(status string) (interfaceMatcher, error) {
+	if status != "enabled" && status != "disabled" && status != "unknown" {
+		return nil, errors.New("expected enabled, disabled or unknown")
+	}
+	return func(iface *api.NetworkInterfaceV2) bool {
+		return iface.V1().AdminStatus == status
+	}, nil
+}
+
+// familyMatcher returns the parser of a parameter that matches interfaces by whether they have
+// an address of the family, including link-local addresses.
+func familyMatcher(family string) filterParser {
+	return func(value string) (interfaceMatcher, error) {
+		want, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, errors.New("expected true or false")
+		}
+		return func(iface *api.NetworkInterfaceV2) bool {
+			has := false
+			for _, addr := range iface.Addresses {
+				if addr.Family == family {
+					has = true
+					break
+				}
+			}
+			return has == want
+		}, nil
+	}
+}
+
+// cidrMatcher matches interfaces that have an address within a prefix in CIDR notation.
+func cidrMatcher(value string) (interfaceMatcher, error) {
+	_, prefix, err := net.ParseCIDR(value)
+	if err != nil {
+		return nil, errors.New("expected a prefix in CIDR notation")
+	}
+	return func(iface *api.NetworkInterfaceV2) bool {
+		for _, addr := range iface.Addresses {
+			if ip := net.ParseIP(addr.Address); ip != nil && prefix.Contains(ip) {
+				return true
+			}
+		}
+		return false
+	}, nil
+}
+
+// macPrefixMatcher matches interfaces whose MAC address starts with the given octets, e.g. the
+// OUI of a vendor. The octets are separated by colons and compared case-insensitively.
+func macPrefixMatcher(value string) (interfaceMatcher, error) {
+	prefix := strings.ToLower(value)
+	if !macPrefixPattern.MatchString(prefix) {
+		return nil, errors.New("expected hexadecimal octets separated by colons, e.g. 00:11:22")
+	}
+	return func(iface *api.NetworkInterfaceV2) bool {
+		return strings.HasPrefix(strings.ToLower(iface.MACAddress), prefix)
+	}, nil
+}
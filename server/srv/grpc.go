@@ -0,0 +1,306 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	api "apimodule"
+	"servermodule/rpc"
+	models "servermodule/servermodels"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// changeEvents are the event types watched by WatchInterfaces requests that don't name any.
+var changeEvents = []string{
+	api.EventInterfaceCreated, api.EventInterfaceDeleted, api.EventLinkUp, api.EventLinkDown,
+	api.EventAddressAdded, api.EventAddressRemoved, api.EventMTUChanged,
+	api.EventDriftDetected, api.EventDriftResolved,
+}
+
+// The grpcService struct implements the Interfacer gRPC service. It serves the interfaces of
+// the same collector as the HTTP API and shares its traffic counter rates and change events.
+type grpcService struct {
+	rpc.UnimplementedInterfacerServer
+	s *server
+}
+
+// The newGRPCServer() method creates a gRPC server serving the Interfacer service, the standard
+// health service, which reports the Interfacer service as serving, and server reflection.
+func (s *server) newGRPCServer() (*grpc.Server, *health.Server) {
+	g := grpc.NewServer(
+		grpc.ChainUnaryInterceptor(logUnaryErrors),
+		grpc.ChainStreamInterceptor(logStreamErrors),
+	)
+	rpc.RegisterInterfacerServer(g, &grpcService{s: s})
+
+	healthServer := health.NewServer()
+	healthServer.SetServingStatus(rpc.Interfacer_ServiceDesc.ServiceName, healthpb.HealthCheckResponse_SERVING)
+	healthpb.RegisterHealthServer(g, healthServer)
+
+	reflection.Register(g)
+	return g, healthServer
+}
+
+// logUnaryErrors logs the errors returned by unary RPCs, as the HTTP API logs its error responses.
+func logUnaryErrors(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	resp, err := handler(ctx, req)
+	if err != nil {
+		logError(info.FullMethod, err)
+	}
+	return resp, err
+}
+
+// logStreamErrors logs the errors that end streaming RPCs, except for cancellations by the client.
+func logStreamErrors(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	err := handler(srv, ss)
+	if err != nil && status.Code(err) != codes.Canceled {
+		logError(info.FullMethod, err)
+	}
+	return err
+}
+
+// logError logs the status of a failed RPC.
+func logError(method string, err error) {
+	st := status.Convert(err)
+	log.Printf("gRPC error %s in %s: %s", st.Code(), method, st.Message())
+}
+
+// collectorError converts an error of the collector to a gRPC status error.
+func collectorError(err error) error {
+	if errors.Is(err, models.ErrNoSuchInterface) {
+		return status.Error(codes.NotFound, err.Error())
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// ListInterfaces returns the interfaces whose names match any of the requested glob patterns,
+// or all interfaces if there are none.
+func (g *grpcService) ListInterfaces(ctx context.Context, req *rpc.ListInterfacesRequest) (*rpc.ListInterfacesResponse, error) {
+	var filter interfaceFilter
+	if len(req.GetNames()) > 0 {
+		if err := filter.add("name", nameMatcher, req.GetNames()); err != nil {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+
+	details := models.AllDetails
+	if !req.GetWithStats() {
+		details &^= models.DetailStats
+	}
+	interfaces, err := models.CollectInterfaces(g.s.collector, details)
+	if err != nil {
+		return nil, collectorError(err)
+	}
+
+	resp := &rpc.ListInterfacesResponse{}
+	for _, iface := range filter.apply(interfaces) {
+		resp.Interfaces = append(resp.Interfaces, g.interfaceProto(&iface, req.GetWithStats()))
+	}
+	return resp, nil
+}
+
+// GetInterface returns a single interface.
+func (g *grpcService) GetInterface(ctx context.Context, req *rpc.GetInterfaceRequest) (*rpc.NetworkInterface, error) {
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "the interface name is required")
+	}
+
+	details := models.AllDetails
+	if !req.GetWithStats() {
+		details &^= models.DetailStats
+	}
+	iface, err := models.CollectInterface(g.s.collector, req.GetName(), details)
+	if err != nil {
+		return nil, collectorError(err)
+	}
+	return g.interfaceProto(iface, req.GetWithStats()), nil
+}
+
+// GetStats returns the traffic counters of a single interface along with the per-second rates
+// since the previous sample of that interface.
+func (g *grpcService) GetStats(ctx context.Context, req *rpc.GetStatsRequest) (*rpc.NetworkInterfaceStats, error) {
+	if req.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "the interface name is required")
+	}
+
+	iface, err := models.CollectInterface(g.s.collector, req.GetName(), models.DetailStats)
+	if err != nil {
+		return nil, collectorError(err)
+	}
+	if iface.Stats == nil {
+		return nil, status.Error(codes.NotFound, "there are no statistics for this interface")
+	}
+
+	g.s.stats.observe(iface.Name, iface.Stats)
+	return statsProto(api.NetworkInterfaceStats{Name: iface.Name, Stats: *iface.Stats}), nil
+}
+
+// WatchInterfaces streams the change events of the requested interfaces and event types, and
+// their latest sampled traffic counters after every sample if the stats event type is requested.
+// Clients resuming with the ID of the last event they received first receive the events they
+// missed, as far as they are still in the backlog.
+func (g *grpcService) WatchInterfaces(req *rpc.WatchInterfacesRequest, stream rpc.Interfacer_WatchInterfacesServer) error {
+	watched := api.Subscription{Action: api.ActionSubscribe, Interfaces: req.GetInterfaces(), Events: req.GetEvents()}
+	if len(watched.Interfaces) == 0 {
+		watched.Interfaces = []string{wildcard}
+	}
+	if len(watched.Events) == 0 {
+		watched.Events = changeEvents
+	}
+	sub := newSubscription()
+	if _, err := sub.apply(watched); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	missed, events, cancel := g.s.events.subscribe(req.GetLastEventId())
+	defer cancel()
+
+	write := func(msg api.Message) bool {
+		resp := &rpc.WatchInterfacesResponse{}
+		switch {
+		case msg.Event != nil:
+			resp.Message = &rpc.WatchInterfacesResponse_Event{Event: eventProto(*msg.Event)}
+		case msg.Stats != nil:
+			resp.Message = &rpc.WatchInterfacesResponse_Stats{Stats: statsProto(*msg.Stats)}
+		}
+		return stream.Send(resp) == nil
+	}
+
+	for _, event := range missed {
+		if sub.matches(event.Interface, event.Type) && !write(api.Message{Type: api.MessageEvent, Event: &event}) {
+			return nil
+		}
+	}
+
+	statsTicker := time.NewTicker(g.s.sampler.interval)
+	defer statsTicker.Stop()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case event, ok := <-events:
+			if !ok {
+				return status.Error(codes.ResourceExhausted, "the watcher fell too far behind, resume with the ID of the last event received")
+			}
+			if sub.matches(event.Interface, event.Type) && !write(api.Message{Type: api.MessageEvent, Event: &event}) {
+				return nil
+			}
+		case <-statsTicker.C:
+			if !g.s.writeStats(sub, write) {
+				return nil
+			}
+		}
+	}
+}
+
+// The interfaceProto() method converts an interface of the v2 model to its gRPC message.
+// Traffic counters are only included, and their rates tracked, if requested.
+func (g *grpcService) interfaceProto(iface *api.NetworkInterfaceV2, withStats bool) *rpc.NetworkInterface {
+	msg := &rpc.NetworkInterface{
+		Name:        iface.Name,
+		Kind:        iface.Kind,
+		Alias:       iface.Alias,
+		MacAddress:  iface.MACAddress,
+		Mtu:         int32(iface.MTU),
+		SpeedMbps:   iface.SpeedMbps,
+		Duplex:      iface.Duplex,
+		AdminStatus: iface.AdminStatus,
+		OperStatus:  iface.OperStatus,
+		Master:      iface.Master,
+		Slaves:      iface.Slaves,
+	}
+
+	for _, addr := range iface.Addresses {
+		msg.Addresses = append(msg.Addresses, &rpc.Address{
+			Address:      addr.Address,
+			PrefixLength: int32(addr.PrefixLength),
+			Family:       addr.Family,
+			Scope:        addr.Scope,
+			Flags:        addr.Flags,
+		})
+	}
+
+	if w := iface.Wireless; w != nil {
+		msg.Wireless = &rpc.Wireless{
+			Mode:             w.Mode,
+			Connected:        w.Connected,
+			Ssid:             w.SSID,
+			Bssid:            w.BSSID,
+			FrequencyMhz:     int32(w.FrequencyMHz),
+			Channel:          int32(w.Channel),
+			TxBitrateMbps:    w.TxBitrateMbps,
+			RxBitrateMbps:    w.RxBitrateMbps,
+			ConnectedSeconds: w.ConnectedSeconds,
+		}
+		if w.SignalDBm != nil {
+			signal := int32(*w.SignalDBm)
+			msg.Wireless.SignalDbm = &signal
+		}
+	}
+
+	if withStats && iface.Stats != nil {
+		g.s.stats.observe(iface.Name, iface.Stats)
+		msg.Stats = statsProto(api.NetworkInterfaceStats{Name: iface.Name, Stats: *iface.Stats}).Stats
+	}
+	return msg
+}
+
+// statsProto converts the traffic counters of an interface to their gRPC message.
+func statsProto(stats api.NetworkInterfaceStats) *rpc.NetworkInterfaceStats {
+	s := stats.Stats
+	msg := &rpc.InterfaceStats{
+		RxBytes:    s.RxBytes,
+		TxBytes:    s.TxBytes,
+		RxPackets:  s.RxPackets,
+		TxPackets:  s.TxPackets,
+		RxErrors:   s.RxErrors,
+		TxErrors:   s.TxErrors,
+		RxDropped:  s.RxDropped,
+		TxDropped:  s.TxDropped,
+		Multicast:  s.Multicast,
+		Collisions: s.Collisions,
+	}
+	if r := s.Rates; r != nil {
+		msg.Rates = &rpc.InterfaceRates{
+			IntervalSeconds: r.Interval,
+			RxBytes:         r.RxBytes,
+			TxBytes:         r.TxBytes,
+			RxPackets:       r.RxPackets,
+			TxPackets:       r.TxPackets,
+			RxErrors:        r.RxErrors,
+			TxErrors:        r.TxErrors,
+			RxDropped:       r.RxDropped,
+			TxDropped:       r.TxDropped,
+			Multicast:       r.Multicast,
+			Collisions:      r.Collisions,
+		}
+	}
+	return &rpc.NetworkInterfaceStats{Name: stats.Name, Stats: msg}
+}
+
+// eventProto converts a change event to its gRPC message.
+func eventProto(event api.Event) *rpc.Event {
+	msg := &rpc.Event{
+		Id:        event.ID,
+		Type:      event.Type,
+		Interface: event.Interface,
+		Timestamp: timestamppb.New(event.Timestamp),
+		Address:   event.Address,
+		OldMtu:    int32(event.OldMTU),
+		NewMtu:    int32(event.NewMTU),
+	}
+	for _, change := range event.Changes {
+		msg.Changes = append(msg.Changes, &rpc.FieldChange{Field: change.Field, Old: change.Old, New: change.New})
+	}
+	return msg
+}
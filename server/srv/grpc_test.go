@@ -0,0 +1,166 @@
+package server
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	api "apimodule"
+	"servermodule/rpc"
+	models "servermodule/servermodels"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+// dialGRPC serves the gRPC service of a server on the sysfs fixture tree in memory and connects to it.
+func dialGRPC(t *testing.T) (*server, *grpc.ClientConn) {
+	t.Helper()
+
+	s := NewServer(models.NewSysfsCollector("../servermodels/testdata"), NewConfig())
+	g, _ := s.newGRPCServer()
+	listener := bufconn.Listen(1 << 20)
+	go g.Serve(listener)
+	t.Cleanup(g.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return listener.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return s, conn
+}
+
+// TestGRPCService tests the unary RPCs of the Interfacer service and the health service.
+func TestGRPCService(t *testing.T) {
+	s, conn := dialGRPC(t)
+	// Every RPC happens a second after the previous one, so rates are derived between them
+	now := time.Now()
+	s.stats.now = func() time.Time {
+		now = now.Add(minRateInterval)
+		return now
+	}
+	client := rpc.NewInterfacerClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	list, err := client.ListInterfaces(ctx, &rpc.ListInterfacesRequest{Names: []string{"eth*", "lo"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list.Interfaces) != 2 || list.Interfaces[0].Name != "eth0" || list.Interfaces[1].Name != "lo" {
+		t.Fatalf("unexpected interfaces: %v", list.Interfaces)
+	}
+	if eth0 := list.Interfaces[0]; eth0.Mtu != 1500 || eth0.GetSpeedMbps() != 1000 || eth0.Duplex != api.DuplexFull || eth0.Stats != nil {
+		t.Errorf("unexpected eth0: %v", eth0)
+	}
+
+	iface, err := client.GetInterface(ctx, &rpc.GetInterfaceRequest{Name: "lo", WithStats: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if iface.OperStatus != api.OperStatusUnknown || iface.SpeedMbps != nil || iface.Stats == nil {
+		t.Errorf("unexpected lo: %v", iface)
+	}
+
+	stats, err := client.GetStats(ctx, &rpc.GetStatsRequest{Name: "lo"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if stats.Name != "lo" || stats.Stats.Rates == nil {
+		t.Errorf("expected the rates since the previous sample: %v", stats)
+	}
+
+	errorTests := []struct {
+		name     string
+		call     func() error
+		expected codes.Code
+	}{
+		{name: "InvalidPattern", call: func() error {
+			_, err := client.ListInterfaces(ctx, &rpc.ListInterfacesRequest{Names: []string{"eth["}})
+			return err
+		}, expected: codes.InvalidArgument},
+		{name: "MissingName", call: func() error {
+			_, err := client.GetInterface(ctx, &rpc.GetInterfaceRequest{})
+			return err
+		}, expected: codes.InvalidArgument},
+		{name: "NoSuchInterface", call: func() error {
+			_, err := client.GetInterface(ctx, &rpc.GetInterfaceRequest{Name: "eth9"})
+			return err
+		}, expected: codes.NotFound},
+		{name: "NoSuchStats", call: func() error {
+			_, err := client.GetStats(ctx, &rpc.GetStatsRequest{Name: "eth9"})
+			return err
+		}, expected: codes.NotFound},
+	}
+	for _, test := range errorTests {
+		t.Run(test.name, func(t *testing.T) {
+			if code := status.Code(test.call()); code != test.expected {
+				t.Errorf("unexpected status code: got %v want %v", code, test.expected)
+			}
+		})
+	}
+
+	health, err := healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{Service: "interfacer.v1.Interfacer"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if health.Status != healthpb.HealthCheckResponse_SERVING {
+		t.Errorf("unexpected health status: %v", health.Status)
+	}
+}
+
+// TestGRPCWatch tests that WatchInterfaces resumes after the last event and streams the
+// subscribed events only.
+func TestGRPCWatch(t *testing.T) {
+	s, conn := dialGRPC(t)
+	client := rpc.NewInterfacerClient(conn)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	now := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+	s.events.publish([]api.Event{
+		{Type: api.EventLinkUp, Interface: "eth0", Timestamp: now},
+		{Type: api.EventLinkDown, Interface: "eth0", Timestamp: now},
+	})
+
+	stream, err := client.WatchInterfaces(ctx, &rpc.WatchInterfacesRequest{Interfaces: []string{"eth0"}, LastEventId: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The missed event is received first
+	resp, err := stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event := resp.GetEvent(); event.GetId() != 2 || event.GetType() != api.EventLinkDown || !event.GetTimestamp().AsTime().Equal(now) {
+		t.Errorf("unexpected missed event: %v", resp)
+	}
+
+	// Events of other interfaces are left out, publish until the stream is subscribed
+	go func() {
+		for ctx.Err() == nil {
+			s.events.publish([]api.Event{
+				{Type: api.EventMTUChanged, Interface: "wlan0", OldMTU: 1500, NewMTU: 1200},
+				{Type: api.EventMTUChanged, Interface: "eth0", OldMTU: 1500, NewMTU: 9000},
+			})
+			time.Sleep(10 * time.Millisecond)
+		}
+	}()
+
+	resp, err = stream.Recv()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if event := resp.GetEvent(); event.GetInterface() != "eth0" || event.GetOldMtu() != 1500 || event.GetNewMtu() != 9000 {
+		t.Errorf("unexpected event: %v", resp)
+	}
+}
@@ -0,0 +1,155 @@
+package server
+
+import (
+	"bytes"
+	"net/http"
+	"time"
+
+	api "apimodule"
+	"servermodule/metrics"
+	models "servermodule/servermodels"
+)
+
+// The instrumentedCollector struct wraps a collector and records the duration of every call.
+type instrumentedCollector struct {
+	models.Collector
+	registry *metrics.Registry
+}
+
+// Interfaces returns details about all available network interfaces.
+func (c *instrumentedCollector) Interfaces() ([]api.NetworkInterfaceV2, error) {
+	start := time.Now()
+	interfaces, err := c.Collector.Interfaces()
+	c.registry.ObserveCollection("interfaces", time.Since(start), err)
+	return interfaces, err
+}
+
+// Interface returns the details of a network interface by its name.
+func (c *instrumentedCollector) Interface(name string) (*api.NetworkInterfaceV2, error) {
+	start := time.Now()
+	iface, err := c.Collector.Interface(name)
+	c.registry.ObserveCollection("interface", time.Since(start), err)
+	return iface, err
+}
+
+// InterfacesWith returns details about all available network interfaces with the selected details.
+func (c *instrumentedCollector) InterfacesWith(details models.Details) ([]api.NetworkInterfaceV2, error) {
+	start := time.Now()
+	interfaces, err := models.CollectInterfaces(c.Collector, details)
+	c.registry.ObserveCollection("interfaces", time.Since(start), err)
+	return interfaces, err
+}
+
+// InterfaceWith returns the details of a network interface by its name with the selected details.
+func (c *instrumentedCollector) InterfaceWith(name string, details models.Details) (*api.NetworkInterfaceV2, error) {
+	start := time.Now()
+	iface, err := models.CollectInterface(c.Collector, name, details)
+	c.registry.ObserveCollection("interface", time.Since(start), err)
+	return iface, err
+}
+
+// The metricsHandler() method is the handler function for the /metrics endpoint.
+// It collects the current state of every interface and writes it, followed by the
+// self-metrics of the server, in the Prometheus text exposition format.
+func (s *server) metricsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// The gauges export every detail, the address count and wireless state along with speed and counters
+		interfaces, err := models.CollectInterfaces(s.collector, models.AllDetails)
+		if err != nil {
+			s.error(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		// Render into a buffer first, so that errors can still be reported with a status code
+		var buf bytes.Buffer
+		mw := metrics.NewWriter(&buf)
+		writeInterfaceMetrics(mw, interfaces)
+		s.desired.writeMetrics(mw)
+		s.metrics.Write(mw)
+
+		w.Header().Set("Content-Type", metrics.ContentType)
+		w.WriteHeader(http.StatusOK)
+		buf.WriteTo(w)
+	}
+}
+
+// interfaceCounter describes a traffic counter exported as a Prometheus counter.
+type interfaceCounter struct {
+	name  string
+	help  string
+	value func(*api.InterfaceStats) uint64
+}
+
+// interfaceCounters are the traffic counters exported for every interface.
+var interfaceCounters = []interfaceCounter{
+	{"interfacer_interface_receive_bytes_total", "Number of bytes received.", func(s *api.InterfaceStats) uint64 { return s.RxBytes }},
+	{"interfacer_interface_transmit_bytes_total", "Number of bytes transmitted.", func(s *api.InterfaceStats) uint64 { return s.TxBytes }},
+	{"interfacer_interface_receive_packets_total", "Number of packets received.", func(s *api.InterfaceStats) uint64 { return s.RxPackets }},
+	{"interfacer_interface_transmit_packets_total", "Number of packets transmitted.", func(s *api.InterfaceStats) uint64 { return s.TxPackets }},
+	{"interfacer_interface_receive_errors_total", "Number of bad packets received.", func(s *api.InterfaceStats) uint64 { return s.RxErrors }},
+	{"interfacer_interface_transmit_errors_total", "Number of packets that failed to transmit.", func(s *api.InterfaceStats) uint64 { return s.TxErrors }},
+	{"interfacer_interface_receive_drop_total", "Number of received packets dropped.", func(s *api.InterfaceStats) uint64 { return s.RxDropped }},
+	{"interfacer_interface_transmit_drop_total", "Number of packets dropped before transmission.", func(s *api.InterfaceStats) uint64 { return s.TxDropped }},
+	{"interfacer_interface_multicast_total", "Number of multicast packets received.", func(s *api.InterfaceStats) uint64 { return s.Multicast }},
+	{"interfacer_interface_collisions_total", "Number of collisions during transmission.", func(s *api.InterfaceStats) uint64 { return s.Collisions }},
+}
+
+// writeInterfaceMetrics writes the state and traffic counters of the interfaces.
+func writeInterfaceMetrics(w *metrics.Writer, interfaces []api.NetworkInterfaceV2) {
+	gauge := func(name, help string, value func(api.NetworkInterfaceV2) (float64, bool)) {
+		w.Family(name, help, "gauge")
+		for _, iface := range interfaces {
+			if v, ok := value(iface); ok {
+				w.Sample(name, metrics.Labels{"name": iface.Name}, v)
+			}
+		}
+	}
+
+	gauge("interfacer_interface_up", "Whether the operational status of the interface is UP.", func(iface api.NetworkInterfaceV2) (float64, bool) {
+		return boolValue(iface.OperStatus == api.OperStatusUp), true
+	})
+	gauge("interfacer_interface_admin_up", "Whether the interface is administratively enabled.", func(iface api.NetworkInterfaceV2) (float64, bool) {
+		return boolValue(iface.AdminStatus == api.AdminStatusUp), true
+	})
+	gauge("interfacer_interface_mtu", "Maximum Transmission Unit of the interface in bytes.", func(iface api.NetworkInterfaceV2) (float64, bool) {
+		return float64(iface.MTU), true
+	})
+	gauge("interfacer_interface_speed_bps", "Link speed of the interface in bits per second.", func(iface api.NetworkInterfaceV2) (float64, bool) {
+		if iface.SpeedMbps == nil {
+			return 0, false
+		}
+		return float64(*iface.SpeedMbps) * 1e6, true
+	})
+	gauge("interfacer_interface_address_count", "Number of IP addresses assigned to the interface.", func(iface api.NetworkInterfaceV2) (float64, bool) {
+		return float64(len(iface.Addresses)), true
+	})
+	gauge("interfacer_interface_wireless_connected", "Whether the wireless station is associated with an access point.", func(iface api.NetworkInterfaceV2) (float64, bool) {
+		if iface.Wireless == nil {
+			return 0, false
+		}
+		return boolValue(iface.Wireless.Connected), true
+	})
+	gauge("interfacer_interface_wireless_signal_dbm", "Signal strength of the access point in dBm.", func(iface api.NetworkInterfaceV2) (float64, bool) {
+		if iface.Wireless == nil || iface.Wireless.SignalDBm == nil {
+			return 0, false
+		}
+		return float64(*iface.Wireless.SignalDBm), true
+	})
+
+	for _, counter := range interfaceCounters {
+		w.Family(counter.name, counter.help, "counter")
+		for _, iface := range interfaces {
+			if iface.Stats != nil {
+				w.Sample(counter.name, metrics.Labels{"name": iface.Name}, float64(counter.value(iface.Stats)))
+			}
+		}
+	}
+}
+
+// boolValue converts a boolean to the 0 or 1 value of a Prometheus gauge.
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	api "apimodule"
+	models "servermodule/servermodels"
+)
+
+// The neighborsHandler() method is the handler function for the /neighbors and
+// /network/{name}/neighbors endpoints. It returns the IPv4 (ARP) and IPv6 (NDP) neighbor
+// table entries of all interfaces, or only of the interface in the path.
+func (s *server) neighborsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		collector, ok := capability[models.NeighborCollector](s, w)
+		if !ok {
+			return
+		}
+
+		name := r.PathValue("name")
+		if name != "" {
+			// Distinguish unknown interfaces from interfaces without neighbors
+			_, err := models.CollectInterface(s.collector, name, 0)
+			if errors.Is(err, models.ErrNoSuchInterface) {
+				s.error(w, http.StatusNotFound, err)
+				return
+			}
+			if err != nil {
+				s.error(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		neighbors, err := collector.Neighbors()
+		if err != nil {
+			s.error(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		response := api.Neighbors{Neighbors: []api.Neighbor{}}
+		for _, neighbor := range neighbors {
+			if name == "" || neighbor.Interface == name {
+				response.Neighbors = append(response.Neighbors, neighbor)
+			}
+		}
+
+		s.respond(w, http.StatusOK, response)
+	}
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"os"
+	"strings"
+
+	api "apimodule"
+	models "servermodule/servermodels"
+)
+
+// The namespacesHandler() method is the handler function for the /netns endpoint.
+// It returns the named network namespaces and, if enabled in the configuration,
+// the namespaces of processes referenced by their PID.
+func (s *server) namespacesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		collector, ok := capability[models.NamespaceCollector](s, w)
+		if !ok {
+			return
+		}
+
+		namespaces, err := collector.Namespaces(s.netnsPIDs)
+		if err != nil {
+			s.error(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, http.StatusOK, api.Namespaces{Namespaces: namespaces})
+	}
+}
+
+// The requestCollector() method returns the collector for the network namespace in the path of
+// the request, or the collector of the server if the path has none. It responds with an error
+// and reports false if the namespace doesn't exist or can't be entered.
+func (s *server) requestCollector(w http.ResponseWriter, r *http.Request) (models.Collector, bool) {
+	ns := r.PathValue("ns")
+	if ns == "" {
+		return s.collector, true
+	}
+
+	collector, ok := capability[models.NamespaceCollector](s, w)
+	if !ok {
+		return nil, false
+	}
+
+	if strings.HasPrefix(ns, "pid:") && !s.netnsPIDs {
+		s.error(w, http.StatusForbidden, errors.New("network namespaces by PID are disabled"))
+		return nil, false
+	}
+
+	nsCollector, err := collector.InNamespace(ns)
+	if errors.Is(err, models.ErrNoSuchNamespace) {
+		s.error(w, http.StatusNotFound, err)
+		return nil, false
+	}
+	if errors.Is(err, os.ErrPermission) {
+		s.error(w, http.StatusForbidden, err)
+		return nil, false
+	}
+	if err != nil {
+		s.error(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	return nsCollector, true
+}
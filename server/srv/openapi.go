@@ -0,0 +1,36 @@
+package server
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+)
+
+// openapiDocument is the OpenAPI 3 description of the HTTP API.
+//
+//go:embed openapi.json
+var openapiDocument []byte
+
+// docsPage is the page rendering the OpenAPI document in the browser.
+//
+//go:embed docs.html
+var docsPage []byte
+
+// The openapiHandler() method is the handler function for the /openapi.json endpoint.
+// It returns the OpenAPI document of the API, in the negotiated format like every other response.
+func (s *server) openapiHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.respond(w, http.StatusOK, json.RawMessage(openapiDocument))
+	}
+}
+
+// The docsHandler() method is the handler function for the /docs endpoint.
+// It serves a self-contained page that fetches the OpenAPI document and lists the operations,
+// their parameters and response schemas, and lets them be tried out against the server.
+func (s *server) docsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write(docsPage)
+	}
+}
@@ -0,0 +1,501 @@
+package server_test
+
+import (
+	api "apimodule"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	models "servermodule/servermodels"
+	server "servermodule/srv"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+)
+
+// specCollector serves the sysfs fixture tree with every capability of the collector, so that
+// every operation of the API can be exercised against a single server.
+type specCollector struct {
+	*configuratorCollector
+}
+
+func (c specCollector) Routes() ([]api.Route, error) {
+	return routeCollector{c.SysfsCollector}.Routes()
+}
+
+func (c specCollector) Rules() ([]api.Rule, error) {
+	return routeCollector{c.SysfsCollector}.Rules()
+}
+
+func (c specCollector) LookupRoute(dst net.IP) (*api.Route, error) {
+	return routeCollector{c.SysfsCollector}.LookupRoute(dst)
+}
+
+func (c specCollector) Neighbors() ([]api.Neighbor, error) {
+	return neighborCollector{c.SysfsCollector}.Neighbors()
+}
+
+func (c specCollector) Namespaces(withPIDs bool) ([]api.Namespace, error) {
+	return namespaceCollector{c.SysfsCollector}.Namespaces(withPIDs)
+}
+
+func (c specCollector) InNamespace(ref string) (models.Collector, error) {
+	return namespaceCollector{c.SysfsCollector}.InNamespace(ref)
+}
+
+func (c specCollector) Ethtool(name string) (*api.Ethtool, error) {
+	return ethtoolCollector{c.SysfsCollector}.Ethtool(name)
+}
+
+// openapiSpec is the decoded OpenAPI document, with numbers kept as json.Number.
+type openapiSpec map[string]any
+
+// resolve follows the local $ref of a schema, parameter or response.
+func (spec openapiSpec) resolve(obj map[string]any) map[string]any {
+	for {
+		ref, ok := obj["$ref"].(string)
+		if !ok {
+			return obj
+		}
+		var node any = map[string]any(spec)
+		for _, key := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			node = node.(map[string]any)[key]
+		}
+		obj = node.(map[string]any)
+	}
+}
+
+// operation returns the path template and the operation documented for a request path.
+// Literal path segments take precedence over parameters, as they do in the router.
+func (spec openapiSpec) operation(method, path string) (string, map[string]any) {
+	segments := strings.Split(path, "/")
+	best, bestParams := "", len(segments)+1
+
+	for template := range spec["paths"].(map[string]any) {
+		templateSegments := strings.Split(template, "/")
+		if len(templateSegments) != len(segments) {
+			continue
+		}
+
+		params := 0
+		for i, segment := range templateSegments {
+			if strings.HasPrefix(segment, "{") && segments[i] != "" {
+				params++
+			} else if segment != segments[i] {
+				params = -1
+				break
+			}
+		}
+		if params >= 0 && params < bestParams {
+			best, bestParams = template, params
+		}
+	}
+
+	if best == "" {
+		return "", nil
+	}
+	op, _ := spec["paths"].(map[string]any)[best].(map[string]any)[strings.ToLower(method)].(map[string]any)
+	return best, op
+}
+
+// validate checks a decoded JSON value against a schema. It supports the subset of the
+// schema keywords the document uses.
+func (spec openapiSpec) validate(schema map[string]any, value any, at string) error {
+	schema = spec.resolve(schema)
+
+	if value == nil {
+		if schema["nullable"] == true {
+			return nil
+		}
+		return fmt.Errorf("%s: unexpected null", at)
+	}
+
+	if oneOf, ok := schema["oneOf"].([]any); ok {
+		var matches int
+		var errs []string
+		for _, option := range oneOf {
+			if err := spec.validate(option.(map[string]any), value, at); err != nil {
+				errs = append(errs, err.Error())
+			} else {
+				matches++
+			}
+		}
+		if matches != 1 {
+			return fmt.Errorf("%s: matches %d of the oneOf schemas: %s", at, matches, strings.Join(errs, "; "))
+		}
+		return nil
+	}
+
+	if enum, ok := schema["enum"].([]any); ok {
+		found := false
+		for _, allowed := range enum {
+			found = found || allowed == value
+		}
+		if !found {
+			return fmt.Errorf("%s: %v is not one of %v", at, value, enum)
+		}
+	}
+
+	switch schema["type"] {
+	case "object":
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return fmt.Errorf("%s: expected an object, got %T", at, value)
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := obj[name.(string)]; !ok {
+				return fmt.Errorf("%s: missing required property %s", at, name)
+			}
+		}
+		for name, v := range obj {
+			property, ok := properties[name].(map[string]any)
+			if !ok {
+				if schema["additionalProperties"] == false {
+					return fmt.Errorf("%s: undocumented property %s", at, name)
+				}
+				continue
+			}
+			if err := spec.validate(property, v, at+"."+name); err != nil {
+				return err
+			}
+		}
+	case "array":
+		items, ok := value.([]any)
+		if !ok {
+			return fmt.Errorf("%s: expected an array, got %T", at, value)
+		}
+		for i, item := range items {
+			if err := spec.validate(schema["items"].(map[string]any), item, fmt.Sprintf("%s[%d]", at, i)); err != nil {
+				return err
+			}
+		}
+	case "string":
+		s, ok := value.(string)
+		if !ok {
+			return fmt.Errorf("%s: expected a string, got %T", at, value)
+		}
+		if schema["format"] == "date-time" {
+			if _, err := time.Parse(time.RFC3339Nano, s); err != nil {
+				return fmt.Errorf("%s: %v", at, err)
+			}
+		}
+	case "integer", "number":
+		n, ok := value.(json.Number)
+		if !ok {
+			return fmt.Errorf("%s: expected a number, got %T", at, value)
+		}
+		if schema["type"] == "integer" && strings.ContainsAny(n.String(), ".eE") {
+			return fmt.Errorf("%s: expected an integer, got %s", at, n)
+		}
+		if minimum, ok := schema["minimum"].(json.Number); ok {
+			if v, _ := n.Float64(); v < mustFloat(minimum) {
+				return fmt.Errorf("%s: %s is less than %s", at, n, minimum)
+			}
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected a boolean, got %T", at, value)
+		}
+	}
+	return nil
+}
+
+func mustFloat(n json.Number) float64 {
+	f, _ := n.Float64()
+	return f
+}
+
+// TestOpenAPI tests that every operation of the OpenAPI document is served, and that every
+// response has a documented status code, content type and headers and matches its schema.
+func TestOpenAPI(t *testing.T) {
+	newCollector := func() models.Collector {
+		return specCollector{&configuratorCollector{SysfsCollector: models.NewSysfsCollector("../servermodels/testdata")}}
+	}
+	withToken := server.NewConfig()
+	withToken.WriteToken = "secret"
+
+	servers := map[string]http.Handler{
+		// Every capability with write access
+		"full": server.NewServer(newCollector(), withToken),
+		// Every capability with write operations disabled
+		"disabled": server.NewServer(newCollector(), server.NewConfig()),
+		// The capabilities of the sysfs collector only
+		"sysfs": server.NewServer(models.NewSysfsCollector("../servermodels/testdata"), withToken),
+	}
+
+	rr := httptest.NewRecorder()
+	servers["full"].ServeHTTP(rr, httptest.NewRequest("GET", "/openapi.json", nil))
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	decoder := json.NewDecoder(rr.Body)
+	decoder.UseNumber()
+	var spec openapiSpec
+	if err := decoder.Decode(&spec); err != nil {
+		t.Fatalf("failed to decode the OpenAPI document: %v", err)
+	}
+
+	// {id} in the paths is replaced by the ID of the latest pending change
+	tests := []struct {
+		name         string
+		server       string
+		noToken      bool
+		method       string
+		path         string
+		accept       string
+		lastEventID  string
+		body         string
+		expectedCode int
+	}{
+		{name: "List", method: "GET", path: "/v2/network", expectedCode: http.StatusOK},
+		{name: "ListFiltered", method: "GET", path: "/v2/network?name=eth*&stats=true", expectedCode: http.StatusOK},
+		{name: "ListNoMatch", method: "GET", path: "/v2/network?kind=bond", expectedCode: http.StatusOK},
+		{name: "ListInvalidQuery", method: "GET", path: "/v2/network?foo=bar", expectedCode: http.StatusBadRequest},
+		{name: "ListNoSuchInterface", method: "GET", path: "/v2/network?interface=eth9", expectedCode: http.StatusNotFound},
+		{name: "ListNotAcceptable", method: "GET", path: "/v2/network?format=svg", expectedCode: http.StatusNotAcceptable},
+		{name: "Interface", method: "GET", path: "/v2/network/eth0", expectedCode: http.StatusOK},
+		{name: "InterfaceWireless", method: "GET", path: "/v2/network/wlan0", expectedCode: http.StatusOK},
+		{name: "InterfaceInvalidFields", method: "GET", path: "/v2/network/eth0?fields=foo", expectedCode: http.StatusBadRequest},
+		{name: "InterfaceNotFound", method: "GET", path: "/v2/network/eth9", expectedCode: http.StatusNotFound},
+		{name: "Stats", method: "GET", path: "/v2/network/lo/stats", expectedCode: http.StatusOK},
+		{name: "StatsNotFound", method: "GET", path: "/v2/network/eth9/stats", expectedCode: http.StatusNotFound},
+		{name: "HistoryNotSampled", method: "GET", path: "/v2/network/eth0/history", expectedCode: http.StatusNotFound},
+		{name: "HistoryInvalidStep", method: "GET", path: "/v2/network/eth0/history?step=-1s", expectedCode: http.StatusBadRequest},
+		{name: "InterfaceNeighbors", method: "GET", path: "/v2/network/eth0/neighbors", expectedCode: http.StatusOK},
+		{name: "InterfaceNeighborsNotFound", method: "GET", path: "/v2/network/eth9/neighbors", expectedCode: http.StatusNotFound},
+		{name: "InterfaceNeighborsNotImplemented", server: "sysfs", method: "GET", path: "/v2/network/eth0/neighbors", expectedCode: http.StatusNotImplemented},
+		{name: "Ethtool", method: "GET", path: "/v2/network/eth0/ethtool", expectedCode: http.StatusOK},
+		{name: "EthtoolNotFound", method: "GET", path: "/v2/network/eth9/ethtool", expectedCode: http.StatusNotFound},
+		{name: "EthtoolNotImplemented", server: "sysfs", method: "GET", path: "/v2/network/eth0/ethtool", expectedCode: http.StatusNotImplemented},
+		{name: "Events", method: "GET", path: "/v2/network/events", expectedCode: http.StatusOK},
+		{name: "EventsInvalidLastEventID", method: "GET", path: "/v2/network/events", lastEventID: "abc", expectedCode: http.StatusBadRequest},
+		{name: "WebSocketNoHandshake", method: "GET", path: "/v2/network/ws", expectedCode: http.StatusBadRequest},
+		{name: "Neighbors", method: "GET", path: "/v2/neighbors", expectedCode: http.StatusOK},
+		{name: "NeighborsNotImplemented", server: "sysfs", method: "GET", path: "/v2/neighbors", expectedCode: http.StatusNotImplemented},
+		{name: "Routes", method: "GET", path: "/v2/routes?table=main", expectedCode: http.StatusOK},
+		{name: "RoutesInvalidFamily", method: "GET", path: "/v2/routes?family=ipv5", expectedCode: http.StatusBadRequest},
+		{name: "RoutesNotImplemented", server: "sysfs", method: "GET", path: "/v2/routes", expectedCode: http.StatusNotImplemented},
+		{name: "RouteLookup", method: "GET", path: "/v2/routes/lookup?dst=192.168.1.20", expectedCode: http.StatusOK},
+		{name: "RouteLookupNoRoute", method: "GET", path: "/v2/routes/lookup?dst=192.0.2.1", expectedCode: http.StatusNotFound},
+		{name: "RouteLookupMissingDst", method: "GET", path: "/v2/routes/lookup", expectedCode: http.StatusBadRequest},
+		{name: "RouteLookupNotImplemented", server: "sysfs", method: "GET", path: "/v2/routes/lookup?dst=192.0.2.1", expectedCode: http.StatusNotImplemented},
+		{name: "Rules", method: "GET", path: "/v2/rules", expectedCode: http.StatusOK},
+		{name: "RulesNotImplemented", server: "sysfs", method: "GET", path: "/v2/rules", expectedCode: http.StatusNotImplemented},
+		{name: "Topology", method: "GET", path: "/v2/topology", expectedCode: http.StatusOK},
+		{name: "TopologyDOT", method: "GET", path: "/v2/topology?format=dot", expectedCode: http.StatusOK},
+		{name: "TopologyInvalidQuery", method: "GET", path: "/v2/topology?depth=1", expectedCode: http.StatusBadRequest},
+		{name: "Namespaces", method: "GET", path: "/v2/netns", expectedCode: http.StatusOK},
+		{name: "NamespacesNotImplemented", server: "sysfs", method: "GET", path: "/v2/netns", expectedCode: http.StatusNotImplemented},
+		{name: "NamespaceNetwork", method: "GET", path: "/v2/netns/blue/network", expectedCode: http.StatusOK},
+		{name: "NamespaceNotFound", method: "GET", path: "/v2/netns/red/network", expectedCode: http.StatusNotFound},
+		{name: "NamespacePIDDisabled", method: "GET", path: "/v2/netns/pid:42/network", expectedCode: http.StatusForbidden},
+		{name: "NamespaceNetworkNotImplemented", server: "sysfs", method: "GET", path: "/v2/netns/blue/network", expectedCode: http.StatusNotImplemented},
+		{name: "ListV1", method: "GET", path: "/v1/network", expectedCode: http.StatusOK},
+		{name: "ListV1NoMatch", method: "GET", path: "/v1/network?kind=bond", expectedCode: http.StatusOK},
+		{name: "ListV1NoSuchInterface", method: "GET", path: "/v1/network?interface=eth9", expectedCode: http.StatusNotFound},
+		{name: "InterfaceV1", method: "GET", path: "/v1/network/eth0", expectedCode: http.StatusOK},
+		{name: "InterfaceV1NotFound", method: "GET", path: "/v1/network/eth9", expectedCode: http.StatusNotFound},
+		{name: "NamespaceNetworkV1", method: "GET", path: "/v1/netns/blue/network", expectedCode: http.StatusOK},
+		{name: "EventsV1", method: "GET", path: "/v1/network/events", expectedCode: http.StatusOK},
+		{name: "WebSocketV1NoHandshake", method: "GET", path: "/v1/network/ws", expectedCode: http.StatusBadRequest},
+		{name: "StatsV1", method: "GET", path: "/v1/network/lo/stats", expectedCode: http.StatusOK},
+		{name: "HistoryV1NotSampled", method: "GET", path: "/v1/network/eth0/history", expectedCode: http.StatusNotFound},
+		{name: "InterfaceNeighborsV1", method: "GET", path: "/v1/network/eth0/neighbors", expectedCode: http.StatusOK},
+		{name: "EthtoolV1", method: "GET", path: "/v1/network/eth0/ethtool", expectedCode: http.StatusOK},
+		{name: "NeighborsV1", method: "GET", path: "/v1/neighbors", expectedCode: http.StatusOK},
+		{name: "RoutesV1", method: "GET", path: "/v1/routes", expectedCode: http.StatusOK},
+		{name: "RouteLookupV1", method: "GET", path: "/v1/routes/lookup?dst=192.168.1.20", expectedCode: http.StatusOK},
+		{name: "RulesV1", method: "GET", path: "/v1/rules", expectedCode: http.StatusOK},
+		{name: "TopologyV1", method: "GET", path: "/v1/topology", expectedCode: http.StatusOK},
+		{name: "NamespacesV1", method: "GET", path: "/v1/netns", expectedCode: http.StatusOK},
+		{name: "ListUnversioned", method: "GET", path: "/network", expectedCode: http.StatusOK},
+		{name: "ListUnversionedV1", method: "GET", path: "/network", accept: "application/vnd.interfacer.v1+json", expectedCode: http.StatusOK},
+		{name: "ListUnversionedV2", method: "GET", path: "/network", accept: "application/vnd.interfacer.v2+json", expectedCode: http.StatusOK},
+		{name: "ListUnversionedInvalidQuery", method: "GET", path: "/network?foo=bar", expectedCode: http.StatusBadRequest},
+		{name: "InterfaceUnversioned", method: "GET", path: "/network/eth0", expectedCode: http.StatusOK},
+		{name: "InterfaceUnversionedV2", method: "GET", path: "/network/wlan0", accept: "application/vnd.interfacer.v2+json", expectedCode: http.StatusOK},
+		{name: "InterfaceUnversionedNotFound", method: "GET", path: "/network/eth9", accept: "application/vnd.interfacer.v2+json", expectedCode: http.StatusNotFound},
+		{name: "NamespaceNetworkUnversioned", method: "GET", path: "/netns/blue/network", expectedCode: http.StatusOK},
+		{name: "NamespaceNetworkUnversionedV2", method: "GET", path: "/netns/blue/network", accept: "application/vnd.interfacer.v2+json", expectedCode: http.StatusOK},
+		{name: "EventsUnversioned", method: "GET", path: "/network/events", expectedCode: http.StatusOK},
+		{name: "WebSocketUnversionedNoHandshake", method: "GET", path: "/network/ws", expectedCode: http.StatusBadRequest},
+		{name: "StatsUnversioned", method: "GET", path: "/network/lo/stats", expectedCode: http.StatusOK},
+		{name: "HistoryUnversionedNotSampled", method: "GET", path: "/network/eth0/history", expectedCode: http.StatusNotFound},
+		{name: "InterfaceNeighborsUnversioned", method: "GET", path: "/network/eth0/neighbors", expectedCode: http.StatusOK},
+		{name: "EthtoolUnversioned", method: "GET", path: "/network/eth0/ethtool", expectedCode: http.StatusOK},
+		{name: "NeighborsUnversioned", method: "GET", path: "/neighbors", expectedCode: http.StatusOK},
+		{name: "RoutesUnversioned", method: "GET", path: "/routes", expectedCode: http.StatusOK},
+		{name: "RouteLookupUnversioned", method: "GET", path: "/routes/lookup?dst=192.168.1.20", expectedCode: http.StatusOK},
+		{name: "RulesUnversioned", method: "GET", path: "/rules", expectedCode: http.StatusOK},
+		{name: "TopologyUnversioned", method: "GET", path: "/topology", expectedCode: http.StatusOK},
+		{name: "NamespacesUnversioned", method: "GET", path: "/netns", expectedCode: http.StatusOK},
+		{name: "Metrics", method: "GET", path: "/metrics", expectedCode: http.StatusOK},
+		{name: "OpenAPI", method: "GET", path: "/openapi.json", expectedCode: http.StatusOK},
+		{name: "OpenAPINotAcceptable", method: "GET", path: "/openapi.json?format=svg", expectedCode: http.StatusNotAcceptable},
+		{name: "Docs", method: "GET", path: "/docs", expectedCode: http.StatusOK},
+
+		{name: "PatchDisabled", server: "disabled", method: "PATCH", path: "/v2/network/eth0", body: `{"mtu":1400}`, expectedCode: http.StatusForbidden},
+		{name: "PatchMissingToken", noToken: true, method: "PATCH", path: "/v2/network/eth0", body: `{"mtu":1400}`, expectedCode: http.StatusUnauthorized},
+		{name: "PatchNotImplemented", server: "sysfs", method: "PATCH", path: "/v2/network/eth0", body: `{"mtu":1400}`, expectedCode: http.StatusNotImplemented},
+		{name: "PatchEmpty", method: "PATCH", path: "/v2/network/eth0", body: `{}`, expectedCode: http.StatusBadRequest},
+		{name: "PatchRejected", method: "PATCH", path: "/v2/network/eth0", body: `{"mtu":65536}`, expectedCode: http.StatusUnprocessableEntity},
+		{name: "PatchNotFound", method: "PATCH", path: "/v2/network/eth9", body: `{"mtu":1400}`, expectedCode: http.StatusNotFound},
+		{name: "PatchDryRun", method: "PATCH", path: "/v2/network/eth0?dry_run=true", body: `{"mtu":1400}`, expectedCode: http.StatusOK},
+		{name: "Patch", method: "PATCH", path: "/v2/network/eth0", body: `{"mtu":1400,"alias":"uplink"}`, expectedCode: http.StatusOK},
+		{name: "PatchConfirmed", method: "PATCH", path: "/v2/network/eth0?confirm_timeout=60", body: `{"mtu":1300}`, expectedCode: http.StatusOK},
+		{name: "PatchPending", method: "PATCH", path: "/v2/network/eth0", body: `{"mtu":1200}`, expectedCode: http.StatusConflict},
+		{name: "Changes", method: "GET", path: "/v2/changes", expectedCode: http.StatusOK},
+		{name: "ChangesMissingToken", noToken: true, method: "GET", path: "/v2/changes", expectedCode: http.StatusUnauthorized},
+		{name: "ChangesDisabled", server: "disabled", method: "GET", path: "/v2/changes", expectedCode: http.StatusForbidden},
+		{name: "Confirm", method: "POST", path: "/v2/changes/{id}/confirm", expectedCode: http.StatusOK},
+		{name: "ConfirmNotFound", method: "POST", path: "/v2/changes/{id}/confirm", expectedCode: http.StatusNotFound},
+		{name: "PatchRolledBack", method: "PATCH", path: "/v2/network/eth0?confirm_timeout=60", body: `{"admin_status":"down"}`, expectedCode: http.StatusOK},
+		{name: "Rollback", method: "DELETE", path: "/v2/changes/{id}", expectedCode: http.StatusOK},
+		{name: "RollbackNotFound", method: "DELETE", path: "/v2/changes/{id}", expectedCode: http.StatusNotFound},
+		{name: "AddAddress", method: "POST", path: "/v2/network/eth0/addresses", body: `{"address":"192.0.2.20/24"}`, expectedCode: http.StatusCreated},
+		{name: "AddAddressDryRun", method: "POST", path: "/v2/network/eth0/addresses?dry_run=true", body: `{"address":"192.0.2.21/24"}`, expectedCode: http.StatusOK},
+		{name: "AddExistingAddress", method: "POST", path: "/v2/network/eth0/addresses", body: `{"address":"192.0.2.20/24"}`, expectedCode: http.StatusConflict},
+		{name: "AddInvalidAddress", method: "POST", path: "/v2/network/eth0/addresses", body: `{"address":"192.0.2.20"}`, expectedCode: http.StatusBadRequest},
+		{name: "DeleteAddress", method: "DELETE", path: "/v2/network/eth0/addresses/192.0.2.20", expectedCode: http.StatusOK},
+		{name: "DeleteUnassignedAddress", method: "DELETE", path: "/v2/network/eth0/addresses/192.0.2.20", expectedCode: http.StatusNotFound},
+		{name: "DeleteInvalidAddress", method: "DELETE", path: "/v2/network/eth0/addresses/eth0", expectedCode: http.StatusBadRequest},
+
+		{name: "DesiredStateNotSet", method: "GET", path: "/v2/desired-state", expectedCode: http.StatusNotFound},
+		{name: "PlanNotSet", method: "GET", path: "/v2/desired-state/plan", expectedCode: http.StatusNotFound},
+		{name: "ApplyNotSet", method: "POST", path: "/v2/desired-state/apply", expectedCode: http.StatusNotFound},
+		{name: "PutDesiredStateInvalid", method: "PUT", path: "/v2/desired-state", body: `{"interfaces":[{"name":"eth0","mtu":0}]}`, expectedCode: http.StatusBadRequest},
+		{name: "PutDesiredStateDisabled", server: "disabled", method: "PUT", path: "/v2/desired-state", body: `{"interfaces":[]}`, expectedCode: http.StatusForbidden},
+		{name: "PutDesiredState", method: "PUT", path: "/v2/desired-state", body: `{"interfaces":[{"name":"eth0","mtu":1500,"addresses":["192.0.2.30/24"]},{"name":"eth9"}]}`, expectedCode: http.StatusOK},
+		{name: "DesiredState", method: "GET", path: "/v2/desired-state", expectedCode: http.StatusOK},
+		{name: "Plan", method: "GET", path: "/v2/desired-state/plan", expectedCode: http.StatusOK},
+		{name: "ApplyInvalidQuery", method: "POST", path: "/v2/desired-state/apply?confirm_timeout=0", expectedCode: http.StatusBadRequest},
+		{name: "ApplyDryRun", method: "POST", path: "/v2/desired-state/apply?dry_run=true", expectedCode: http.StatusOK},
+		{name: "Apply", method: "POST", path: "/v2/desired-state/apply", expectedCode: http.StatusOK},
+		{name: "PlanInSync", method: "GET", path: "/v2/desired-state/plan", expectedCode: http.StatusOK},
+		{name: "PutDesiredRoutes", server: "sysfs", method: "PUT", path: "/v2/desired-state", body: `{"interfaces":[{"name":"eth0","routes":[]}]}`, expectedCode: http.StatusOK},
+		{name: "PlanRoutesNotImplemented", server: "sysfs", method: "GET", path: "/v2/desired-state/plan", expectedCode: http.StatusNotImplemented},
+		{name: "ApplyNotImplemented", server: "sysfs", method: "POST", path: "/v2/desired-state/apply", expectedCode: http.StatusNotImplemented},
+		{name: "DeleteDesiredStateDisabled", server: "disabled", method: "DELETE", path: "/v2/desired-state", expectedCode: http.StatusForbidden},
+		{name: "DeleteDesiredState", method: "DELETE", path: "/v2/desired-state", expectedCode: http.StatusNoContent},
+	}
+
+	exercised := map[string]bool{}
+	var pendingID string
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			srv := servers["full"]
+			if test.server != "" {
+				srv = servers[test.server]
+			}
+
+			// Cancel the request shortly after so that event streams terminate
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			req, err := http.NewRequestWithContext(ctx, test.method, strings.ReplaceAll(test.path, "{id}", pendingID), strings.NewReader(test.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !test.noToken {
+				req.Header.Set("Authorization", "Bearer secret")
+			}
+			if test.accept != "" {
+				req.Header.Set("Accept", test.accept)
+			}
+			if test.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", test.lastEventID)
+			}
+
+			rr := httptest.NewRecorder()
+			srv.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != test.expectedCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, test.expectedCode)
+			}
+
+			template, op := spec.operation(test.method, req.URL.Path)
+			if op == nil {
+				t.Fatalf("%s %s is not documented", test.method, req.URL.Path)
+			}
+			exercised[test.method+" "+template] = true
+
+			response, ok := op["responses"].(map[string]any)[fmt.Sprint(rr.Code)].(map[string]any)
+			if !ok {
+				t.Fatalf("status %d of %s %s is not documented", rr.Code, test.method, template)
+			}
+			response = spec.resolve(response)
+
+			headers, _ := response["headers"].(map[string]any)
+			for name, header := range headers {
+				if spec.resolve(header.(map[string]any))["required"] == true && rr.Header().Get(name) == "" {
+					t.Errorf("required header %s is missing", name)
+				}
+			}
+
+			content, _ := response["content"].(map[string]any)
+			if len(content) == 0 {
+				if rr.Body.Len() != 0 {
+					t.Errorf("unexpected response body: %s", rr.Body)
+				}
+				return
+			}
+
+			mediaType, _, _ := mime.ParseMediaType(rr.Header().Get("Content-Type"))
+			media, ok := content[mediaType].(map[string]any)
+			if !ok {
+				t.Fatalf("content type %q is not documented", mediaType)
+			}
+			if mediaType != "application/json" && !strings.HasSuffix(mediaType, "+json") {
+				return
+			}
+
+			decoder := json.NewDecoder(bytes.NewReader(rr.Body.Bytes()))
+			decoder.UseNumber()
+			var body any
+			if err := decoder.Decode(&body); err != nil {
+				t.Fatalf("failed to decode response body: %v", err)
+			}
+			if err := spec.validate(media["schema"].(map[string]any), body, "body"); err != nil {
+				t.Errorf("response doesn't match the schema: %v\n%s", err, rr.Body)
+			}
+
+			if obj, ok := body.(map[string]any); ok && obj["deadline"] != nil {
+				pendingID = obj["id"].(string)
+			}
+		})
+	}
+
+	var missing []string
+	for template, item := range spec["paths"].(map[string]any) {
+		for method := range item.(map[string]any) {
+			if key := strings.ToUpper(method) + " " + template; !exercised[key] {
+				missing = append(missing, key)
+			}
+		}
+	}
+	sort.Strings(missing)
+	if len(missing) != 0 {
+		t.Errorf("operations not exercised: %s", strings.Join(missing, ", "))
+	}
+
+	// Every route of the router is documented, including the v1 and unversioned aliases
+	var undocumented []string
+	for _, route := range server.NewServer(newCollector(), withToken).Routes() {
+		method, path, _ := strings.Cut(route, " ")
+		item, _ := spec["paths"].(map[string]any)[path].(map[string]any)
+		if _, ok := item[strings.ToLower(method)]; !ok {
+			undocumented = append(undocumented, route)
+		}
+	}
+	if len(undocumented) != 0 {
+		t.Errorf("routes not documented: %s", strings.Join(undocumented, ", "))
+	}
+}
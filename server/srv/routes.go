@@ -0,0 +1,209 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+
+	api "apimodule"
+	models "servermodule/servermodels"
+)
+
+// errInvalidRouteQuery is returned when the /routes endpoint receives unsupported query parameters.
+var errInvalidRouteQuery = errors.New("only ?table={table}, ?family={ipv4|ipv6}, ?interface={interface_name} and ?destination={address|prefix} input formats are allowed")
+
+// capability returns the collector of the server as the optional capability T.
+// If the collector doesn't provide it, it responds with 501 Not Implemented and reports false.
+func capability[T any](s *server, w http.ResponseWriter) (T, bool) {
+	c, ok := s.source.(T)
+	if !ok {
+		s.error(w, http.StatusNotImplemented, errors.New("this endpoint is not supported by the collector"))
+	}
+	return c, ok
+}
+
+// The routesHandler() method is the handler function for the /routes endpoint.
+// It returns the entries of all routing tables, optionally filtered by the table, family,
+// interface and destination query parameters. A destination prefix selects the routes for
+// exactly that prefix, a destination address every route whose prefix contains it.
+func (s *server) routesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		collector, ok := capability[models.RouteCollector](s, w)
+		if !ok {
+			return
+		}
+
+		filter, err := parseRouteFilter(r)
+		if err != nil {
+			s.error(w, http.StatusBadRequest, err)
+			return
+		}
+
+		routes, err := collector.Routes()
+		if err != nil {
+			s.error(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		response := api.Routes{Routes: []api.Route{}}
+		for _, route := range routes {
+			if filter.matches(route) {
+				response.Routes = append(response.Routes, route)
+			}
+		}
+
+		s.respond(w, http.StatusOK, response)
+	}
+}
+
+// The routeLookupHandler() method is the handler function for the /routes/lookup endpoint.
+// It returns the route the kernel selects for the destination address in the dst query parameter.
+func (s *server) routeLookupHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		collector, ok := capability[models.RouteCollector](s, w)
+		if !ok {
+			return
+		}
+
+		dst := net.ParseIP(r.URL.Query().Get("dst"))
+		if dst == nil {
+			s.error(w, http.StatusBadRequest, errors.New("only ?dst={address} input format is allowed"))
+			return
+		}
+
+		route, err := collector.LookupRoute(dst)
+		if errors.Is(err, models.ErrNoRoute) {
+			s.error(w, http.StatusNotFound, err)
+			return
+		}
+		if err != nil {
+			s.error(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		s.respond(w, http.StatusOK, api.RouteLookup{Destination: dst.String(), Route: *route})
+	}
+}
+
+// The rulesHandler() method is the handler function for the /rules endpoint.
+// It returns the policy routing rules in the order the kernel evaluates them.
+func (s *server) rulesHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		collector, ok := capability[models.RouteCollector](s, w)
+		if !ok {
+			return
+		}
+
+		rules, err := collector.Rules()
+		if err != nil {
+			s.error(w, http.StatusInternalServerError, err)
+			return
+		}
+		if rules == nil {
+			rules = []api.Rule{}
+		}
+
+		s.respond(w, http.StatusOK, api.Rules{Rules: rules})
+	}
+}
+
+// routeFilter holds the query parameters of a /routes request. Empty fields match every route.
+type routeFilter struct {
+	table     string     // Table name as reported in api.Route.
+	family    string     // api.FamilyIPv4 or api.FamilyIPv6.
+	iface     string     // Output interface of the route or one of its next hops.
+	prefix    *net.IPNet // Exact destination prefix.
+	contained net.IP     // Address the destination prefix has to contain.
+}
+
+// parseRouteFilter parses the query parameters of a /routes request.
+func parseRouteFilter(r *http.Request) (*routeFilter, error) {
+	queryParams := r.URL.Query()
+	filter := &routeFilter{}
+
+	for key, values := range queryParams {
+		if len(values) != 1 || values[0] == "" {
+			return nil, errInvalidRouteQuery
+		}
+		value := values[0]
+
+		switch key {
+		case "table":
+			filter.table = value
+			// Numeric IDs of the well-known tables are reported by name
+			if id, err := strconv.ParseUint(value, 10, 32); err == nil {
+				filter.table = models.TableName(uint32(id))
+			}
+		case "family":
+			if value != api.FamilyIPv4 && value != api.FamilyIPv6 {
+				return nil, fmt.Errorf("invalid family parameter: expected %s or %s", api.FamilyIPv4, api.FamilyIPv6)
+			}
+			filter.family = value
+		case "interface":
+			filter.iface = value
+		case "destination":
+			if _, prefix, err := net.ParseCIDR(value); err == nil {
+				filter.prefix = prefix
+			} else if ip := net.ParseIP(value); ip != nil {
+				filter.contained = ip
+			} else {
+				return nil, errors.New("invalid destination parameter: expected an address or a prefix in CIDR notation")
+			}
+		default:
+			return nil, errInvalidRouteQuery
+		}
+	}
+
+	return filter, nil
+}
+
+// matches reports whether the route is selected by the filter.
+func (f *routeFilter) matches(route api.Route) bool {
+	if f.table != "" && route.Table != f.table {
+		return false
+	}
+	if f.family != "" && route.Family != f.family {
+		return false
+	}
+	if f.iface != "" && !routeUsesInterface(route, f.iface) {
+		return false
+	}
+	if f.prefix != nil || f.contained != nil {
+		_, dst, err := net.ParseCIDR(route.Destination)
+		if err != nil {
+			return false
+		}
+		if f.prefix != nil && dst.String() != f.prefix.String() {
+			return false
+		}
+		// An IPv4 address is never contained in an IPv6 prefix, including ::/0
+		if f.contained != nil && (len(dst.IP) != len(ipForm(f.contained)) || !dst.Contains(f.contained)) {
+			return false
+		}
+	}
+	return true
+}
+
+// routeUsesInterface reports whether the route or one of its next hops leaves through the interface.
+func routeUsesInterface(route api.Route, name string) bool {
+	if route.Interface == name {
+		return true
+	}
+	for _, nexthop := range route.Nexthops {
+		if nexthop.Interface == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ipForm returns the 4-byte form of IPv4 addresses and the 16-byte form of IPv6 addresses,
+// matching the form net.ParseCIDR uses for the network address.
+func ipForm(ip net.IP) net.IP {
+	if ip4 := ip.To4(); ip4 != nil {
+		return ip4
+	}
+	return ip
+}
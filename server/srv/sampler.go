@@ -0,0 +1,223 @@
+package server
+
+import (
+	"context"
+	"log"
+	"slices"
+	"sync"
+	"time"
+
+	api "apimodule"
+	models "servermodule/servermodels"
+)
+
+// The history struct is a fixed-size ring buffer of snapshots of a single interface.
+// Once full, every new snapshot overwrites the oldest one.
+type history struct {
+	snapshots []api.InterfaceSnapshot
+	next      int  // Position the next snapshot is written to.
+	full      bool // Whether the buffer has wrapped around at least once.
+}
+
+// newHistory creates an empty history holding up to size snapshots.
+func newHistory(size int) *history {
+	return &history{
+		snapshots: make([]api.InterfaceSnapshot, size),
+	}
+}
+
+// add appends a snapshot, overwriting the oldest one if the buffer is full.
+func (h *history) add(snapshot api.InterfaceSnapshot) {
+	h.snapshots[h.next] = snapshot
+	h.next = (h.next + 1) % len(h.snapshots)
+	if h.next == 0 {
+		h.full = true
+	}
+}
+
+// latest returns the most recent snapshot, if any.
+func (h *history) latest() (api.InterfaceSnapshot, bool) {
+	if !h.full && h.next == 0 {
+		return api.InterfaceSnapshot{}, false
+	}
+	return h.snapshots[(h.next-1+len(h.snapshots))%len(h.snapshots)], true
+}
+
+// between returns the snapshots taken in the closed interval [since, until] in chronological order.
+// With a positive step, consecutive returned snapshots are at least step apart.
+func (h *history) between(since, until time.Time, step time.Duration) []api.InterfaceSnapshot {
+	start, count := 0, h.next
+	if h.full {
+		start, count = h.next, len(h.snapshots)
+	}
+
+	result := []api.InterfaceSnapshot{}
+	var last time.Time
+	for i := 0; i < count; i++ {
+		snapshot := h.snapshots[(start+i)%len(h.snapshots)]
+		if snapshot.Timestamp.Before(since) || snapshot.Timestamp.After(until) {
+			continue
+		}
+		if step > 0 && len(result) > 0 && snapshot.Timestamp.Sub(last) < step {
+			continue
+		}
+		result = append(result, snapshot)
+		last = snapshot.Timestamp
+	}
+
+	return result
+}
+
+// The sampler struct polls a collector in the background, keeps a bounded history
+// of snapshots for every interface it has seen and publishes the changes between
+// consecutive samples as events.
+type sampler struct {
+	collector models.Collector
+	interval  time.Duration    // Time between two samples.
+	size      int              // Number of snapshots kept per interface.
+	stats     *statsTracker    // Derives counter rates between consecutive samples.
+	events    *eventBroker     // Receives the changes detected between samples.
+	now       func() time.Time // Clock used to timestamp snapshots, replaceable in tests.
+
+	mu        sync.RWMutex
+	histories map[string]*history
+	present   map[string]bool // Interfaces seen in the latest sample, nil before the first one.
+}
+
+// newSampler creates a new sampler polling the collector at the given interval,
+// keeping up to size snapshots per interface and publishing changes to the broker.
+func newSampler(collector models.Collector, interval time.Duration, size int, events *eventBroker) *sampler {
+	return &sampler{
+		collector: collector,
+		interval:  interval,
+		size:      size,
+		stats:     newStatsTracker(0),
+		events:    events,
+		now:       time.Now,
+		histories: make(map[string]*history),
+	}
+}
+
+// run takes a sample immediately and then at every interval until the context is cancelled.
+func (s *sampler) run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.sample(); err != nil {
+			log.Printf("Sampler error: %s", err.Error())
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sample polls the collector once, records a snapshot of every interface and publishes
+// the changes since the previous sample. The first sample only sets the baseline.
+// Histories of interfaces that disappeared are dropped once all their snapshots are
+// older than the retention window, so churning interfaces don't grow memory unbounded.
+func (s *sampler) sample() error {
+	interfaces, err := models.CollectInterfaces(s.collector, models.DetailAddresses|models.DetailStats)
+	if err != nil {
+		return err
+	}
+
+	now := s.now()
+	var events []api.Event
+
+	s.mu.Lock()
+	defer func() {
+		s.mu.Unlock()
+		s.events.publish(events)
+	}()
+
+	present := make(map[string]bool, len(interfaces))
+	for _, iface := range interfaces {
+		present[iface.Name] = true
+		if iface.Stats != nil {
+			s.stats.observeAt(iface.Name, iface.Stats, now)
+		}
+
+		h, ok := s.histories[iface.Name]
+		if !ok {
+			h = newHistory(s.size)
+			s.histories[iface.Name] = h
+		}
+
+		v1 := iface.V1()
+		snapshot := api.InterfaceSnapshot{
+			Timestamp:         now,
+			IPAddresses:       v1.IPAddresses,
+			MTU:               v1.MTU,
+			AdminStatus:       v1.AdminStatus,
+			OperationalStatus: v1.OperationalStatus,
+			Stats:             v1.Stats,
+		}
+
+		if s.present != nil {
+			if prev, ok := h.latest(); ok && s.present[iface.Name] {
+				events = append(events, diffSnapshots(iface.Name, prev, snapshot)...)
+			} else {
+				events = append(events, api.Event{Type: api.EventInterfaceCreated, Interface: iface.Name, Timestamp: now})
+			}
+		}
+
+		h.add(snapshot)
+	}
+
+	var deleted []string
+	for name := range s.present {
+		if !present[name] {
+			deleted = append(deleted, name)
+		}
+	}
+	slices.Sort(deleted)
+	s.stats.forget(deleted...)
+	for _, name := range deleted {
+		events = append(events, api.Event{Type: api.EventInterfaceDeleted, Interface: name, Timestamp: now})
+	}
+	s.present = present
+
+	retention := time.Duration(s.size) * s.interval
+	for name, h := range s.histories {
+		if latest, ok := h.latest(); ok && now.Sub(latest.Timestamp) > retention {
+			delete(s.histories, name)
+		}
+	}
+
+	return nil
+}
+
+// history returns the snapshots of the named interface taken between since and until,
+// thinned out to at most one snapshot per step. It reports false if the interface
+// has never been sampled.
+func (s *sampler) history(name string, since, until time.Time, step time.Duration) ([]api.InterfaceSnapshot, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	h, ok := s.histories[name]
+	if !ok {
+		return nil, false
+	}
+
+	return h.between(since, until, step), true
+}
+
+// latest returns the most recent snapshot of every interface present in the latest sample.
+func (s *sampler) latest() map[string]api.InterfaceSnapshot {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshots := make(map[string]api.InterfaceSnapshot, len(s.present))
+	for name := range s.present {
+		if snapshot, ok := s.histories[name].latest(); ok {
+			snapshots[name] = snapshot
+		}
+	}
+
+	return snapshots
+}
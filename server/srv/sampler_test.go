@@ -0,0 +1,157 @@
+package server
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	api "apimodule"
+	models "servermodule/servermodels"
+)
+
+// TestHistory tests that the ring buffer keeps the newest snapshots and filters them by time.
+func TestHistory(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	h := newHistory(3)
+
+	for i := 0; i < 5; i++ {
+		h.add(api.InterfaceSnapshot{Timestamp: base.Add(time.Duration(i) * time.Second), MTU: i})
+	}
+
+	tests := []struct {
+		name        string
+		since       time.Time
+		until       time.Time
+		step        time.Duration
+		expectedMTU []int
+	}{
+		{name: "All", since: base, until: base.Add(time.Hour), expectedMTU: []int{2, 3, 4}},
+		{name: "Since", since: base.Add(3 * time.Second), until: base.Add(time.Hour), expectedMTU: []int{3, 4}},
+		{name: "Until", since: base, until: base.Add(3 * time.Second), expectedMTU: []int{2, 3}},
+		{name: "Step", since: base, until: base.Add(time.Hour), step: 2 * time.Second, expectedMTU: []int{2, 4}},
+		{name: "Empty", since: base.Add(time.Hour), until: base.Add(2 * time.Hour), expectedMTU: []int{}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			snapshots := h.between(test.since, test.until, test.step)
+			if len(snapshots) != len(test.expectedMTU) {
+				t.Fatalf("unexpected number of snapshots: got %d, want %d", len(snapshots), len(test.expectedMTU))
+			}
+			for i, snapshot := range snapshots {
+				if snapshot.MTU != test.expectedMTU[i] {
+					t.Errorf("snapshot %d mismatch: got MTU %d, want %d", i, snapshot.MTU, test.expectedMTU[i])
+				}
+			}
+		})
+	}
+}
+
+// TestSampler tests that the sampler records snapshots with counter rates.
+func TestSampler(t *testing.T) {
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := base
+
+	s := newSampler(models.NewSysfsCollector("../servermodels/testdata"), time.Second, 10, newEventBroker(10))
+	s.now = func() time.Time { return now }
+
+	for i := 0; i < 2; i++ {
+		if err := s.sample(); err != nil {
+			t.Fatal(err)
+		}
+		now = now.Add(time.Second)
+	}
+
+	snapshots, ok := s.history("eth0", base, now, 0)
+	if !ok {
+		t.Fatal("expected history for eth0")
+	}
+	if len(snapshots) != 2 {
+		t.Fatalf("unexpected number of snapshots: got %d, want 2", len(snapshots))
+	}
+	if snapshots[0].Stats.Rates != nil {
+		t.Errorf("expected no rates for the first snapshot, got %+v", snapshots[0].Stats.Rates)
+	}
+	if rates := snapshots[1].Stats.Rates; rates == nil || rates.Interval != 1 {
+		t.Errorf("expected rates over one second for the second snapshot, got %+v", rates)
+	}
+
+	if _, ok := s.history("eth1", base, now, 0); ok {
+		t.Error("expected no history for unknown interface")
+	}
+}
+
+// fakeCollector is a Collector returning a fixed, mutable list of interfaces.
+type fakeCollector struct {
+	interfaces []api.NetworkInterfaceV2
+}
+
+// Interfaces returns the configured interfaces.
+func (c *fakeCollector) Interfaces() ([]api.NetworkInterfaceV2, error) {
+	return append([]api.NetworkInterfaceV2(nil), c.interfaces...), nil
+}
+
+// Interface returns the configured interface with the given name.
+func (c *fakeCollector) Interface(name string) (*api.NetworkInterfaceV2, error) {
+	for _, iface := range c.interfaces {
+		if iface.Name == name {
+			return &iface, nil
+		}
+	}
+	return nil, models.ErrNoSuchInterface
+}
+
+// TestSamplerEvents tests that the sampler publishes the changes between consecutive samples.
+func TestSamplerEvents(t *testing.T) {
+	collector := &fakeCollector{interfaces: []api.NetworkInterfaceV2{
+		{Name: "eth0", Addresses: []api.Address{{Address: "10.0.0.1"}}, MTU: 1500, OperStatus: api.OperStatusUp},
+		{Name: "eth1", MTU: 1500, OperStatus: api.OperStatusDown},
+	}}
+
+	broker := newEventBroker(100)
+	s := newSampler(collector, time.Second, 10, broker)
+
+	// The first sample only sets the baseline
+	if err := s.sample(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, events, cancel := broker.subscribe(0)
+	defer cancel()
+
+	collector.interfaces = []api.NetworkInterfaceV2{
+		{Name: "eth0", Addresses: []api.Address{{Address: "10.0.0.2"}}, MTU: 9000, OperStatus: api.OperStatusDown},
+		{Name: "wlan0", MTU: 1500, OperStatus: api.OperStatusUp},
+	}
+	if err := s.sample(); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []api.Event{
+		{ID: 1, Type: api.EventLinkDown, Interface: "eth0"},
+		{ID: 2, Type: api.EventMTUChanged, Interface: "eth0", OldMTU: 1500, NewMTU: 9000},
+		{ID: 3, Type: api.EventAddressAdded, Interface: "eth0", Address: "10.0.0.2"},
+		{ID: 4, Type: api.EventAddressRemoved, Interface: "eth0", Address: "10.0.0.1"},
+		{ID: 5, Type: api.EventInterfaceCreated, Interface: "wlan0"},
+		{ID: 6, Type: api.EventInterfaceDeleted, Interface: "eth1"},
+	}
+
+	for _, want := range expected {
+		select {
+		case got := <-events:
+			got.Timestamp = time.Time{}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("event mismatch: got %+v, want %+v", got, want)
+			}
+		default:
+			t.Fatalf("missing event %+v", want)
+		}
+	}
+
+	// Resuming after the second event returns the remaining backlog
+	missed, _, cancelResume := broker.subscribe(2)
+	defer cancelResume()
+	if len(missed) != 4 || missed[0].ID != 3 {
+		t.Errorf("unexpected backlog on resume: %+v", missed)
+	}
+}
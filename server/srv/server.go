@@ -3,97 +3,559 @@ package server
 import (
 	"encoding/json"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
+	"slices"
+	"strconv"
+	"time"
 
+	api "apimodule"
+	"servermodule/codec"
+	"servermodule/metrics"
 	router "servermodule/pkg"
 	models "servermodule/servermodels"
 )
 
 // The server struct represents the HTTP server instance.
-// It holds a reference to a router, which handles incoming HTTP requests.
+// It holds a reference to a router, which handles incoming HTTP requests,
+// and to the collector, which provides the network interface details.
+// The source is the collector as passed to NewServer, which optional capabilities
+// such as models.RouteCollector are detected on.
 type server struct {
-	router *router.Router
+	router    *router.Router
+	collector models.Collector
+	source    models.Collector
+	stats     *statsTracker
+	sampler   *sampler
+	events    *eventBroker
+	metrics   *metrics.Registry
+	changes   *pendingChanges
+	desired   *desiredStateStore
+
+	v1Deprecation time.Time // Announced in the Deprecation header of v1 responses.
+	v1Sunset      time.Time // Announced in the Sunset header of v1 responses.
+	netnsPIDs     bool      // Whether network namespaces are also listed and served by PID.
+	writeToken    string    // Bearer token of write operations, which are disabled if empty.
 }
 
-// newServer creates a new server instance with a configured router.
-func NewServer() *server {
+// NewServer creates a new server instance with a configured router,
+// serving the network interface details provided by the given collector.
+// The background sampler is configured but only started by Start.
+func NewServer(collector models.Collector, config *Config) *server {
+	registry := metrics.NewRegistry()
+	instrumented := &instrumentedCollector{Collector: collector, registry: registry}
+
+	events := newEventBroker(config.EventBacklog)
 	s := &server{
-		router: router.New(),
+		router:    router.New(),
+		collector: instrumented,
+		source:    collector,
+		stats:     newStatsTracker(minRateInterval),
+		sampler:   newSampler(instrumented, config.SampleInterval, config.HistorySize, events),
+		events:    events,
+		metrics:   registry,
+		changes:   newPendingChanges(),
+		desired:   newDesiredStateStore(config.DesiredStateFile),
+
+		v1Deprecation: config.V1Deprecation,
+		v1Sunset:      config.V1Sunset,
+		netnsPIDs:     config.NetnsPIDs,
+		writeToken:    config.WriteToken,
 	}
+	s.router.Observe(registry.ObserveRequest)
 	s.configureRouter()
 
 	return s
 }
 
 // The configureRouter() method configures the router with the necessary route handlers.
-// Currently, it only sets up a handler for the /network endpoint using the GET method.
+// It sets up the /v1 and /v2 route trees with handlers for the /network, /network/{name},
+// /network/events, /network/ws, /network/{name}/stats, /network/{name}/history,
+// /network/{name}/neighbors, /network/{name}/ethtool, /neighbors, /routes, /routes/lookup,
+// /rules, /topology, /netns and /netns/{ns}/network endpoints using the GET method, and the
+// unversioned /metrics, /openapi.json and /docs endpoints. The /v2 route tree additionally
+// accepts the write operations PATCH /network/{name}, POST /network/{name}/addresses and
+// DELETE /network/{name}/addresses/{address}, the /changes endpoints to list, confirm and roll
+// back the changes of commit-confirmed writes, and the /desired-state, /desired-state/plan and
+// /desired-state/apply endpoints.
+// The legacy unversioned routes are aliases of the v1 routes, except for /network, /network/{name}
+// and /netns/{ns}/network, which negotiate the version with the client.
 func (s *server) configureRouter() {
-	s.router.GET("/network", s.requestHandler())
+	s.router.GET("/metrics", s.metricsHandler())
+	s.router.GET("/openapi.json", s.openapiHandler())
+	s.router.GET("/docs", s.docsHandler())
+
+	v1 := s.router.Group("/v1", s.deprecated)
+	v1.GET("/network", s.requestHandler())
+	v1.GET("/network/{name}", s.interfaceHandler())
+	v1.GET("/netns/{ns}/network", s.requestHandler())
+	s.configureNetworkRoutes(v1)
+
+	v2 := s.router.Group("/v2")
+	v2.GET("/network", s.requestHandlerV2())
+	v2.GET("/network/{name}", s.interfaceHandlerV2())
+	v2.GET("/netns/{ns}/network", s.requestHandlerV2())
+	v2.PATCH("/network/{name}", s.writeAccess(s.patchInterfaceHandler()))
+	v2.POST("/network/{name}/addresses", s.writeAccess(s.addAddressHandler()))
+	v2.DELETE("/network/{name}/addresses/{address}", s.writeAccess(s.deleteAddressHandler()))
+	v2.GET("/changes", s.writeAccess(s.changesHandler()))
+	v2.POST("/changes/{id}/confirm", s.writeAccess(s.confirmChangeHandler()))
+	v2.DELETE("/changes/{id}", s.writeAccess(s.rollbackChangeHandler()))
+	v2.GET("/desired-state", s.getDesiredStateHandler())
+	v2.PUT("/desired-state", s.writeAccess(s.putDesiredStateHandler()))
+	v2.DELETE("/desired-state", s.writeAccess(s.deleteDesiredStateHandler()))
+	v2.GET("/desired-state/plan", s.planHandler())
+	v2.POST("/desired-state/apply", s.writeAccess(s.applyHandler()))
+	s.configureNetworkRoutes(v2)
+
+	legacy := s.router.Group("", s.deprecated)
+	s.router.GET("/network", s.negotiated(s.requestHandler(), s.requestHandlerV2()))
+	s.router.GET("/network/{name}", s.negotiated(s.interfaceHandler(), s.interfaceHandlerV2()))
+	s.router.GET("/netns/{ns}/network", s.negotiated(s.requestHandler(), s.requestHandlerV2()))
+	s.configureNetworkRoutes(legacy)
+}
+
+// The configureNetworkRoutes() method sets up the routes that are the same in every API version.
+func (s *server) configureNetworkRoutes(g *router.Group) {
+	g.GET("/network/events", s.eventsHandler())
+	g.GET("/network/ws", s.websocketHandler())
+	g.GET("/network/{name}/stats", s.statsHandler())
+	g.GET("/network/{name}/history", s.historyHandler())
+	g.GET("/network/{name}/neighbors", s.neighborsHandler())
+	g.GET("/network/{name}/ethtool", s.ethtoolHandler())
+	g.GET("/neighbors", s.neighborsHandler())
+	g.GET("/routes", s.routesHandler())
+	g.GET("/routes/lookup", s.routeLookupHandler())
+	g.GET("/rules", s.rulesHandler())
+	g.GET("/topology", s.topologyHandler())
+	g.GET("/netns", s.namespacesHandler())
 }
 
-// The requestHandler() method is the handler function for the /network endpoint.
-// It retrieves interface details based on query parameters.
+// errInvalidQuery is returned when the /network endpoint receives unsupported query parameters.
+var errInvalidQuery = errors.New("only ?interface={interface_name}, ?stats={true|false}, ?fields={field,...} and the filters ?name={glob}, ?kind={kind}, " +
+	"?oper_status={status}, ?admin_status={status}, ?has_ipv4={true|false}, ?has_ipv6={true|false}, ?in_cidr={prefix} " +
+	"and ?mac_prefix={octets} input formats are allowed")
+
+// The requestHandler() method is the handler function for the /network and /netns/{ns}/network
+// endpoints. It retrieves interface details based on query parameters.
 // This function is returned as an http.HandlerFunc.
 func (s *server) requestHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		interfaces, fields, ok := s.queryInterfaces(w, r, v1Model)
+		if !ok {
+			return
+		}
+
+		var response api.NetworkInterfaces
+		for _, iface := range interfaces {
+			response.Interfaces = append(response.Interfaces, iface.V1())
+		}
+		if fields == nil {
+			s.respond(w, http.StatusOK, response)
+			return
+		}
+
+		projections, err := projectAll(v1Model, response.Interfaces, fields)
+		if err != nil {
+			s.error(w, http.StatusInternalServerError, err)
+			return
+		}
+		s.respond(w, http.StatusOK, map[string][]projection{"network_interface": projections})
+	}
+}
+
+// The requestHandlerV2() method is the handler function for the /v2/network and
+// /v2/netns/{ns}/network endpoints.
+// It accepts the same query parameters as the /network endpoint, but responds with
+// the typed v2 model of the interfaces.
+func (s *server) requestHandlerV2() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		interfaces, fields, ok := s.queryInterfaces(w, r, v2Model)
+		if !ok {
+			return
+		}
+		if fields == nil {
+			s.respond(w, http.StatusOK, api.NetworkInterfacesV2{Interfaces: interfaces})
+			return
+		}
+
+		projections, err := projectAll(v2Model, interfaces, fields)
+		if err != nil {
+			s.error(w, http.StatusInternalServerError, err)
+			return
+		}
+		s.respond(w, http.StatusOK, map[string][]projection{"network_interfaces": projections})
+	}
+}
+
+// The interfaceHandler() method is the handler function for the /network/{name} endpoint.
+// It returns the details of a single interface in the v1 model, reduced to the fields
+// selected by the ?fields= parameter.
+func (s *server) interfaceHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.respondInterface(w, r, v1Model, func(iface *api.NetworkInterfaceV2) any { return iface.V1() })
+	}
+}
+
+// The interfaceHandlerV2() method is the handler function for the /v2/network/{name} endpoint.
+// It returns the details of a single interface in the v2 model, including its kind-specific
+// details and its master and slaves, reduced to the fields selected by the ?fields= parameter.
+func (s *server) interfaceHandlerV2() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		s.respondInterface(w, r, v2Model, func(iface *api.NetworkInterfaceV2) any { return iface })
+	}
+}
+
+// The respondInterface() method responds with the interface named in the path of the request,
+// converted to the model and reduced to the fields selected by the ?fields= parameter.
+// Only the details of the selected fields are collected.
+func (s *server) respondInterface(w http.ResponseWriter, r *http.Request, m *interfaceModel, convert func(*api.NetworkInterfaceV2) any) {
+	fields, ok := s.requestFields(w, r, m)
+	if !ok {
+		return
+	}
+
+	iface, ok := s.pathInterface(w, r, m.details(fields))
+	if !ok {
+		return
+	}
+	if fields == nil {
+		s.respond(w, http.StatusOK, convert(iface))
+		return
+	}
+
+	p, err := m.project(convert(iface), fields)
+	if err != nil {
+		s.error(w, http.StatusInternalServerError, err)
+		return
+	}
+	s.respond(w, http.StatusOK, p)
+}
+
+// The pathInterface() method retrieves the interface named in the path of the request with the
+// selected details, without its traffic counters. It responds with an error and reports false
+// if it can't be retrieved.
+func (s *server) pathInterface(w http.ResponseWriter, r *http.Request, details models.Details) (*api.NetworkInterfaceV2, bool) {
+	iface, err := models.CollectInterface(s.collector, r.PathValue("name"), details&^models.DetailStats)
+	if errors.Is(err, models.ErrNoSuchInterface) {
+		s.error(w, http.StatusNotFound, err)
+		return nil, false
+	}
+	if err != nil {
+		s.error(w, http.StatusInternalServerError, err)
+		return nil, false
+	}
+
+	iface.Stats = nil
+	return iface, true
+}
+
+// The queryInterfaces() method retrieves the interfaces selected by the ?interface= and
+// ?stats= query parameters and the filter parameters of a /network request, from the network
+// namespace in the path if there is one, along with the fields of the model selected by the
+// ?fields= parameter. Only the details needed for the selected fields and the filters are
+// collected. It responds with an error and reports false if the parameters are invalid or
+// the interfaces can't be retrieved.
+func (s *server) queryInterfaces(w http.ResponseWriter, r *http.Request, m *interfaceModel) ([]api.NetworkInterfaceV2, fieldSet, bool) {
+	queryParams := r.URL.Query()
+
+	// Check that the "interface", "stats" and "fields" query parameters are provided at most
+	// once and that every other parameter is a filter, which may be repeated
+	var filter interfaceFilter
+	for key, values := range queryParams {
+		if slices.Contains(values, "") {
+			s.error(w, http.StatusBadRequest, errInvalidQuery)
+			return nil, nil, false
+		}
+		if key == "interface" || key == "stats" || key == "fields" {
+			if len(values) != 1 {
+				s.error(w, http.StatusBadRequest, errInvalidQuery)
+				return nil, nil, false
+			}
+			continue
+		}
+		parse, ok := m.filters[key]
+		if !ok {
+			s.error(w, http.StatusBadRequest, errInvalidQuery)
+			return nil, nil, false
+		}
+		if err := filter.add(key, parse, values); err != nil {
+			s.error(w, http.StatusBadRequest, err)
+			return nil, nil, false
+		}
+	}
+
+	withStats := false
+	if statsParam := queryParams.Get("stats"); statsParam != "" {
+		var err error
+		if withStats, err = strconv.ParseBool(statsParam); err != nil {
+			s.error(w, http.StatusBadRequest, errInvalidQuery)
+			return nil, nil, false
+		}
+	}
+
+	fields, ok := s.requestFields(w, r, m)
+	if !ok {
+		return nil, nil, false
+	}
+	details := m.details(fields) | filter.details
+	if !withStats {
+		details &^= models.DetailStats
+	}
+
+	collector, ok := s.requestCollector(w, r)
+	if !ok {
+		return nil, nil, false
+	}
+
+	var interfaces []api.NetworkInterfaceV2
+
+	if interfaceParam := queryParams.Get("interface"); interfaceParam != "" {
+		// Retrieve details of the specified interface
+		interfaceDetails, err := models.CollectInterface(collector, interfaceParam, details)
+		if errors.Is(err, models.ErrNoSuchInterface) {
+			s.error(w, http.StatusNotFound, err)
+			return nil, nil, false
+		}
+		if err != nil {
+			s.error(w, http.StatusInternalServerError, err)
+			return nil, nil, false
+		}
+		interfaces = append(interfaces, *interfaceDetails)
+	} else {
+		// Retrieve details of all network interfaces
+		var err error
+		if interfaces, err = models.CollectInterfaces(collector, details); err != nil {
+			s.error(w, http.StatusInternalServerError, err)
+			return nil, nil, false
+		}
+	}
+
+	interfaces = filter.apply(interfaces)
+
+	// The stats block is only part of the response when asked for. Rates of interfaces in
+	// other namespaces are tracked separately, their names may clash with the server's own.
+	for i := range interfaces {
+		iface := &interfaces[i]
+		if withStats && iface.Stats != nil {
+			key := iface.Name
+			if ns := r.PathValue("ns"); ns != "" {
+				key = ns + "/" + iface.Name
+			}
+			s.stats.observe(key, iface.Stats)
+		} else {
+			iface.Stats = nil
+		}
+	}
+
+	return interfaces, fields, true
+}
+
+// The statsHandler() method is the handler function for the /network/{name}/stats endpoint.
+// It returns the traffic counters of a single interface along with the per-second rates
+// since the previous sample of that interface.
+func (s *server) statsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		iface, err := models.CollectInterface(s.collector, r.PathValue("name"), models.DetailStats)
+		if errors.Is(err, models.ErrNoSuchInterface) {
+			s.error(w, http.StatusNotFound, err)
+			return
+		}
+		if err != nil {
+			s.error(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if iface.Stats == nil {
+			s.error(w, http.StatusNotFound, errors.New("there are no statistics for this interface"))
+			return
+		}
+
+		s.stats.observe(iface.Name, iface.Stats)
+		s.respond(w, http.StatusOK, api.NetworkInterfaceStats{Name: iface.Name, Stats: *iface.Stats})
+	}
+}
+
+// The historyHandler() method is the handler function for the /network/{name}/history endpoint.
+// It returns the snapshots the background sampler recorded for a single interface.
+// The optional since and until query parameters limit the time range and accept either an
+// RFC 3339 timestamp or a duration into the past (e.g. 5m), step thins out the series.
+func (s *server) historyHandler() http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		queryParams := r.URL.Query()
-		interfaceParam := queryParams.Get("interface")
-
-		// Check if only "interface" query parameter is provided
-		if len(queryParams) == 1 && interfaceParam != "" {
-			// Retrieve details of the specified interface
-			interfaceDetails, err := models.GetInterfaceByName(interfaceParam)
-			if err != nil {
-				s.error(w, http.StatusNotFound, err)
+		now := time.Now()
+
+		since, err := parseTimeParam(queryParams.Get("since"), now, time.Time{})
+		if err != nil {
+			s.error(w, http.StatusBadRequest, fmt.Errorf("invalid since parameter: %w", err))
+			return
+		}
+
+		until, err := parseTimeParam(queryParams.Get("until"), now, now)
+		if err != nil {
+			s.error(w, http.StatusBadRequest, fmt.Errorf("invalid until parameter: %w", err))
+			return
+		}
+
+		var step time.Duration
+		if stepParam := queryParams.Get("step"); stepParam != "" {
+			if step, err = time.ParseDuration(stepParam); err != nil || step < 0 {
+				s.error(w, http.StatusBadRequest, errors.New("invalid step parameter: expected a positive duration"))
 				return
 			}
+		}
 
-			var test models.NetworkInterfaces
-			test.Interfaces = append(test.Interfaces, *interfaceDetails)
+		name := r.PathValue("name")
+		snapshots, ok := s.sampler.history(name, since, until, step)
+		if !ok {
+			s.error(w, http.StatusNotFound, errors.New("there is no history for this interface"))
+			return
+		}
+
+		s.respond(w, http.StatusOK, api.InterfaceHistory{Name: name, Snapshots: snapshots})
+	}
+}
 
-			s.respond(w, http.StatusOK, test)
+// The eventsHandler() method is the handler function for the /network/events endpoint.
+// It streams interface change events as Server-Sent Events until the client disconnects.
+// Clients reconnecting with a Last-Event-ID header first receive the events they missed,
+// as far as they are still in the backlog.
+func (s *server) eventsHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !ok {
+			s.error(w, http.StatusInternalServerError, errors.New("streaming is not supported"))
 			return
 		}
 
-		// Check if no query parameter is provided
-		if len(queryParams) == 0 {
-			// Retrieve details of all network interfaces
-			interfaces, err := models.GetInterfaces()
-			if err != nil {
-				s.error(w, http.StatusInternalServerError, err)
+		var lastID uint64
+		if header := r.Header.Get("Last-Event-ID"); header != "" {
+			var err error
+			if lastID, err = strconv.ParseUint(header, 10, 64); err != nil {
+				s.error(w, http.StatusBadRequest, errors.New("invalid Last-Event-ID header"))
 				return
 			}
-			var test models.NetworkInterfaces
-			test.Interfaces = interfaces
+		}
 
-			s.respond(w, http.StatusOK, test)
-			return
+		missed, events, cancel := s.events.subscribe(lastID)
+		defer cancel()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("Connection", "keep-alive")
+		w.WriteHeader(http.StatusOK)
+
+		for _, event := range missed {
+			writeEvent(w, event)
+		}
+		flusher.Flush()
+
+		// Comments keep idle connections from being closed by proxies
+		keepAlive := time.NewTicker(15 * time.Second)
+		defer keepAlive.Stop()
+
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event, ok := <-events:
+				if !ok {
+					// Dropped for being too slow, the client has to reconnect and resume
+					return
+				}
+				writeEvent(w, event)
+				flusher.Flush()
+			case <-keepAlive.C:
+				fmt.Fprint(w, ": keep-alive\n\n")
+				flusher.Flush()
+			}
 		}
+	}
+}
 
-		// If any other query parameter is provided, return an error
-		s.error(w, http.StatusBadRequest, errors.New("only ?interface={interface_name} input format is allowed"))
+// writeEvent writes a single event in the Server-Sent Events format.
+func writeEvent(w http.ResponseWriter, event api.Event) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		log.Printf("Failed to encode event: %s", err.Error())
+		return
 	}
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
 }
 
-// ServeHTTP handles incoming HTTP requests by delegating them to the router.
+// parseTimeParam parses a query parameter holding either an RFC 3339 timestamp or a duration
+// before now. An empty parameter yields the given default.
+func parseTimeParam(param string, now, def time.Time) (time.Time, error) {
+	if param == "" {
+		return def, nil
+	}
+
+	if d, err := time.ParseDuration(param); err == nil {
+		return now.Add(-d.Abs()), nil
+	}
+
+	t, err := time.Parse(time.RFC3339, param)
+	if err != nil {
+		return time.Time{}, errors.New("expected an RFC 3339 timestamp or a duration")
+	}
+
+	return t, nil
+}
+
+// ServeHTTP handles incoming HTTP requests by delegating them to the router,
+// along with the response format negotiated for them.
 func (s *server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	s.router.ServeHTTP(w, r)
+	w.Header().Add("Vary", "Accept")
+	s.router.ServeHTTP(withEncoding(w, r))
 }
 
-// The error() method responds to HTTP errors with a JSON error message.
-// It takes the HTTP status code and an error object as input parameters and returns a JSON response with the error message.
+// The error() method responds to HTTP errors with an error message in the negotiated format.
+// It takes the HTTP status code and an error object as input parameters and responds with the error message.
 func (s *server) error(w http.ResponseWriter, code int, err error) {
 	log.Printf("HTTP error %d: %s", code, err.Error()) // Log the error
-	s.respond(w, code, models.Error{Error: err.Error()})
+	s.respond(w, code, api.Error{Error: err.Error()})
 }
 
-// The respond() method sets the content type to JSON and writes the provided data to the response writer.
+// The respond() method writes the provided data to the response writer in the negotiated format.
+// JSON keeps a JSON media type that has already been negotiated. If none of the requested formats
+// is supported, it responds with 406 Not Acceptable instead, while errors fall back to JSON.
 // It takes the HTTP status code and any data to be sent in the response body as input parameters.
 func (s *server) respond(w http.ResponseWriter, code int, data interface{}) {
-	w.Header().Set("Content-Type", "application/json")
+	e := responseEncoding(w)
+	if code < http.StatusBadRequest && !s.acceptable(w) {
+		return
+	}
+	if e == nil || e.encode == nil {
+		e = jsonEncoding
+	}
+
+	if e == jsonEncoding {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		w.WriteHeader(code)
+		if data != nil {
+			json.NewEncoder(w).Encode(data)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", e.contentType)
+	if data == nil {
+		w.WriteHeader(code)
+		return
+	}
+	tree, err := codec.Tree(data)
+	if err != nil {
+		log.Printf("Error encoding the response as %s: %v", e.name, err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(api.Error{Error: "internal server error"})
+		return
+	}
 	w.WriteHeader(code)
-	if data != nil {
-		json.NewEncoder(w).Encode(data)
+	if err := e.encode(w, tree); err != nil {
+		log.Printf("Error encoding the response as %s: %v", e.name, err)
 	}
 }
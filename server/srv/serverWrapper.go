@@ -1,25 +1,72 @@
 package server
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+
+	models "servermodule/servermodels"
 )
 
-// Start starts the HTTP server with the provided configuration.
-// It initializes a new server instance and listens on the specified port.
+// Start starts the HTTP and gRPC servers with the configuration read from the environment.
+// It initializes a new server instance, starts the background sampler and drift checks and listens on the specified ports
+// until it receives SIGINT or SIGTERM, which rolls back the changes that aren't confirmed yet.
 func Start() error {
-	// Create a new server instance
-	srv := NewServer()
+	config := NewConfig()
+
+	// Create a new server instance collecting interface details from the live host
+	srv := NewServer(models.NewNetlinkCollector(), config)
+
+	// Start sampling interface state in the background
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go srv.sampler.run(ctx)
+	go srv.watchDrift(ctx, config.SampleInterval)
 
 	// Print a message indicating that the server is running
-	log.Printf("Server listening on port %s\n", os.Getenv("PORT"))
+	log.Printf("Server listening on port %s\n", config.Port)
 
 	// Start the HTTP server and listen on the specified port
-	if err := http.ListenAndServe(os.Getenv("PORT"), srv); err != nil {
+	httpServer := &http.Server{Addr: config.Port, Handler: srv}
+	errs := make(chan error, 2)
+	go func() {
+		errs <- httpServer.ListenAndServe()
+	}()
+
+	// Start the gRPC server on its own port, if enabled
+	grpcServer, healthServer := srv.newGRPCServer()
+	if config.GRPCPort != "" {
+		listener, err := net.Listen("tcp", config.GRPCPort)
+		if err != nil {
+			httpServer.Close()
+			return fmt.Errorf("failed to start gRPC server: %v", err)
+		}
+		log.Printf("gRPC server listening on port %s\n", config.GRPCPort)
+		go func() {
+			errs <- grpcServer.Serve(listener)
+		}()
+	}
+
+	select {
+	case err := <-errs:
 		return fmt.Errorf("failed to start server: %v", err)
+	case <-ctx.Done():
+	}
+
+	// Stop accepting write operations before rolling back, streams are closed rather than drained
+	log.Println("Server shutting down")
+	healthServer.Shutdown()
+	grpcServer.Stop()
+	if err := httpServer.Close(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
 	}
+	srv.changes.rollbackAll()
 
 	return nil
 }
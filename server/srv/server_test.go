@@ -1,14 +1,29 @@
 package server_test
 
 import (
+	api "apimodule"
+	"context"
 	"encoding/json"
+	"net"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
 	models "servermodule/servermodels"
 	server "servermodule/srv"
+	"strconv"
+	"strings"
+	"syscall"
 	"testing"
+	"time"
+
+	"github.com/gorilla/websocket"
 )
 
+// errInvalidQuery is the error message for unsupported query parameters of the /network endpoint.
+const errInvalidQuery = "only ?interface={interface_name}, ?stats={true|false}, ?fields={field,...} and the filters ?name={glob}, ?kind={kind}, " +
+	"?oper_status={status}, ?admin_status={status}, ?has_ipv4={true|false}, ?has_ipv6={true|false}, ?in_cidr={prefix} " +
+	"and ?mac_prefix={octets} input formats are allowed"
+
 // TestNetworkEndpointParams tests different scenarios related to the /network endpoint parameters.
 func TestNetworkEndpointParams(t *testing.T) {
 	tests := []struct {
@@ -27,7 +42,19 @@ func TestNetworkEndpointParams(t *testing.T) {
 			name:          "InvalidInput",
 			query:         "interfa=lo",
 			expectedCode:  http.StatusBadRequest,
-			expectedError: "only ?interface={interface_name} input format is allowed",
+			expectedError: errInvalidQuery,
+		},
+		{
+			name:          "InvalidFilter",
+			query:         "oper_status=running",
+			expectedCode:  http.StatusBadRequest,
+			expectedError: "invalid oper_status parameter: expected UP, DOWN or unknown",
+		},
+		{
+			name:          "RepeatedInterface",
+			query:         "interface=lo&interface=eth0",
+			expectedCode:  http.StatusBadRequest,
+			expectedError: errInvalidQuery,
 		},
 		{
 			name:          "NoParam",
@@ -52,8 +79,8 @@ func TestNetworkEndpointParams(t *testing.T) {
 
 			rr := httptest.NewRecorder()
 
-			// Create a new server instance
-			srv := server.NewServer()
+			// Create a new server instance backed by the sysfs fixture tree
+			srv := server.NewServer(models.NewSysfsCollector("../servermodels/testdata"), server.NewConfig())
 
 			// Serve the request to the server
 			srv.ServeHTTP(rr, req)
@@ -64,7 +91,7 @@ func TestNetworkEndpointParams(t *testing.T) {
 			}
 
 			// Check if the response body contains the expected error message
-			var actualError models.Error
+			var actualError api.Error
 			if err := json.NewDecoder(rr.Body).Decode(&actualError); err != nil {
 				t.Errorf("failed to decode response body: %v", err)
 			}
@@ -75,3 +102,1592 @@ func TestNetworkEndpointParams(t *testing.T) {
 		})
 	}
 }
+
+// addressCollector serves the sysfs fixture tree with addresses assigned to eth0, if they are selected.
+type addressCollector struct {
+	*models.SysfsCollector
+}
+
+func (c addressCollector) Interfaces() ([]api.NetworkInterfaceV2, error) {
+	return c.InterfacesWith(models.AllDetails)
+}
+
+func (c addressCollector) InterfacesWith(details models.Details) ([]api.NetworkInterfaceV2, error) {
+	interfaces, err := c.SysfsCollector.InterfacesWith(details)
+	for i := range interfaces {
+		if interfaces[i].Name == "eth0" && details&models.DetailAddresses != 0 {
+			interfaces[i].Addresses = []api.Address{
+				{Address: "10.1.2.3", PrefixLength: 8, Family: api.FamilyIPv4},
+				{Address: "fe80::211:22ff:fe33:4455", PrefixLength: 64, Family: api.FamilyIPv6},
+			}
+		}
+	}
+	return interfaces, err
+}
+
+// TestNetworkEndpointFilters tests that the filter parameters of the /network endpoint are
+// combined with AND semantics and their repeated values with OR semantics.
+func TestNetworkEndpointFilters(t *testing.T) {
+	tests := []struct {
+		query         string
+		expectedNames []string
+	}{
+		{query: "", expectedNames: []string{"eth0", "lo", "wlan0"}},
+		{query: "name=eth*", expectedNames: []string{"eth0"}},
+		{query: "name=eth*&name=wlan?", expectedNames: []string{"eth0", "wlan0"}},
+		{query: "oper_status=up&oper_status=unknown", expectedNames: []string{"eth0", "lo"}},
+		{query: "admin_status=up&name=*0", expectedNames: []string{"eth0"}},
+		{query: "kind=device", expectedNames: []string{"eth0", "lo", "wlan0"}},
+		{query: "kind=vlan", expectedNames: []string{}},
+		{query: "has_ipv4=true", expectedNames: []string{"eth0"}},
+		{query: "has_ipv6=false", expectedNames: []string{"lo", "wlan0"}},
+		{query: "in_cidr=10.0.0.0/8", expectedNames: []string{"eth0"}},
+		{query: "in_cidr=192.168.0.0/16&in_cidr=fe80::/10", expectedNames: []string{"eth0"}},
+		{query: "in_cidr=192.168.0.0/16", expectedNames: []string{}},
+		{query: "mac_prefix=A1:B2", expectedNames: []string{"wlan0"}},
+		{query: "mac_prefix=00:11:22&mac_prefix=00:00:00&oper_status=up", expectedNames: []string{"eth0"}},
+		{query: "interface=lo&name=eth*", expectedNames: []string{}},
+	}
+
+	srv := server.NewServer(addressCollector{models.NewSysfsCollector("../servermodels/testdata")}, server.NewConfig())
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/v2/network?"+test.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			srv.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != http.StatusOK {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+			}
+
+			var response api.NetworkInterfacesV2
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response body: %v", err)
+			}
+			names := []string{}
+			for _, iface := range response.Interfaces {
+				names = append(names, iface.Name)
+			}
+			if !reflect.DeepEqual(names, test.expectedNames) {
+				t.Errorf("interfaces mismatch: got %v, want %v", names, test.expectedNames)
+			}
+		})
+	}
+
+	for _, query := range []string{"name=[", "has_ipv4=maybe", "in_cidr=10.0.0.1", "mac_prefix=0:11", "admin_status=testing", "kind="} {
+		req, err := http.NewRequest("GET", "/v2/network?"+query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		rr := httptest.NewRecorder()
+		srv.ServeHTTP(rr, req)
+		if status := rr.Code; status != http.StatusBadRequest {
+			t.Errorf("%s: handler returned wrong status code: got %v want %v", query, status, http.StatusBadRequest)
+		}
+	}
+}
+
+// TestV1NetworkEndpointFilters tests that the status filters of the v1 /network endpoint take the
+// values of the v1 model and reject those of the v2 model.
+func TestV1NetworkEndpointFilters(t *testing.T) {
+	tests := []struct {
+		query         string
+		expectedCode  int
+		expectedNames []string
+		expectedError string
+	}{
+		{query: "oper_status=UP", expectedCode: http.StatusOK, expectedNames: []string{"eth0"}},
+		{query: "oper_status=DOWN&oper_status=unknown", expectedCode: http.StatusOK, expectedNames: []string{"lo", "wlan0"}},
+		{query: "admin_status=enabled", expectedCode: http.StatusOK, expectedNames: []string{"eth0"}},
+		{query: "admin_status=disabled&name=wlan*", expectedCode: http.StatusOK, expectedNames: []string{"wlan0"}},
+		{query: "admin_status=unknown", expectedCode: http.StatusOK, expectedNames: []string{"lo"}},
+		{query: "oper_status=up", expectedCode: http.StatusBadRequest, expectedError: "invalid oper_status parameter: expected UP, DOWN or unknown"},
+		{query: "admin_status=up", expectedCode: http.StatusBadRequest, expectedError: "invalid admin_status parameter: expected enabled, disabled or unknown"},
+	}
+
+	srv := server.NewServer(models.NewSysfsCollector("../servermodels/testdata"), server.NewConfig())
+	for _, test := range tests {
+		t.Run(test.query, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/v1/network?"+test.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			srv.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != test.expectedCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, test.expectedCode)
+			}
+			if test.expectedCode != http.StatusOK {
+				var body api.Error
+				if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+					t.Fatalf("failed to decode response body: %v", err)
+				}
+				if body.Error != test.expectedError {
+					t.Errorf("error message mismatch: got %q, want %q", body.Error, test.expectedError)
+				}
+				return
+			}
+
+			var response api.NetworkInterfaces
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response body: %v", err)
+			}
+			names := []string{}
+			for _, iface := range response.Interfaces {
+				names = append(names, iface.Name)
+			}
+			if !reflect.DeepEqual(names, test.expectedNames) {
+				t.Errorf("interfaces mismatch: got %v, want %v", names, test.expectedNames)
+			}
+		})
+	}
+}
+
+// detailsCollector serves the sysfs fixture tree and records the details selected by the last request.
+type detailsCollector struct {
+	*models.SysfsCollector
+	details models.Details
+}
+
+func (c *detailsCollector) InterfacesWith(details models.Details) ([]api.NetworkInterfaceV2, error) {
+	c.details = details
+	return c.SysfsCollector.InterfacesWith(details)
+}
+
+func (c *detailsCollector) InterfaceWith(name string, details models.Details) (*api.NetworkInterfaceV2, error) {
+	c.details = details
+	return c.SysfsCollector.InterfaceWith(name, details)
+}
+
+// TestNetworkEndpointFields tests that ?fields= reduces the interfaces to the selected fields,
+// in the order of the model, and that only the details of those fields are collected.
+func TestNetworkEndpointFields(t *testing.T) {
+	tests := []struct {
+		name            string
+		path            string
+		expectedCode    int
+		expectedBody    string
+		expectedDetails models.Details
+	}{
+		{
+			name:            "V1",
+			path:            "/v1/network?fields=operational_status,name",
+			expectedCode:    http.StatusOK,
+			expectedBody:    `{"network_interface":[{"name":"eth0","operational_status":"UP"},{"name":"lo","operational_status":"unknown"},{"name":"wlan0","operational_status":"DOWN"}]}`,
+			expectedDetails: 0,
+		},
+		{
+			name:            "V2",
+			path:            "/v2/network?interface=eth0&fields=name,speed_mbps,alias",
+			expectedCode:    http.StatusOK,
+			expectedBody:    `{"network_interfaces":[{"name":"eth0","speed_mbps":1000}]}`,
+			expectedDetails: models.DetailLinkModes,
+		},
+		{
+			name:            "Filter",
+			path:            "/v2/network?fields=name&has_ipv4=false&name=wlan*",
+			expectedCode:    http.StatusOK,
+			expectedBody:    `{"network_interfaces":[{"name":"wlan0"}]}`,
+			expectedDetails: models.DetailAddresses,
+		},
+		{
+			name:            "Stats",
+			path:            "/v2/network?interface=lo&fields=name,stats&stats=true",
+			expectedCode:    http.StatusOK,
+			expectedBody:    `{"network_interfaces":[{"name":"lo","stats":{"rx_bytes":4096,"tx_bytes":4096,"rx_packets":64,"tx_packets":64,"rx_errors":0,"tx_errors":0,"rx_dropped":0,"tx_dropped":0,"multicast":0,"collisions":0}}]}`,
+			expectedDetails: models.DetailStats,
+		},
+		{
+			name:            "Interface",
+			path:            "/v2/network/wlan0?fields=mtu,name",
+			expectedCode:    http.StatusOK,
+			expectedBody:    `{"name":"wlan0","mtu":1200}`,
+			expectedDetails: 0,
+		},
+		{
+			name:            "InterfaceV1",
+			path:            "/network/eth0?fields=ip_addresses,duplex",
+			expectedCode:    http.StatusOK,
+			expectedBody:    `{"ip_addresses":null,"duplex":"Full"}`,
+			expectedDetails: models.DetailAddresses | models.DetailLinkModes,
+		},
+		{
+			name:            "All",
+			path:            "/v2/network/eth0",
+			expectedCode:    http.StatusOK,
+			expectedDetails: models.AllDetails &^ models.DetailStats,
+		},
+		{
+			name:            "Topology",
+			path:            "/v2/topology",
+			expectedCode:    http.StatusOK,
+			expectedDetails: 0,
+		},
+		{
+			name:            "Metrics",
+			path:            "/metrics",
+			expectedCode:    http.StatusOK,
+			expectedDetails: models.AllDetails,
+		},
+		{name: "UnknownField", path: "/v2/network?fields=name,operational_status", expectedCode: http.StatusBadRequest},
+		{name: "EmptyField", path: "/v2/network/eth0?fields=name,", expectedCode: http.StatusBadRequest},
+		{name: "RepeatedFields", path: "/v2/network?fields=name&fields=mtu", expectedCode: http.StatusBadRequest},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			collector := &detailsCollector{SysfsCollector: models.NewSysfsCollector("../servermodels/testdata"), details: 0xff}
+			req, err := http.NewRequest("GET", test.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			server.NewServer(collector, server.NewConfig()).ServeHTTP(rr, req)
+
+			if status := rr.Code; status != test.expectedCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, test.expectedCode)
+			}
+			if test.expectedCode != http.StatusOK {
+				return
+			}
+			if body := strings.TrimSpace(rr.Body.String()); test.expectedBody != "" && body != test.expectedBody {
+				t.Errorf("body mismatch: got %s, want %s", body, test.expectedBody)
+			}
+			if collector.details != test.expectedDetails {
+				t.Errorf("details mismatch: got %04b, want %04b", collector.details, test.expectedDetails)
+			}
+		})
+	}
+}
+
+// TestStatsEndpoint tests the /network/{name}/stats endpoint.
+func TestStatsEndpoint(t *testing.T) {
+	tests := []struct {
+		name          string
+		path          string
+		expectedCode  int
+		expectedError string
+	}{
+		{
+			name:         "ValidInterface",
+			path:         "/network/eth0/stats",
+			expectedCode: http.StatusOK,
+		},
+		{
+			name:          "InvalidInterface",
+			path:          "/network/test/stats",
+			expectedCode:  http.StatusNotFound,
+			expectedError: "there is no such interface",
+		},
+	}
+
+	srv := server.NewServer(models.NewSysfsCollector("../servermodels/testdata"), server.NewConfig())
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", test.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			srv.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != test.expectedCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, test.expectedCode)
+			}
+
+			if test.expectedError != "" {
+				var actualError api.Error
+				if err := json.NewDecoder(rr.Body).Decode(&actualError); err != nil {
+					t.Errorf("failed to decode response body: %v", err)
+				}
+				if actualError.Error != test.expectedError {
+					t.Errorf("error message mismatch: got %q, want %q", actualError.Error, test.expectedError)
+				}
+				return
+			}
+
+			var stats api.NetworkInterfaceStats
+			if err := json.NewDecoder(rr.Body).Decode(&stats); err != nil {
+				t.Errorf("failed to decode response body: %v", err)
+			}
+			if stats.Name != "eth0" || stats.Stats.RxBytes != 1234567 {
+				t.Errorf("unexpected stats: %+v", stats)
+			}
+		})
+	}
+}
+
+// TestNetworkEndpointStats tests that the stats block is only included when requested.
+func TestNetworkEndpointStats(t *testing.T) {
+	srv := server.NewServer(models.NewSysfsCollector("../servermodels/testdata"), server.NewConfig())
+
+	for query, expectStats := range map[string]bool{"": false, "stats=false": false, "stats=true": true} {
+		req, err := http.NewRequest("GET", "/network?"+query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		srv.ServeHTTP(rr, req)
+
+		var body api.NetworkInterfaces
+		if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+			t.Fatalf("failed to decode response body: %v", err)
+		}
+
+		for _, iface := range body.Interfaces {
+			if (iface.Stats != nil) != expectStats {
+				t.Errorf("query %q: unexpected stats presence for %s: %v", query, iface.Name, iface.Stats != nil)
+			}
+		}
+	}
+}
+
+// TestNetworkEndpointV2 tests that the /v2/network endpoint serves the typed model,
+// while the /network endpoint keeps serving the v1 model for the same interface.
+func TestNetworkEndpointV2(t *testing.T) {
+	srv := server.NewServer(models.NewSysfsCollector("../servermodels/testdata"), server.NewConfig())
+
+	req, err := http.NewRequest("GET", "/v2/network?interface=wlan0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr := httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	var raw struct {
+		Interfaces []map[string]interface{} `json:"network_interfaces"`
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&raw); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+	if len(raw.Interfaces) != 1 {
+		t.Fatalf("unexpected number of interfaces: got %d want 1", len(raw.Interfaces))
+	}
+
+	iface := raw.Interfaces[0]
+	expected := map[string]interface{}{
+		"name":         "wlan0",
+		"speed_mbps":   nil,
+		"duplex":       api.DuplexUnknown,
+		"admin_status": api.AdminStatusDown,
+		"oper_status":  api.OperStatusDown,
+	}
+	for key, want := range expected {
+		if got, ok := iface[key]; !ok || got != want {
+			t.Errorf("field %s mismatch: got %v want %v", key, got, want)
+		}
+	}
+
+	// The v1 endpoint reports the same interface with free-form strings
+	req, err = http.NewRequest("GET", "/network?interface=wlan0", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	rr = httptest.NewRecorder()
+	srv.ServeHTTP(rr, req)
+
+	var body api.NetworkInterfaces
+	if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+	if len(body.Interfaces) != 1 || body.Interfaces[0].AdminStatus != "disabled" || body.Interfaces[0].OperationalStatus != "DOWN" {
+		t.Errorf("unexpected v1 interfaces: %+v", body.Interfaces)
+	}
+}
+
+// TestInterfaceEndpoint tests the /network/{name} endpoint in both API versions.
+func TestInterfaceEndpoint(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         string
+		accept       string
+		expectedCode int
+		expectedKind string
+	}{
+		{name: "V2", path: "/v2/network/eth0", expectedCode: http.StatusOK, expectedKind: api.KindDevice},
+		{name: "V1", path: "/v1/network/eth0", expectedCode: http.StatusOK},
+		{name: "Negotiated", path: "/network/eth0", accept: "application/vnd.interfacer.v2+json", expectedCode: http.StatusOK, expectedKind: api.KindDevice},
+		{name: "NoSuchInterface", path: "/v2/network/eth1", expectedCode: http.StatusNotFound},
+	}
+
+	srv := server.NewServer(models.NewSysfsCollector("../servermodels/testdata"), server.NewConfig())
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", test.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.accept != "" {
+				req.Header.Set("Accept", test.accept)
+			}
+
+			rr := httptest.NewRecorder()
+			srv.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != test.expectedCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, test.expectedCode)
+			}
+			if test.expectedCode != http.StatusOK {
+				return
+			}
+
+			var raw map[string]interface{}
+			if err := json.NewDecoder(rr.Body).Decode(&raw); err != nil {
+				t.Fatalf("failed to decode response body: %v", err)
+			}
+			if raw["name"] != "eth0" {
+				t.Errorf("name mismatch: got %v want %v", raw["name"], "eth0")
+			}
+			if _, ok := raw["stats"]; ok {
+				t.Error("unexpected stats in response")
+			}
+
+			// Only the v2 model has a kind, the v1 model reports the free-form status instead
+			if test.expectedKind != "" && raw["kind"] != test.expectedKind {
+				t.Errorf("kind mismatch: got %v want %v", raw["kind"], test.expectedKind)
+			}
+			if test.expectedKind == "" && raw["admin_status"] != "enabled" {
+				t.Errorf("admin status mismatch: got %v want %v", raw["admin_status"], "enabled")
+			}
+		})
+	}
+}
+
+// TestAPIVersioning tests the versioned route trees, the negotiation of the API version
+// on the legacy /network endpoint and the deprecation headers of v1 responses.
+func TestAPIVersioning(t *testing.T) {
+	tests := []struct {
+		name                string
+		path                string
+		accept              string
+		expectedV2          bool
+		expectedContentType string
+		expectedSuccessor   string
+	}{
+		{
+			name:                "Legacy",
+			path:                "/network",
+			expectedContentType: "application/json",
+			expectedSuccessor:   "</v2/network>; rel=\"successor-version\"",
+		},
+		{
+			name:                "LegacyAcceptV2",
+			path:                "/network",
+			accept:              "application/vnd.interfacer.v2+json",
+			expectedV2:          true,
+			expectedContentType: "application/vnd.interfacer.v2+json",
+		},
+		{
+			name:                "LegacyPreferV1",
+			path:                "/network",
+			accept:              "application/vnd.interfacer.v2+json;q=0.5, application/vnd.interfacer.v1+json",
+			expectedContentType: "application/vnd.interfacer.v1+json",
+			expectedSuccessor:   "</v2/network>; rel=\"successor-version\"",
+		},
+		{
+			name:                "LegacyAlias",
+			path:                "/network/eth0/stats",
+			expectedContentType: "application/json",
+			expectedSuccessor:   "</v2/network/eth0/stats>; rel=\"successor-version\"",
+		},
+		{
+			name:                "V1",
+			path:                "/v1/network",
+			expectedContentType: "application/json",
+			expectedSuccessor:   "</v2/network>; rel=\"successor-version\"",
+		},
+		{
+			name:                "V1Stats",
+			path:                "/v1/network/eth0/stats",
+			expectedContentType: "application/json",
+			expectedSuccessor:   "</v2/network/eth0/stats>; rel=\"successor-version\"",
+		},
+		{
+			name:                "V2",
+			path:                "/v2/network",
+			expectedV2:          true,
+			expectedContentType: "application/json",
+		},
+		{
+			name:                "V2Stats",
+			path:                "/v2/network/eth0/stats",
+			expectedContentType: "application/json",
+		},
+	}
+
+	config := server.NewConfig()
+	config.V1Deprecation = time.Date(2026, time.October, 18, 0, 0, 0, 0, time.UTC)
+	config.V1Sunset = time.Date(2027, time.April, 18, 0, 0, 0, 0, time.UTC)
+	srv := server.NewServer(models.NewSysfsCollector("../servermodels/testdata"), config)
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", test.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.accept != "" {
+				req.Header.Set("Accept", test.accept)
+			}
+
+			rr := httptest.NewRecorder()
+			srv.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != http.StatusOK {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+			}
+			if contentType := rr.Header().Get("Content-Type"); contentType != test.expectedContentType {
+				t.Errorf("content type mismatch: got %q want %q", contentType, test.expectedContentType)
+			}
+
+			// Only v1 responses are marked as deprecated
+			deprecated := test.expectedSuccessor != ""
+			if got := rr.Header().Get("Link"); got != test.expectedSuccessor {
+				t.Errorf("link header mismatch: got %q want %q", got, test.expectedSuccessor)
+			}
+			if got, want := rr.Header().Get("Deprecation"), "@1792281600"; (got == want) != deprecated {
+				t.Errorf("unexpected deprecation header: %q", got)
+			}
+			if got, want := rr.Header().Get("Sunset"), "Sun, 18 Apr 2027 00:00:00 GMT"; (got == want) != deprecated {
+				t.Errorf("unexpected sunset header: %q", got)
+			}
+
+			if strings.HasSuffix(test.path, "/stats") {
+				return
+			}
+
+			var body map[string]json.RawMessage
+			if err := json.NewDecoder(rr.Body).Decode(&body); err != nil {
+				t.Fatalf("failed to decode response body: %v", err)
+			}
+			if _, v2 := body["network_interfaces"]; v2 != test.expectedV2 {
+				t.Errorf("response version mismatch: got v2 %v want v2 %v", v2, test.expectedV2)
+			}
+		})
+	}
+}
+
+// TestResponseEncodings tests the response formats negotiated with the Accept header and the ?format= parameter.
+func TestResponseEncodings(t *testing.T) {
+	tests := []struct {
+		name                string
+		method              string
+		path                string
+		accept              string
+		expectedCode        int
+		expectedContentType string
+		expectedBody        string
+	}{
+		{
+			name:                "Default",
+			path:                "/v2/network?name=lo&fields=name",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody:        "{\"network_interfaces\":[{\"name\":\"lo\"}]}\n",
+		},
+		{
+			name:                "Wildcard",
+			path:                "/v2/network?name=lo&fields=name",
+			accept:              "text/html, */*;q=0.1",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/json",
+			expectedBody:        "{\"network_interfaces\":[{\"name\":\"lo\"}]}\n",
+		},
+		{
+			name:                "YAML",
+			path:                "/v2/network?name=lo&fields=name,mtu",
+			accept:              "application/json;q=0.5, application/yaml",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/yaml",
+			expectedBody:        "network_interfaces:\n  - name: lo\n    mtu: 65536\n",
+		},
+		{
+			name:                "YAMLFormat",
+			path:                "/v2/network?format=yaml&name=lo&fields=name",
+			accept:              "application/json",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/yaml",
+			expectedBody:        "network_interfaces:\n  - name: lo\n",
+		},
+		{
+			name:                "CSV",
+			path:                "/v2/network?format=csv&name=eth0&name=wlan0&fields=name,addresses",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "text/csv; charset=utf-8",
+			expectedBody: "name,addresses.address,addresses.prefix_length,addresses.family,addresses.scope,addresses.flags\n" +
+				"eth0,10.1.2.3,8,ipv4,,\n" +
+				"eth0,fe80::211:22ff:fe33:4455,64,ipv6,,\n" +
+				"wlan0,,,,,\n",
+		},
+		{
+			name:                "TSV",
+			path:                "/v1/network?name=eth0&fields=name,ip_addresses",
+			accept:              "text/tab-separated-values",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "text/tab-separated-values; charset=utf-8",
+			expectedBody:        "name\tip_addresses\neth0\t10.1.2.3\neth0\tfe80::211:22ff:fe33:4455\n",
+		},
+		{
+			name:                "MessagePack",
+			path:                "/v2/network?format=msgpack&name=lo&fields=name",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/msgpack",
+			expectedBody:        "\x81\xb2network_interfaces\x91\x81\xa4name\xa2lo",
+		},
+		{
+			name:                "CBOR",
+			path:                "/v2/network?name=lo&fields=name",
+			accept:              "application/cbor",
+			expectedCode:        http.StatusOK,
+			expectedContentType: "application/cbor",
+			expectedBody:        "\xa1\x72network_interfaces\x81\xa1\x64name\x62lo",
+		},
+		{
+			name:                "YAMLError",
+			path:                "/v2/network/eth9?format=yaml",
+			expectedCode:        http.StatusNotFound,
+			expectedContentType: "application/yaml",
+			expectedBody:        "error: there is no such interface\n",
+		},
+		{
+			name:                "UnsupportedAccept",
+			path:                "/v2/network",
+			accept:              "application/xml",
+			expectedCode:        http.StatusNotAcceptable,
+			expectedContentType: "application/json",
+		},
+		{
+			name:                "UnsupportedFormat",
+			path:                "/v2/network?format=xml",
+			expectedCode:        http.StatusNotAcceptable,
+			expectedContentType: "application/json",
+		},
+		{
+			name:                "TopologyOnlyFormat",
+			path:                "/v2/network?format=dot",
+			expectedCode:        http.StatusNotAcceptable,
+			expectedContentType: "application/json",
+		},
+		{
+			name:                "UnsupportedWrite",
+			method:              "PATCH",
+			path:                "/v2/network/eth0?format=xml",
+			expectedCode:        http.StatusNotAcceptable,
+			expectedContentType: "application/json",
+		},
+	}
+
+	srv := server.NewServer(addressCollector{models.NewSysfsCollector("../servermodels/testdata")}, server.NewConfig())
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			method := test.method
+			if method == "" {
+				method = "GET"
+			}
+			req, err := http.NewRequest(method, test.path, strings.NewReader(`{"mtu":1400}`))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.accept != "" {
+				req.Header.Set("Accept", test.accept)
+			}
+
+			rr := httptest.NewRecorder()
+			srv.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != test.expectedCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, test.expectedCode)
+			}
+			if contentType := rr.Header().Get("Content-Type"); contentType != test.expectedContentType {
+				t.Errorf("content type mismatch: got %q want %q", contentType, test.expectedContentType)
+			}
+			if test.expectedBody != "" && rr.Body.String() != test.expectedBody {
+				t.Errorf("body mismatch:\ngot  %q\nwant %q", rr.Body.String(), test.expectedBody)
+			}
+		})
+	}
+}
+
+// TestHistoryEndpointParams tests the validation of the /network/{name}/history endpoint parameters.
+func TestHistoryEndpointParams(t *testing.T) {
+	tests := []struct {
+		name         string
+		path         string
+		expectedCode int
+	}{
+		{name: "NotSampled", path: "/network/eth0/history", expectedCode: http.StatusNotFound},
+		{name: "RelativeSince", path: "/network/eth0/history?since=5m", expectedCode: http.StatusNotFound},
+		{name: "InvalidSince", path: "/network/eth0/history?since=yesterday", expectedCode: http.StatusBadRequest},
+		{name: "InvalidUntil", path: "/network/eth0/history?until=tomorrow", expectedCode: http.StatusBadRequest},
+		{name: "InvalidStep", path: "/network/eth0/history?step=-1s", expectedCode: http.StatusBadRequest},
+	}
+
+	srv := server.NewServer(models.NewSysfsCollector("../servermodels/testdata"), server.NewConfig())
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", test.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			srv.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != test.expectedCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, test.expectedCode)
+			}
+		})
+	}
+}
+
+// TestEventsEndpoint tests that the /network/events endpoint opens an event stream.
+func TestEventsEndpoint(t *testing.T) {
+	tests := []struct {
+		name         string
+		lastEventID  string
+		expectedCode int
+	}{
+		{name: "NewStream", expectedCode: http.StatusOK},
+		{name: "Resume", lastEventID: "42", expectedCode: http.StatusOK},
+		{name: "InvalidLastEventID", lastEventID: "abc", expectedCode: http.StatusBadRequest},
+	}
+
+	srv := server.NewServer(models.NewSysfsCollector("../servermodels/testdata"), server.NewConfig())
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			// Cancel the request shortly after so the stream terminates
+			ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+			defer cancel()
+
+			req, err := http.NewRequestWithContext(ctx, "GET", "/network/events", nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", test.lastEventID)
+			}
+
+			rr := httptest.NewRecorder()
+			srv.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != test.expectedCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, test.expectedCode)
+			}
+			if test.expectedCode == http.StatusOK && rr.Header().Get("Content-Type") != "text/event-stream" {
+				t.Errorf("unexpected content type: %q", rr.Header().Get("Content-Type"))
+			}
+		})
+	}
+}
+
+// TestWebSocketSubscriptions tests subscribing and unsubscribing on the /network/ws endpoint.
+func TestWebSocketSubscriptions(t *testing.T) {
+	srv := httptest.NewServer(server.NewServer(models.NewSysfsCollector("../servermodels/testdata"), server.NewConfig()))
+	defer srv.Close()
+
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/network/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+
+	tests := []struct {
+		name                 string
+		message              string
+		expectedType         string
+		expectedSubscription api.Subscription
+	}{
+		{
+			name:                 "Subscribe",
+			message:              `{"action":"subscribe","interfaces":["eth0","wlan0"],"events":["link_up","link_down","stats"]}`,
+			expectedType:         api.MessageSubscription,
+			expectedSubscription: api.Subscription{Interfaces: []string{"eth0", "wlan0"}, Events: []string{"link_down", "link_up", "stats"}},
+		},
+		{
+			name:                 "Unsubscribe",
+			message:              `{"action":"unsubscribe","interfaces":["wlan0"],"events":["stats"]}`,
+			expectedType:         api.MessageSubscription,
+			expectedSubscription: api.Subscription{Interfaces: []string{"eth0"}, Events: []string{"link_down", "link_up"}},
+		},
+		{
+			name:         "InvalidAction",
+			message:      `{"action":"listen"}`,
+			expectedType: api.MessageError,
+		},
+		{
+			name:         "InvalidMessage",
+			message:      `subscribe`,
+			expectedType: api.MessageError,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := conn.WriteMessage(websocket.TextMessage, []byte(test.message)); err != nil {
+				t.Fatal(err)
+			}
+
+			var reply api.Message
+			conn.SetReadDeadline(time.Now().Add(time.Second))
+			if err := conn.ReadJSON(&reply); err != nil {
+				t.Fatal(err)
+			}
+
+			if reply.Type != test.expectedType {
+				t.Errorf("message type mismatch: got %q, want %q", reply.Type, test.expectedType)
+			}
+			if test.expectedType == api.MessageSubscription && !reflect.DeepEqual(*reply.Subscription, test.expectedSubscription) {
+				t.Errorf("subscription mismatch: got %+v, want %+v", *reply.Subscription, test.expectedSubscription)
+			}
+		})
+	}
+}
+
+// TestMetricsEndpoint tests that the /metrics endpoint exposes interface and self-metrics.
+func TestMetricsEndpoint(t *testing.T) {
+	srv := server.NewServer(models.NewSysfsCollector("../servermodels/testdata"), server.NewConfig())
+
+	// Make a request first so that it shows up in the self-metrics
+	for _, path := range []string{"/network", "/metrics"} {
+		req, err := http.NewRequest("GET", path, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		rr := httptest.NewRecorder()
+		srv.ServeHTTP(rr, req)
+
+		if status := rr.Code; status != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+		if path != "/metrics" {
+			continue
+		}
+
+		expectedLines := []string{
+			`interfacer_interface_up{name="eth0"} 1`,
+			`interfacer_interface_up{name="wlan0"} 0`,
+			`interfacer_interface_mtu{name="lo"} 65536`,
+			`interfacer_interface_speed_bps{name="eth0"} 1000000000`,
+			`interfacer_interface_receive_bytes_total{name="eth0"} 1234567`,
+			`interfacer_http_requests_total{code="200",method="GET",route="/network"} 1`,
+			`interfacer_collector_duration_seconds_count{call="interfaces"} 2`,
+		}
+		for _, line := range expectedLines {
+			if !strings.Contains(rr.Body.String(), line+"\n") {
+				t.Errorf("missing line %q in output:\n%s", line, rr.Body.String())
+			}
+		}
+	}
+}
+
+// routeCollector serves the sysfs fixture tree along with a fixed routing table.
+type routeCollector struct {
+	*models.SysfsCollector
+}
+
+func (c routeCollector) Routes() ([]api.Route, error) {
+	return []api.Route{
+		{Family: api.FamilyIPv4, Destination: "0.0.0.0/0", Gateway: "192.168.1.1", Interface: "eth0", Metric: 100, Protocol: "dhcp", Scope: "global", Table: "main", Type: "unicast"},
+		{Family: api.FamilyIPv4, Destination: "192.168.1.0/24", Source: "192.168.1.10", Interface: "eth0", Protocol: "kernel", Scope: "link", Table: "main", Type: "unicast"},
+		{Family: api.FamilyIPv4, Destination: "10.0.0.0/8", Interface: "wlan0", Protocol: "static", Scope: "global", Table: "100", Type: "unicast"},
+		{Family: api.FamilyIPv6, Destination: "::/0", Gateway: "fe80::1", Interface: "wlan0", Metric: 1024, Protocol: "ra", Scope: "global", Table: "main", Type: "unicast"},
+	}, nil
+}
+
+func (c routeCollector) Rules() ([]api.Rule, error) {
+	return []api.Rule{{Family: api.FamilyIPv4, Priority: 32766, Action: "lookup", Table: "main"}}, nil
+}
+
+func (c routeCollector) LookupRoute(dst net.IP) (*api.Route, error) {
+	routes, _ := c.Routes()
+	if dst.Equal(net.ParseIP("192.168.1.20")) {
+		return &routes[1], nil
+	}
+	return nil, models.ErrNoRoute
+}
+
+// TestRoutesEndpoint tests the filters of the /routes endpoint.
+func TestRoutesEndpoint(t *testing.T) {
+	tests := []struct {
+		name                 string
+		query                string
+		expectedCode         int
+		expectedDestinations []string
+	}{
+		{name: "NoParam", query: "", expectedCode: http.StatusOK, expectedDestinations: []string{"0.0.0.0/0", "192.168.1.0/24", "10.0.0.0/8", "::/0"}},
+		{name: "TableByName", query: "table=main", expectedCode: http.StatusOK, expectedDestinations: []string{"0.0.0.0/0", "192.168.1.0/24", "::/0"}},
+		{name: "TableByID", query: "table=254", expectedCode: http.StatusOK, expectedDestinations: []string{"0.0.0.0/0", "192.168.1.0/24", "::/0"}},
+		{name: "Family", query: "family=ipv6", expectedCode: http.StatusOK, expectedDestinations: []string{"::/0"}},
+		{name: "Interface", query: "interface=wlan0&family=ipv4", expectedCode: http.StatusOK, expectedDestinations: []string{"10.0.0.0/8"}},
+		{name: "DestinationPrefix", query: "destination=192.168.1.0/24", expectedCode: http.StatusOK, expectedDestinations: []string{"192.168.1.0/24"}},
+		{name: "DestinationAddress", query: "destination=192.168.1.20", expectedCode: http.StatusOK, expectedDestinations: []string{"0.0.0.0/0", "192.168.1.0/24"}},
+		{name: "NoMatch", query: "table=200", expectedCode: http.StatusOK, expectedDestinations: []string{}},
+		{name: "InvalidFamily", query: "family=ipx", expectedCode: http.StatusBadRequest},
+		{name: "InvalidDestination", query: "destination=192.168.1", expectedCode: http.StatusBadRequest},
+		{name: "InvalidParam", query: "dst=192.168.1.20", expectedCode: http.StatusBadRequest},
+	}
+
+	srv := server.NewServer(routeCollector{models.NewSysfsCollector("../servermodels/testdata")}, server.NewConfig())
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/v2/routes?"+test.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			srv.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != test.expectedCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, test.expectedCode)
+			}
+			if test.expectedCode != http.StatusOK {
+				return
+			}
+
+			var response api.Routes
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response body: %v", err)
+			}
+			destinations := []string{}
+			for _, route := range response.Routes {
+				destinations = append(destinations, route.Destination)
+			}
+			if !reflect.DeepEqual(destinations, test.expectedDestinations) {
+				t.Errorf("destinations mismatch: got %v, want %v", destinations, test.expectedDestinations)
+			}
+		})
+	}
+}
+
+// TestRouteLookupEndpoint tests the /routes/lookup and /rules endpoints, and that they are
+// reported as not implemented by collectors without routing information.
+func TestRouteLookupEndpoint(t *testing.T) {
+	tests := []struct {
+		name          string
+		collector     models.Collector
+		path          string
+		expectedCode  int
+		expectedRoute string
+	}{
+		{name: "Lookup", collector: routeCollector{models.NewSysfsCollector("../servermodels/testdata")}, path: "/v2/routes/lookup?dst=192.168.1.20", expectedCode: http.StatusOK, expectedRoute: "192.168.1.0/24"},
+		{name: "NoRoute", collector: routeCollector{models.NewSysfsCollector("../servermodels/testdata")}, path: "/v2/routes/lookup?dst=172.16.0.1", expectedCode: http.StatusNotFound},
+		{name: "InvalidDestination", collector: routeCollector{models.NewSysfsCollector("../servermodels/testdata")}, path: "/v2/routes/lookup?dst=example.com", expectedCode: http.StatusBadRequest},
+		{name: "Rules", collector: routeCollector{models.NewSysfsCollector("../servermodels/testdata")}, path: "/v1/rules", expectedCode: http.StatusOK},
+		{name: "NotImplemented", collector: models.NewSysfsCollector("../servermodels/testdata"), path: "/routes", expectedCode: http.StatusNotImplemented},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", test.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			server.NewServer(test.collector, server.NewConfig()).ServeHTTP(rr, req)
+
+			if status := rr.Code; status != test.expectedCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, test.expectedCode)
+			}
+			if test.expectedRoute == "" {
+				return
+			}
+
+			var response api.RouteLookup
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response body: %v", err)
+			}
+			if response.Destination != "192.168.1.20" || response.Route.Destination != test.expectedRoute {
+				t.Errorf("unexpected lookup: %+v", response)
+			}
+		})
+	}
+}
+
+// neighborCollector serves the sysfs fixture tree along with a fixed neighbor table.
+type neighborCollector struct {
+	*models.SysfsCollector
+}
+
+func (c neighborCollector) Neighbors() ([]api.Neighbor, error) {
+	return []api.Neighbor{
+		{Family: api.FamilyIPv4, Address: "192.168.1.1", MACAddress: "00:11:22:33:44:55", Interface: "eth0", State: api.NeighborReachable, Flags: []string{}},
+		{Family: api.FamilyIPv6, Address: "fe80::1", MACAddress: "00:11:22:33:44:66", Interface: "wlan0", State: api.NeighborStale, Flags: []string{"router"}},
+	}, nil
+}
+
+// TestNeighborsEndpoint tests the /neighbors and /network/{name}/neighbors endpoints.
+func TestNeighborsEndpoint(t *testing.T) {
+	tests := []struct {
+		name              string
+		collector         models.Collector
+		path              string
+		expectedCode      int
+		expectedAddresses []string
+	}{
+		{name: "All", collector: neighborCollector{models.NewSysfsCollector("../servermodels/testdata")}, path: "/v2/neighbors", expectedCode: http.StatusOK, expectedAddresses: []string{"192.168.1.1", "fe80::1"}},
+		{name: "Interface", collector: neighborCollector{models.NewSysfsCollector("../servermodels/testdata")}, path: "/v2/network/wlan0/neighbors", expectedCode: http.StatusOK, expectedAddresses: []string{"fe80::1"}},
+		{name: "NoNeighbors", collector: neighborCollector{models.NewSysfsCollector("../servermodels/testdata")}, path: "/network/lo/neighbors", expectedCode: http.StatusOK, expectedAddresses: []string{}},
+		{name: "NoSuchInterface", collector: neighborCollector{models.NewSysfsCollector("../servermodels/testdata")}, path: "/v2/network/eth9/neighbors", expectedCode: http.StatusNotFound},
+		{name: "NotImplemented", collector: models.NewSysfsCollector("../servermodels/testdata"), path: "/neighbors", expectedCode: http.StatusNotImplemented},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", test.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			server.NewServer(test.collector, server.NewConfig()).ServeHTTP(rr, req)
+
+			if status := rr.Code; status != test.expectedCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, test.expectedCode)
+			}
+			if test.expectedCode != http.StatusOK {
+				return
+			}
+
+			var response api.Neighbors
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response body: %v", err)
+			}
+			addresses := []string{}
+			for _, neighbor := range response.Neighbors {
+				addresses = append(addresses, neighbor.Address)
+			}
+			if !reflect.DeepEqual(addresses, test.expectedAddresses) {
+				t.Errorf("addresses mismatch: got %v, want %v", addresses, test.expectedAddresses)
+			}
+		})
+	}
+}
+
+// TestTopologyEndpoint tests the JSON and Graphviz formats of the /topology endpoint.
+func TestTopologyEndpoint(t *testing.T) {
+	tests := []struct {
+		name                string
+		query               string
+		expectedCode        int
+		expectedContentType string
+	}{
+		{name: "JSON", query: "", expectedCode: http.StatusOK, expectedContentType: "application/json"},
+		{name: "DOT", query: "format=dot", expectedCode: http.StatusOK, expectedContentType: "text/vnd.graphviz; charset=utf-8"},
+		{name: "YAML", query: "format=yaml", expectedCode: http.StatusOK, expectedContentType: "application/yaml"},
+		{name: "InvalidFormat", query: "format=svg", expectedCode: http.StatusNotAcceptable, expectedContentType: "application/json"},
+		{name: "InvalidParam", query: "fmt=dot", expectedCode: http.StatusBadRequest, expectedContentType: "application/json"},
+	}
+
+	srv := server.NewServer(models.NewSysfsCollector("../servermodels/testdata"), server.NewConfig())
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", "/v2/topology?"+test.query, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			srv.ServeHTTP(rr, req)
+
+			if status := rr.Code; status != test.expectedCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, test.expectedCode)
+			}
+			if contentType := rr.Header().Get("Content-Type"); contentType != test.expectedContentType {
+				t.Errorf("content type mismatch: got %q want %q", contentType, test.expectedContentType)
+			}
+			if test.expectedCode != http.StatusOK {
+				return
+			}
+
+			// The fixture tree has three unrelated devices
+			if test.query == "" {
+				var topology api.Topology
+				if err := json.NewDecoder(rr.Body).Decode(&topology); err != nil {
+					t.Fatalf("failed to decode response body: %v", err)
+				}
+				if len(topology.Nodes) != 3 || len(topology.Edges) != 0 {
+					t.Errorf("unexpected topology: %+v", topology)
+				}
+			} else if test.query == "format=dot" && !strings.Contains(rr.Body.String(), "\t\"eth0\" [label=\"eth0\\ndevice\", color=green];\n") {
+				t.Errorf("missing eth0 node in output:\n%s", rr.Body.String())
+			}
+		})
+	}
+}
+
+// namespaceCollector serves the sysfs fixture tree as the only other network namespace, blue.
+// It is also reachable as pid:42.
+type namespaceCollector struct {
+	*models.SysfsCollector
+}
+
+func (c namespaceCollector) Namespaces(withPIDs bool) ([]api.Namespace, error) {
+	namespaces := []api.Namespace{{Name: "blue", Inode: 4026532205}}
+	if withPIDs {
+		namespaces = append(namespaces, api.Namespace{Name: "pid:42", Inode: 4026532300, PID: 42})
+	}
+	return namespaces, nil
+}
+
+func (c namespaceCollector) InNamespace(ref string) (models.Collector, error) {
+	if ref != "blue" && ref != "pid:42" {
+		return nil, models.ErrNoSuchNamespace
+	}
+	return c.SysfsCollector, nil
+}
+
+// TestNamespaceEndpoints tests the /netns and /netns/{ns}/network endpoints.
+func TestNamespaceEndpoints(t *testing.T) {
+	tests := []struct {
+		name          string
+		collector     models.Collector
+		pids          bool
+		path          string
+		expectedCode  int
+		expectedCount int
+	}{
+		{name: "List", collector: namespaceCollector{models.NewSysfsCollector("../servermodels/testdata")}, path: "/v2/netns", expectedCode: http.StatusOK, expectedCount: 1},
+		{name: "ListWithPIDs", collector: namespaceCollector{models.NewSysfsCollector("../servermodels/testdata")}, pids: true, path: "/v2/netns", expectedCode: http.StatusOK, expectedCount: 2},
+		{name: "Network", collector: namespaceCollector{models.NewSysfsCollector("../servermodels/testdata")}, path: "/v2/netns/blue/network", expectedCode: http.StatusOK, expectedCount: 3},
+		{name: "NetworkV1", collector: namespaceCollector{models.NewSysfsCollector("../servermodels/testdata")}, path: "/v1/netns/blue/network?interface=eth0", expectedCode: http.StatusOK, expectedCount: 1},
+		{name: "NoSuchInterface", collector: namespaceCollector{models.NewSysfsCollector("../servermodels/testdata")}, path: "/v2/netns/blue/network?interface=eth9", expectedCode: http.StatusNotFound},
+		{name: "NoSuchNamespace", collector: namespaceCollector{models.NewSysfsCollector("../servermodels/testdata")}, path: "/v2/netns/red/network", expectedCode: http.StatusNotFound},
+		{name: "PIDDisabled", collector: namespaceCollector{models.NewSysfsCollector("../servermodels/testdata")}, path: "/v2/netns/pid:42/network", expectedCode: http.StatusForbidden},
+		{name: "PIDEnabled", collector: namespaceCollector{models.NewSysfsCollector("../servermodels/testdata")}, pids: true, path: "/v2/netns/pid:42/network", expectedCode: http.StatusOK, expectedCount: 3},
+		{name: "ListNotImplemented", collector: models.NewSysfsCollector("../servermodels/testdata"), path: "/netns", expectedCode: http.StatusNotImplemented},
+		{name: "NetworkNotImplemented", collector: models.NewSysfsCollector("../servermodels/testdata"), path: "/v2/netns/blue/network", expectedCode: http.StatusNotImplemented},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", test.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			config := server.NewConfig()
+			config.NetnsPIDs = test.pids
+
+			rr := httptest.NewRecorder()
+			server.NewServer(test.collector, config).ServeHTTP(rr, req)
+
+			if status := rr.Code; status != test.expectedCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, test.expectedCode)
+			}
+			if test.expectedCode != http.StatusOK {
+				return
+			}
+
+			// Every response is a single list, of namespaces or of interfaces
+			var response map[string][]json.RawMessage
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response body: %v", err)
+			}
+			if len(response) != 1 {
+				t.Fatalf("unexpected response: %v", response)
+			}
+			for key, items := range response {
+				if len(items) != test.expectedCount {
+					t.Errorf("%s count mismatch: got %d want %d", key, len(items), test.expectedCount)
+				}
+			}
+		})
+	}
+}
+
+// ethtoolCollector serves the sysfs fixture tree along with the driver of its interfaces.
+type ethtoolCollector struct {
+	*models.SysfsCollector
+}
+
+func (c ethtoolCollector) Ethtool(name string) (*api.Ethtool, error) {
+	if _, err := c.Interface(name); err != nil {
+		return nil, err
+	}
+	return &api.Ethtool{Name: name, Driver: &api.DriverInfo{Driver: "ixgbe", Version: "6.8.0", BusInfo: "0000:03:00.0"}}, nil
+}
+
+// TestEthtoolEndpoint tests the /network/{name}/ethtool endpoint.
+func TestEthtoolEndpoint(t *testing.T) {
+	tests := []struct {
+		name         string
+		collector    models.Collector
+		path         string
+		expectedCode int
+	}{
+		{name: "Interface", collector: ethtoolCollector{models.NewSysfsCollector("../servermodels/testdata")}, path: "/v2/network/eth0/ethtool", expectedCode: http.StatusOK},
+		{name: "NoSuchInterface", collector: ethtoolCollector{models.NewSysfsCollector("../servermodels/testdata")}, path: "/v2/network/eth9/ethtool", expectedCode: http.StatusNotFound},
+		{name: "NotImplemented", collector: models.NewSysfsCollector("../servermodels/testdata"), path: "/network/eth0/ethtool", expectedCode: http.StatusNotImplemented},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			req, err := http.NewRequest("GET", test.path, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			rr := httptest.NewRecorder()
+			server.NewServer(test.collector, server.NewConfig()).ServeHTTP(rr, req)
+
+			if status := rr.Code; status != test.expectedCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, test.expectedCode)
+			}
+			if test.expectedCode != http.StatusOK {
+				return
+			}
+
+			var response api.Ethtool
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response body: %v", err)
+			}
+			if response.Name != "eth0" || response.Driver == nil || response.Driver.Driver != "ixgbe" {
+				t.Errorf("unexpected response: %+v", response)
+			}
+		})
+	}
+}
+
+// configuratorCollector serves the sysfs fixture tree with the MTU and addresses applied to it.
+type configuratorCollector struct {
+	*models.SysfsCollector
+	mtu       int
+	addresses []api.Address
+}
+
+func (c *configuratorCollector) Interface(name string) (*api.NetworkInterfaceV2, error) {
+	return c.InterfaceWith(name, models.AllDetails)
+}
+
+func (c *configuratorCollector) InterfaceWith(name string, details models.Details) (*api.NetworkInterfaceV2, error) {
+	iface, err := c.SysfsCollector.InterfaceWith(name, details)
+	if err != nil {
+		return nil, err
+	}
+	if c.mtu != 0 {
+		iface.MTU = c.mtu
+	}
+	iface.Addresses = append(iface.Addresses, c.addresses...)
+	return iface, nil
+}
+
+func (c *configuratorCollector) SetMTU(name string, mtu int) error {
+	if _, err := c.Interface(name); err != nil {
+		return err
+	}
+	if mtu > 9000 {
+		return syscall.EINVAL
+	}
+	c.mtu = mtu
+	return nil
+}
+
+func (c *configuratorCollector) SetAdminStatus(name string, up bool) error {
+	_, err := c.Interface(name)
+	return err
+}
+
+func (c *configuratorCollector) SetAlias(name, alias string) error {
+	_, err := c.Interface(name)
+	return err
+}
+
+func (c *configuratorCollector) AddAddress(name string, addr *net.IPNet) error {
+	if _, err := c.Interface(name); err != nil {
+		return err
+	}
+	for _, address := range c.addresses {
+		if address.Address == addr.IP.String() {
+			return syscall.EEXIST
+		}
+	}
+	prefixLength, _ := addr.Mask.Size()
+	c.addresses = append(c.addresses, api.Address{Address: addr.IP.String(), PrefixLength: prefixLength, Family: api.FamilyIPv4})
+	return nil
+}
+
+func (c *configuratorCollector) DeleteAddress(name string, addr *net.IPNet) error {
+	for i, address := range c.addresses {
+		if address.Address == addr.IP.String() {
+			c.addresses = append(c.addresses[:i], c.addresses[i+1:]...)
+			return nil
+		}
+	}
+	return syscall.EADDRNOTAVAIL
+}
+
+func (c *configuratorCollector) AddRoute(name string, dst *net.IPNet, gateway net.IP, metric uint32) error {
+	return syscall.EOPNOTSUPP
+}
+
+func (c *configuratorCollector) DeleteRoute(name string, dst *net.IPNet, gateway net.IP, metric uint32) error {
+	return syscall.EOPNOTSUPP
+}
+
+// TestWriteEndpoints tests the access control, validation and error mapping of the write operations.
+func TestWriteEndpoints(t *testing.T) {
+	assigned := []api.Address{{Address: "192.0.2.10", PrefixLength: 24, Family: api.FamilyIPv4}}
+
+	tests := []struct {
+		name         string
+		sysfsOnly    bool
+		writeToken   string
+		token        string
+		method       string
+		path         string
+		body         string
+		expectedCode int
+	}{
+		{name: "Disabled", method: "PATCH", path: "/v2/network/eth0", body: `{"mtu":1400}`, expectedCode: http.StatusForbidden},
+		{name: "MissingToken", writeToken: "secret", method: "PATCH", path: "/v2/network/eth0", body: `{"mtu":1400}`, expectedCode: http.StatusUnauthorized},
+		{name: "WrongToken", writeToken: "secret", token: "guess", method: "PATCH", path: "/v2/network/eth0", body: `{"mtu":1400}`, expectedCode: http.StatusUnauthorized},
+		{name: "Patch", writeToken: "secret", token: "secret", method: "PATCH", path: "/v2/network/eth0", body: `{"mtu":1400,"admin_status":"up","alias":"uplink"}`, expectedCode: http.StatusOK},
+		{name: "EmptyPatch", writeToken: "secret", token: "secret", method: "PATCH", path: "/v2/network/eth0", body: `{}`, expectedCode: http.StatusBadRequest},
+		{name: "UnknownField", writeToken: "secret", token: "secret", method: "PATCH", path: "/v2/network/eth0", body: `{"speed":1000}`, expectedCode: http.StatusBadRequest},
+		{name: "InvalidAdminStatus", writeToken: "secret", token: "secret", method: "PATCH", path: "/v2/network/eth0", body: `{"admin_status":"testing"}`, expectedCode: http.StatusBadRequest},
+		{name: "RejectedMTU", writeToken: "secret", token: "secret", method: "PATCH", path: "/v2/network/eth0", body: `{"mtu":65536}`, expectedCode: http.StatusUnprocessableEntity},
+		{name: "PatchNoSuchInterface", writeToken: "secret", token: "secret", method: "PATCH", path: "/v2/network/eth9", body: `{"mtu":1400}`, expectedCode: http.StatusNotFound},
+		{name: "AddAddress", writeToken: "secret", token: "secret", method: "POST", path: "/v2/network/eth0/addresses", body: `{"address":"192.0.2.20/24"}`, expectedCode: http.StatusCreated},
+		{name: "AddExistingAddress", writeToken: "secret", token: "secret", method: "POST", path: "/v2/network/eth0/addresses", body: `{"address":"192.0.2.10/24"}`, expectedCode: http.StatusConflict},
+		{name: "AddInvalidAddress", writeToken: "secret", token: "secret", method: "POST", path: "/v2/network/eth0/addresses", body: `{"address":"192.0.2.20"}`, expectedCode: http.StatusBadRequest},
+		{name: "DeleteAddress", writeToken: "secret", token: "secret", method: "DELETE", path: "/v2/network/eth0/addresses/192.0.2.10", expectedCode: http.StatusOK},
+		{name: "DeleteUnassignedAddress", writeToken: "secret", token: "secret", method: "DELETE", path: "/v2/network/eth0/addresses/192.0.2.20", expectedCode: http.StatusNotFound},
+		{name: "DeleteInvalidAddress", writeToken: "secret", token: "secret", method: "DELETE", path: "/v2/network/eth0/addresses/eth0", expectedCode: http.StatusBadRequest},
+		{name: "NotImplemented", sysfsOnly: true, writeToken: "secret", token: "secret", method: "PATCH", path: "/v2/network/eth0", body: `{"mtu":1400}`, expectedCode: http.StatusNotImplemented},
+		{name: "V1", writeToken: "secret", token: "secret", method: "PATCH", path: "/v1/network/eth0", body: `{"mtu":1400}`, expectedCode: http.StatusMethodNotAllowed},
+		{name: "InvalidConfirmTimeout", writeToken: "secret", token: "secret", method: "PATCH", path: "/v2/network/eth0?confirm_timeout=0", body: `{"mtu":1400}`, expectedCode: http.StatusBadRequest},
+		{name: "DryRunConfirmed", writeToken: "secret", token: "secret", method: "PATCH", path: "/v2/network/eth0?dry_run=true&confirm_timeout=60", body: `{"mtu":1400}`, expectedCode: http.StatusBadRequest},
+		{name: "UnknownParameter", writeToken: "secret", token: "secret", method: "PATCH", path: "/v2/network/eth0?force=true", body: `{"mtu":1400}`, expectedCode: http.StatusBadRequest},
+		{name: "ChangesDisabled", method: "GET", path: "/v2/changes", expectedCode: http.StatusForbidden},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var collector models.Collector = &configuratorCollector{SysfsCollector: models.NewSysfsCollector("../servermodels/testdata"), addresses: assigned}
+			if test.sysfsOnly {
+				collector = models.NewSysfsCollector("../servermodels/testdata")
+			}
+			config := server.NewConfig()
+			config.WriteToken = test.writeToken
+
+			req, err := http.NewRequest(test.method, test.path, strings.NewReader(test.body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if test.token != "" {
+				req.Header.Set("Authorization", "Bearer "+test.token)
+			}
+
+			rr := httptest.NewRecorder()
+			server.NewServer(collector, config).ServeHTTP(rr, req)
+
+			if status := rr.Code; status != test.expectedCode {
+				t.Fatalf("handler returned wrong status code: got %v want %v", status, test.expectedCode)
+			}
+			if status := rr.Code; status != http.StatusOK && status != http.StatusCreated {
+				return
+			}
+
+			var response api.NetworkInterfaceV2
+			if err := json.NewDecoder(rr.Body).Decode(&response); err != nil {
+				t.Fatalf("failed to decode response body: %v", err)
+			}
+			if response.Name != "eth0" {
+				t.Errorf("unexpected response: %+v", response)
+			}
+		})
+	}
+}
+
+// TestWriteModes tests that dry runs don't change interfaces and that changes in commit-confirmed
+// mode are kept pending, block other changes of the interface and can be rolled back.
+func TestWriteModes(t *testing.T) {
+	config := server.NewConfig()
+	config.WriteToken = "secret"
+	srv := server.NewServer(&configuratorCollector{SysfsCollector: models.NewSysfsCollector("../servermodels/testdata")}, config)
+
+	request := func(method, path, body string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer secret")
+		rr := httptest.NewRecorder()
+		srv.ServeHTTP(rr, req)
+		return rr
+	}
+	mtu := func() int {
+		var iface api.NetworkInterfaceV2
+		if err := json.NewDecoder(request("GET", "/v2/network/eth0", "").Body).Decode(&iface); err != nil {
+			t.Fatalf("failed to decode response body: %v", err)
+		}
+		return iface.MTU
+	}
+	initialMTU := mtu()
+
+	rr := request("PATCH", "/v2/network/eth0?dry_run=true", `{"mtu":1400}`)
+	var changeSet api.ChangeSet
+	if err := json.NewDecoder(rr.Body).Decode(&changeSet); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+	expected := []api.FieldChange{{Field: api.FieldMTU, Old: strconv.Itoa(initialMTU), New: "1400"}}
+	if !changeSet.DryRun || !reflect.DeepEqual(changeSet.Changes, expected) {
+		t.Errorf("unexpected dry run response: %+v", changeSet)
+	}
+	if got := mtu(); got != initialMTU {
+		t.Fatalf("dry run changed the MTU to %d", got)
+	}
+
+	rr = request("PATCH", "/v2/network/eth0?confirm_timeout=60", `{"mtu":1400}`)
+	var pending api.PendingChange
+	if err := json.NewDecoder(rr.Body).Decode(&pending); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+	if pending.ID == "" || pending.State == nil || pending.State.MTU != 1400 {
+		t.Fatalf("unexpected pending change: %+v", pending)
+	}
+
+	if status := request("POST", "/v2/network/eth0/addresses", `{"address":"192.0.2.20/24"}`).Code; status != http.StatusConflict {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusConflict)
+	}
+
+	var list api.PendingChanges
+	if err := json.NewDecoder(request("GET", "/v2/changes", "").Body).Decode(&list); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+	if len(list.Changes) != 1 || list.Changes[0].ID != pending.ID {
+		t.Errorf("unexpected pending changes: %+v", list)
+	}
+
+	if status := request("DELETE", "/v2/changes/"+pending.ID, "").Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	if got := mtu(); got != initialMTU {
+		t.Errorf("rollback left the MTU at %d", got)
+	}
+	if status := request("POST", "/v2/changes/"+pending.ID+"/confirm", "").Code; status != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
+	}
+}
+
+// TestDesiredStateEndpoints tests that a desired state set as YAML is planned against the
+// current state of the interfaces and that applying it brings them in sync.
+func TestDesiredStateEndpoints(t *testing.T) {
+	config := server.NewConfig()
+	config.WriteToken = "secret"
+	srv := server.NewServer(&configuratorCollector{SysfsCollector: models.NewSysfsCollector("../servermodels/testdata")}, config)
+
+	request := func(method, path, contentType, body string) *httptest.ResponseRecorder {
+		req, err := http.NewRequest(method, path, strings.NewReader(body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Authorization", "Bearer secret")
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		rr := httptest.NewRecorder()
+		srv.ServeHTTP(rr, req)
+		return rr
+	}
+	plan := func() api.Plan {
+		rr := request("GET", "/v2/desired-state/plan", "", "")
+		if status := rr.Code; status != http.StatusOK {
+			t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+		}
+		var plan api.Plan
+		if err := json.NewDecoder(rr.Body).Decode(&plan); err != nil {
+			t.Fatalf("failed to decode response body: %v", err)
+		}
+		return plan
+	}
+
+	statusTests := []struct {
+		name         string
+		method       string
+		path         string
+		contentType  string
+		body         string
+		expectedCode int
+	}{
+		{name: "NotSet", method: "GET", path: "/v2/desired-state", expectedCode: http.StatusNotFound},
+		{name: "PlanNotSet", method: "GET", path: "/v2/desired-state/plan", expectedCode: http.StatusNotFound},
+		{name: "InvalidMTU", method: "PUT", path: "/v2/desired-state", body: `{"interfaces":[{"name":"eth0","mtu":0}]}`, expectedCode: http.StatusBadRequest},
+		{name: "DuplicateInterface", method: "PUT", path: "/v2/desired-state", body: `{"interfaces":[{"name":"eth0"},{"name":"eth0"}]}`, expectedCode: http.StatusBadRequest},
+		{name: "InvalidRoute", method: "PUT", path: "/v2/desired-state", body: `{"interfaces":[{"name":"eth0","routes":[{"destination":"default","gateway":"eth0"}]}]}`, expectedCode: http.StatusBadRequest},
+		{name: "InvalidYAML", method: "PUT", path: "/v2/desired-state", contentType: "application/yaml", body: "interfaces: [", expectedCode: http.StatusBadRequest},
+		{name: "Routes", method: "PUT", path: "/v2/desired-state", body: `{"interfaces":[{"name":"eth0","routes":[]}]}`, expectedCode: http.StatusOK},
+		{name: "RoutesNotImplemented", method: "GET", path: "/v2/desired-state/plan", expectedCode: http.StatusNotImplemented},
+	}
+	for _, test := range statusTests {
+		t.Run(test.name, func(t *testing.T) {
+			if status := request(test.method, test.path, test.contentType, test.body).Code; status != test.expectedCode {
+				t.Errorf("handler returned wrong status code: got %v want %v", status, test.expectedCode)
+			}
+		})
+	}
+
+	desired := "interfaces:\n  - name: eth0\n    mtu: 1400\n    addresses: [192.0.2.20/24]\n  - name: eth9\n"
+	if status := request("PUT", "/v2/desired-state", "application/yaml", desired).Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+
+	expected := []api.ChangeSet{{
+		Interface: "eth0",
+		DryRun:    true,
+		Changes: []api.FieldChange{
+			{Field: api.FieldMTU, Old: "1500", New: "1400"},
+			{Field: api.FieldAddresses, New: "192.0.2.20/24"},
+		},
+	}}
+	if got := plan(); got.InSync || !reflect.DeepEqual(got.Interfaces, expected) || !reflect.DeepEqual(got.Missing, []string{"eth9"}) {
+		t.Errorf("unexpected plan: %+v", got)
+	}
+
+	rr := request("POST", "/v2/desired-state/apply", "", "")
+	if status := rr.Code; status != http.StatusOK {
+		t.Fatalf("handler returned wrong status code: got %v want %v", status, http.StatusOK)
+	}
+	var result api.ApplyResult
+	if err := json.NewDecoder(rr.Body).Decode(&result); err != nil {
+		t.Fatalf("failed to decode response body: %v", err)
+	}
+	if len(result.Applied) != 1 || result.Applied[0].DryRun || len(result.Pending) != 0 {
+		t.Errorf("unexpected apply result: %+v", result)
+	}
+	if got := plan(); len(got.Interfaces) != 0 {
+		t.Errorf("interfaces not in sync after apply: %+v", got)
+	}
+
+	if status := request("DELETE", "/v2/desired-state", "", "").Code; status != http.StatusNoContent {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNoContent)
+	}
+	if status := request("GET", "/v2/desired-state", "", "").Code; status != http.StatusNotFound {
+		t.Errorf("handler returned wrong status code: got %v want %v", status, http.StatusNotFound)
+	}
+}
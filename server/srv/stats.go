@@ -0,0 +1,94 @@
+package server
+
+import (
+	"sync"
+	"time"
+
+	api "apimodule"
+)
+
+// Limits of the traffic counter samples that rates are derived from.
+const (
+	minRateInterval = time.Second      // Minimum time between the samples of requests that rates are derived from.
+	statsRetention  = 10 * time.Minute // Time after which the samples of interfaces that are no longer observed are dropped.
+)
+
+// statsSample is a traffic counter sample of a single interface taken at a point in time.
+type statsSample struct {
+	at    time.Time          // Time the sample was taken.
+	stats api.InterfaceStats // Counters of the sample, along with the rates since the sample before.
+}
+
+// The statsTracker struct remembers the latest traffic counter sample of every interface,
+// so that per-second rates can be derived from consecutive samples. Samples taken less than
+// the minimum interval after the previous one reuse its rates instead of replacing it, so
+// that concurrent clients don't reduce the rates to the noise of a few milliseconds.
+type statsTracker struct {
+	mu          sync.Mutex
+	samples     map[string]statsSample
+	minInterval time.Duration    // Minimum time between the samples that rates are derived from.
+	swept       time.Time        // Time expired samples were last dropped.
+	now         func() time.Time // Clock used to timestamp samples, replaceable in tests.
+}
+
+// newStatsTracker creates a new statsTracker without any samples, deriving rates from samples
+// at least minInterval apart.
+func newStatsTracker(minInterval time.Duration) *statsTracker {
+	return &statsTracker{
+		samples:     make(map[string]statsSample),
+		minInterval: minInterval,
+		now:         time.Now,
+	}
+}
+
+// observe records a new sample of the counters of the named interface taken now
+// and fills in the rates since the previous sample.
+func (t *statsTracker) observe(name string, stats *api.InterfaceStats) {
+	t.observeAt(name, stats, t.now())
+}
+
+// observeAt records a new sample of the counters of the named interface taken at the given
+// time and fills in the rates since the previous sample. The rates stay nil for the first sample.
+func (t *statsTracker) observeAt(name string, stats *api.InterfaceStats, now time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.sweep(now)
+
+	prev, ok := t.samples[name]
+	if ok && now.Sub(prev.at) < t.minInterval {
+		stats.Rates = prev.stats.Rates
+		return
+	}
+
+	stats.Rates = nil
+	if ok {
+		stats.Rates = stats.RatesSince(prev.stats, now.Sub(prev.at))
+	}
+
+	t.samples[name] = statsSample{at: now, stats: *stats}
+}
+
+// forget drops the samples of the named interfaces, e.g. because they were deleted.
+func (t *statsTracker) forget(names ...string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for _, name := range names {
+		delete(t.samples, name)
+	}
+}
+
+// sweep drops the samples that are older than the retention, at most once per retention.
+// The caller must hold the lock.
+func (t *statsTracker) sweep(now time.Time) {
+	if now.Sub(t.swept) < statsRetention {
+		return
+	}
+	for name, sample := range t.samples {
+		if now.Sub(sample.at) > statsRetention {
+			delete(t.samples, name)
+		}
+	}
+	t.swept = now
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"testing"
+	"time"
+
+	api "apimodule"
+)
+
+// TestStatsTracker tests that rates are derived from samples at least the minimum interval
+// apart, and that samples of interfaces that are no longer observed are dropped.
+func TestStatsTracker(t *testing.T) {
+	tracker := newStatsTracker(time.Second)
+	start := time.Date(2026, time.October, 18, 12, 0, 0, 0, time.UTC)
+
+	observe := func(name string, rxBytes uint64, at time.Duration) *api.InterfaceRates {
+		stats := &api.InterfaceStats{RxBytes: rxBytes}
+		tracker.observeAt(name, stats, start.Add(at))
+		return stats.Rates
+	}
+
+	if rates := observe("eth0", 0, 0); rates != nil {
+		t.Errorf("expected no rates for the first sample, got %+v", rates)
+	}
+	if rates := observe("eth0", 2000, 2*time.Second); rates == nil || rates.RxBytes != 1000 {
+		t.Errorf("unexpected rates after 2s: %+v", rates)
+	}
+
+	// A sample shortly after the previous one reuses its rates and keeps it as the baseline
+	if rates := observe("eth0", 2010, 2*time.Second+time.Millisecond); rates == nil || rates.RxBytes != 1000 {
+		t.Errorf("unexpected rates 1ms after the previous sample: %+v", rates)
+	}
+	if rates := observe("eth0", 5000, 4*time.Second); rates == nil || rates.Interval != 2 || rates.RxBytes != 1500 {
+		t.Errorf("unexpected rates after another 2s: %+v", rates)
+	}
+
+	// Samples are dropped once they are older than the retention, or when forgotten
+	observe("eth1", 0, 4*time.Second)
+	tracker.forget("eth1")
+	if _, ok := tracker.samples["eth1"]; ok {
+		t.Error("expected the sample of eth1 to be forgotten")
+	}
+	observe("veth0", 0, 5*time.Second)
+	if rates := observe("eth0", 6000, statsRetention+6*time.Second); rates != nil {
+		t.Errorf("expected no rates after the retention, got %+v", rates)
+	}
+	if _, ok := tracker.samples["veth0"]; ok {
+		t.Error("expected the expired sample of veth0 to be dropped")
+	}
+}
@@ -0,0 +1,40 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+
+	api "apimodule"
+	models "servermodule/servermodels"
+)
+
+// errInvalidTopologyQuery is returned when the /topology endpoint receives unsupported query parameters.
+var errInvalidTopologyQuery = errors.New("only ?format={json|dot|yaml|csv|tsv|msgpack|cbor} input format is allowed")
+
+// The topologyHandler() method is the handler function for the /topology endpoint.
+// It returns the graph of all interfaces and their master, parent and peer relationships,
+// in the negotiated format or, with ?format=dot, as a Graphviz document.
+func (s *server) topologyHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if len(r.URL.Query()) != 0 {
+			s.error(w, http.StatusBadRequest, errInvalidTopologyQuery)
+			return
+		}
+
+		interfaces, err := models.CollectInterfaces(s.collector, 0)
+		if err != nil {
+			s.error(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		topology := api.NewTopology(interfaces)
+		if responseEncoding(w) == dotEncoding {
+			w.Header().Set("Content-Type", dotEncoding.contentType)
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(topology.DOT()))
+			return
+		}
+
+		s.respond(w, http.StatusOK, topology)
+	}
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Media types selecting an API version through the Accept header.
+const (
+	mediaTypeV1 = "application/vnd.interfacer.v1+json"
+	mediaTypeV2 = "application/vnd.interfacer.v2+json"
+)
+
+// negotiateVersion returns the API version requested by the Accept header of a request,
+// along with the matching media type. Without a versioned media type, or if v1 is
+// preferred, version 1 and an empty media type are returned.
+func negotiateVersion(r *http.Request) (int, string) {
+	var q1, q2 float64 = -1, -1
+
+	for _, mediaRange := range acceptRanges(r.Header.Values("Accept")) {
+		switch mediaRange.mediaType {
+		case mediaTypeV1:
+			q1 = max(q1, mediaRange.q)
+		case mediaTypeV2:
+			q2 = max(q2, mediaRange.q)
+		}
+	}
+
+	if q2 > 0 && q2 > q1 {
+		return 2, mediaTypeV2
+	}
+	if q1 > 0 {
+		return 1, mediaTypeV1
+	}
+	return 1, ""
+}
+
+// The negotiated() method returns a handler that serves a request with the handler of the
+// API version selected by the Accept header. Requests served by v1 are marked as deprecated.
+func (s *server) negotiated(v1, v2 http.HandlerFunc) http.HandlerFunc {
+	deprecatedV1 := s.deprecated(v1)
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		version, mediaType := negotiateVersion(r)
+		if mediaType != "" {
+			w.Header().Set("Content-Type", mediaType)
+		}
+
+		if version == 2 {
+			v2(w, r)
+			return
+		}
+		deprecatedV1.ServeHTTP(w, r)
+	}
+}
+
+// The deprecated() method is a middleware marking the responses of the v1 API as deprecated.
+// It announces the deprecation and sunset dates and links the v2 counterpart of the resource.
+func (s *server) deprecated(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Deprecation", fmt.Sprintf("@%d", s.v1Deprecation.Unix()))
+		w.Header().Set("Sunset", s.v1Sunset.UTC().Format(http.TimeFormat))
+		w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, "/v2"+strings.TrimPrefix(r.URL.Path, "/v1")))
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,218 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+
+	api "apimodule"
+)
+
+// wildcard matches every interface or every event type in a subscription.
+const wildcard = "*"
+
+// upgrader upgrades /network/ws requests to WebSocket connections.
+// It only accepts same-origin browser connections.
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  1024,
+	WriteBufferSize: 4096,
+}
+
+// Timeouts of a WebSocket connection.
+const (
+	wsWriteTimeout = 10 * time.Second // Maximum time to write a single message.
+	wsPongTimeout  = 60 * time.Second // Maximum time without a pong from the client.
+	wsPingInterval = 30 * time.Second // Interval of the pings keeping the connection alive.
+)
+
+// The subscription struct holds the interfaces and event types a WebSocket client is subscribed to.
+// It is updated by the reading goroutine and consulted by the writing one.
+type subscription struct {
+	mu         sync.Mutex
+	interfaces map[string]bool
+	events     map[string]bool
+}
+
+// newSubscription creates an empty subscription that matches nothing.
+func newSubscription() *subscription {
+	return &subscription{
+		interfaces: make(map[string]bool),
+		events:     make(map[string]bool),
+	}
+}
+
+// apply adds or removes the interfaces and event types of a client message
+// and returns the resulting subscription.
+func (s *subscription) apply(msg api.Subscription) (api.Subscription, error) {
+	var set bool
+	switch msg.Action {
+	case api.ActionSubscribe:
+		set = true
+	case api.ActionUnsubscribe:
+		set = false
+	default:
+		return api.Subscription{}, errors.New("action must be subscribe or unsubscribe")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	update := func(m map[string]bool, keys []string) {
+		for _, key := range keys {
+			if set {
+				m[key] = true
+			} else {
+				delete(m, key)
+			}
+		}
+	}
+	update(s.interfaces, msg.Interfaces)
+	update(s.events, msg.Events)
+
+	keys := func(m map[string]bool) []string {
+		result := make([]string, 0, len(m))
+		for key := range m {
+			result = append(result, key)
+		}
+		sort.Strings(result)
+		return result
+	}
+
+	return api.Subscription{Interfaces: keys(s.interfaces), Events: keys(s.events)}, nil
+}
+
+// matches reports whether the subscription covers the given interface and event type.
+func (s *subscription) matches(name, eventType string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return (s.interfaces[wildcard] || s.interfaces[name]) && (s.events[wildcard] || s.events[eventType])
+}
+
+// The websocketHandler() method is the handler function for the /network/ws endpoint.
+// It upgrades the connection to a WebSocket, on which the client sends subscribe and
+// unsubscribe messages and receives the matching change events as well as traffic
+// counter updates after every sample.
+func (s *server) websocketHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// The upgrader already replied with an HTTP error
+			log.Printf("WebSocket upgrade failed: %s", err.Error())
+			return
+		}
+		defer conn.Close()
+
+		sub := newSubscription()
+		replies := make(chan api.Message)
+		readerDone := make(chan struct{})
+		writerDone := make(chan struct{})
+		defer close(writerDone)
+
+		// Read client messages until the connection breaks
+		conn.SetReadLimit(64 * 1024)
+		conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+		conn.SetPongHandler(func(string) error {
+			return conn.SetReadDeadline(time.Now().Add(wsPongTimeout))
+		})
+		go func() {
+			defer close(readerDone)
+			for {
+				_, data, err := conn.ReadMessage()
+				if err != nil {
+					return
+				}
+
+				reply := api.Message{Type: api.MessageSubscription}
+				var msg api.Subscription
+				if err := json.Unmarshal(data, &msg); err != nil {
+					reply = api.Message{Type: api.MessageError, Error: "invalid message: " + err.Error()}
+				} else if current, err := sub.apply(msg); err != nil {
+					reply = api.Message{Type: api.MessageError, Error: err.Error()}
+				} else {
+					reply.Subscription = &current
+				}
+
+				select {
+				case replies <- reply:
+				case <-writerDone:
+					return
+				}
+			}
+		}()
+
+		_, events, cancel := s.events.subscribe(0)
+		defer cancel()
+
+		statsTicker := time.NewTicker(s.sampler.interval)
+		defer statsTicker.Stop()
+		pingTicker := time.NewTicker(wsPingInterval)
+		defer pingTicker.Stop()
+
+		// All writes happen here, as a WebSocket connection supports only one concurrent writer
+		write := func(msg api.Message) bool {
+			conn.SetWriteDeadline(time.Now().Add(wsWriteTimeout))
+			return conn.WriteJSON(msg) == nil
+		}
+
+		for {
+			select {
+			case <-readerDone:
+				return
+			case reply := <-replies:
+				if !write(reply) {
+					return
+				}
+			case event, ok := <-events:
+				if !ok {
+					conn.WriteControl(websocket.CloseMessage,
+						websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "client too slow"),
+						time.Now().Add(wsWriteTimeout))
+					return
+				}
+				if sub.matches(event.Interface, event.Type) && !write(api.Message{Type: api.MessageEvent, Event: &event}) {
+					return
+				}
+			case <-statsTicker.C:
+				if !s.writeStats(sub, write) {
+					return
+				}
+			case <-pingTicker.C:
+				if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(wsWriteTimeout)); err != nil {
+					return
+				}
+			}
+		}
+	}
+}
+
+// writeStats writes the latest sampled traffic counters of every subscribed interface.
+// It returns false if writing failed.
+func (s *server) writeStats(sub *subscription, write func(api.Message) bool) bool {
+	latest := s.sampler.latest()
+
+	names := make([]string, 0, len(latest))
+	for name := range latest {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+
+	for _, name := range names {
+		snapshot := latest[name]
+		if snapshot.Stats == nil || !sub.matches(name, api.EventStats) {
+			continue
+		}
+		if !write(api.Message{Type: api.MessageStats, Stats: &api.NetworkInterfaceStats{Name: name, Stats: *snapshot.Stats}}) {
+			return false
+		}
+	}
+
+	return true
+}